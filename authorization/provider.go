@@ -0,0 +1,103 @@
+// Package authorization answers the Authorize, StartTransaction and
+// StopTransaction requests of the charge points with the IdTagInfo of
+// their id tag, as given by an IdTagProvider
+package authorization
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/store"
+)
+
+// IdTag is what's known of an id tag
+type IdTag struct {
+	// Status of the id tag, Accepted when it's empty,
+	// it's Expired once past its ExpiryDate
+	Status     enums.AuthorizationStatus
+	ExpiryDate *time.Time
+	// ParentIdTag of the group the id tag belongs to, whose
+	// status, expiry and limit of transactions apply too
+	ParentIdTag string
+	// MaxConcurrentTx is how many transactions the id tag may be
+	// in at the same time, or the group of a parent id tag, 0 being
+	// the default of the service and a negative one no limit
+	MaxConcurrentTx int
+}
+
+// IdTagProvider gives what's known of the id tags, nil when it's unknown
+type IdTagProvider interface {
+	IdTag(ctx context.Context, idTag string) (*IdTag, error)
+}
+
+// IdTagProviderFunc lets a plain function be an IdTagProvider
+type IdTagProviderFunc func(ctx context.Context, idTag string) (*IdTag, error)
+
+func (f IdTagProviderFunc) IdTag(ctx context.Context, idTag string) (*IdTag, error) {
+	return f(ctx, idTag)
+}
+
+// Tags provides the id tags of the map
+func Tags(tags map[string]*IdTag) IdTagProvider {
+	return IdTagProviderFunc(func(ctx context.Context, idTag string) (*IdTag, error) {
+		return tags[idTag], nil
+	})
+}
+
+// AuthorizationListGetter gets the authorization list of the name, e.g. a store.Store
+type AuthorizationListGetter interface {
+	AuthorizationList(name string) (*store.AuthorizationList, error)
+}
+
+// FromAuthorizationList provides the id tags of the stored authorization
+// list, e.g. the local authorization list sent to the charge points
+func FromAuthorizationList(lists AuthorizationListGetter, name string) IdTagProvider {
+	return IdTagProviderFunc(func(ctx context.Context, idTag string) (*IdTag, error) {
+		list, err := lists.AuthorizationList(name)
+		if err != nil || list == nil {
+			return nil, err
+		}
+		info := list.IdTagInfo(idTag)
+		if info == nil {
+			return nil, nil
+		}
+		return &IdTag{Status: info.Status, ExpiryDate: info.ExpiryDate, ParentIdTag: info.ParentIdTag}, nil
+	})
+}
+
+type cachedIdTag struct {
+	idTag   *IdTag
+	expires time.Time
+}
+
+// Cached caches what the provider gives for the ttl, unknown id tags
+// included, e.g. when it's a remote service. The errors aren't cached.
+func Cached(provider IdTagProvider, ttl time.Duration) IdTagProvider {
+	var mux sync.Mutex
+	cache := make(map[string]cachedIdTag)
+	return IdTagProviderFunc(func(ctx context.Context, idTag string) (*IdTag, error) {
+		now := time.Now()
+		mux.Lock()
+		cached, ok := cache[idTag]
+		mux.Unlock()
+		if ok && now.Before(cached.expires) {
+			return cached.idTag, nil
+		}
+		tag, err := provider.IdTag(ctx, idTag)
+		if err != nil {
+			return nil, err
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		// the expired ones are dropped as the cache is filled
+		for cachedTag, cached := range cache {
+			if !now.Before(cached.expires) {
+				delete(cache, cachedTag)
+			}
+		}
+		cache[idTag] = cachedIdTag{idTag: tag, expires: now.Add(ttl)}
+		return tag, nil
+	})
+}
@@ -0,0 +1,80 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Cached(t *testing.T) {
+	calls := 0
+	var fail bool
+	provider := Cached(IdTagProviderFunc(func(ctx context.Context, idTag string) (*IdTag, error) {
+		calls++
+		if fail {
+			return nil, errors.New("unreachable")
+		}
+		if idTag == "UNKNOWN" {
+			return nil, nil
+		}
+		return &IdTag{}, nil
+	}), 50*time.Millisecond)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		tag, err := provider.IdTag(ctx, "TAG1")
+		assert.NoError(t, err)
+		assert.NotNil(t, tag)
+		tag, err = provider.IdTag(ctx, "UNKNOWN")
+		assert.NoError(t, err)
+		assert.Nil(t, tag)
+	}
+	assert.Equal(t, 2, calls)
+
+	// once expired, the errors aren't cached
+	time.Sleep(60 * time.Millisecond)
+	fail = true
+	_, err := provider.IdTag(ctx, "TAG1")
+	assert.Error(t, err)
+	fail = false
+	tag, err := provider.IdTag(ctx, "TAG1")
+	assert.NoError(t, err)
+	assert.NotNil(t, tag)
+	assert.Equal(t, 4, calls)
+}
+
+func Test_FromAuthorizationList(t *testing.T) {
+	st := store.NewMemory()
+	provider := FromAuthorizationList(st, "depot")
+	ctx := context.Background()
+	tag, err := provider.IdTag(ctx, "TAG1")
+	assert.NoError(t, err)
+	assert.Nil(t, tag)
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	assert.NoError(t, st.SaveAuthorizationList(&store.AuthorizationList{
+		Name: "depot",
+		IdTags: []*csreq.LocalAuthorizationListItems{
+			{IdTag: "TAG1", IdTagInfo: &csreq.IdTagInfo{Status: enums.AuthorizationStatusAccepted, ParentIdTag: "FLEET", ExpiryDate: &expiry}},
+			{IdTag: "TAG2", IdTagInfo: &csreq.IdTagInfo{Status: enums.AuthorizationStatusBlocked}},
+		},
+	}))
+	tag, err = provider.IdTag(ctx, "TAG1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, tag) {
+		assert.Equal(t, "FLEET", tag.ParentIdTag)
+		assert.True(t, expiry.Equal(*tag.ExpiryDate))
+	}
+	tag, _ = provider.IdTag(ctx, "TAG3")
+	assert.Nil(t, tag)
+
+	info, err := New(provider).Authorize(ctx, "TAG2")
+	assert.NoError(t, err)
+	assert.Equal(t, enums.AuthorizationStatusBlocked, info.Status)
+}
@@ -0,0 +1,221 @@
+package authorization
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/transactions"
+)
+
+// Service authorizes the id tags of the charge points, the status being:
+//
+//	Invalid when the id tag is unknown to the provider, or it or its parent is invalid
+//	Blocked when the id tag, or its parent, is blocked
+//	Expired when the id tag, or its parent, is past its expiry date
+//	ConcurrentTx when it starts a transaction, and it's in as many as it may be, or its group
+//	Accepted otherwise
+//
+// It follows the transactions through a transactions.Tracker. The ones
+// counted against the limits are only kept in memory: those started before
+// the service was created, e.g. before the central system restarted, aren't.
+type Service struct {
+	mux             sync.Mutex
+	provider        IdTagProvider
+	tracker         *transactions.Tracker
+	maxConcurrentTx int
+	// running are the transactions started with an accepted id tag
+	running map[int32]runningTransaction
+}
+
+type runningTransaction struct {
+	idTag       string
+	parentIdTag string
+}
+
+// Option configures the service
+type Option func(*Service)
+
+// WithTracker follows the transactions with the tracker, e.g. one with a
+// store, instead of a new one. The service handles its requests, so the
+// tracker mustn't be registered to the router, nor have an IdTagInfoFunc.
+func WithTracker(tracker *transactions.Tracker) Option {
+	return func(s *Service) {
+		s.tracker = tracker
+	}
+}
+
+// WithMaxConcurrentTx is how many transactions an id tag may be in at
+// the same time by default, 1 unless set, a negative one being no limit
+func WithMaxConcurrentTx(max int) Option {
+	return func(s *Service) {
+		s.maxConcurrentTx = max
+	}
+}
+
+// New authorizes the id tags the provider gives
+func New(provider IdTagProvider, options ...Option) *Service {
+	s := &Service{
+		provider:        provider,
+		maxConcurrentTx: 1,
+		running:         make(map[int32]runningTransaction),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.tracker == nil {
+		s.tracker = transactions.NewTracker()
+	}
+	return s
+}
+
+// Tracker following the transactions
+func (s *Service) Tracker() *transactions.Tracker {
+	return s.tracker
+}
+
+// Authorize the id tag, e.g. to start or stop a transaction. It's never
+// ConcurrentTx, which is only relevant to StartTransaction in OCPP 1.6.
+func (s *Service) Authorize(ctx context.Context, idTag string) (*cpresp.IdTagInfo, error) {
+	return s.authorize(ctx, idTag, 0, false)
+}
+
+// authorize the id tag, which is in the transaction of the ID unless
+// it's 0: it's not counted, and it's running once it's accepted
+func (s *Service) authorize(ctx context.Context, idTag string, transactionID int32, concurrency bool) (*cpresp.IdTagInfo, error) {
+	tag, err := s.provider.IdTag(ctx, idTag)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return &cpresp.IdTagInfo{Status: enums.AuthorizationStatusInvalid}, nil
+	}
+	now := time.Now()
+	info := &cpresp.IdTagInfo{Status: status(tag, now), ExpiryDate: tag.ExpiryDate, ParentIdTag: tag.ParentIdTag}
+	var parent *IdTag
+	if info.Status == enums.AuthorizationStatusAccepted && tag.ParentIdTag != "" {
+		// the parent id tag may only be the ID of a group
+		if parent, err = s.provider.IdTag(ctx, tag.ParentIdTag); err != nil {
+			return nil, err
+		}
+		if parent != nil {
+			info.Status = status(parent, now)
+			if parent.ExpiryDate != nil && (info.ExpiryDate == nil || parent.ExpiryDate.Before(*info.ExpiryDate)) {
+				info.ExpiryDate = parent.ExpiryDate
+			}
+		}
+	}
+	if info.Status != enums.AuthorizationStatusAccepted || !concurrency {
+		if transactionID != 0 {
+			s.mux.Lock()
+			delete(s.running, transactionID)
+			s.mux.Unlock()
+		}
+		return info, nil
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.running, transactionID)
+	ownLimit, groupLimit := s.limit(tag), -1
+	if parent != nil && parent.MaxConcurrentTx > 0 {
+		groupLimit = parent.MaxConcurrentTx
+	}
+	own, group := 0, 0
+	for _, tx := range s.running {
+		if tx.idTag == idTag {
+			own++
+		}
+		if tag.ParentIdTag != "" && tx.parentIdTag == tag.ParentIdTag {
+			group++
+		}
+	}
+	if (ownLimit >= 0 && own >= ownLimit) || (groupLimit >= 0 && group >= groupLimit) {
+		info.Status = enums.AuthorizationStatusConcurrentTx
+		return info, nil
+	}
+	if transactionID != 0 {
+		s.running[transactionID] = runningTransaction{idTag: idTag, parentIdTag: tag.ParentIdTag}
+	}
+	return info, nil
+}
+
+// status of the id tag at the time, but for the transactions it's in
+func status(tag *IdTag, now time.Time) enums.AuthorizationStatus {
+	if tag.Status != "" && tag.Status != enums.AuthorizationStatusAccepted {
+		return tag.Status
+	}
+	if tag.ExpiryDate != nil && !now.Before(*tag.ExpiryDate) {
+		return enums.AuthorizationStatusExpired
+	}
+	return enums.AuthorizationStatusAccepted
+}
+
+// limit of the transactions of the id tag, negative when there's none
+func (s *Service) limit(tag *IdTag) int {
+	if tag.MaxConcurrentTx != 0 {
+		return tag.MaxConcurrentTx
+	}
+	return s.maxConcurrentTx
+}
+
+// HandleAuthorize is the handler of the Authorize requests
+func (s *Service) HandleAuthorize(ctx context.Context, req *cpreq.Authorize, metadata cs.ChargePointRequestMetadata) (*cpresp.Authorize, error) {
+	info, err := s.Authorize(ctx, req.IdTag)
+	if err != nil {
+		return nil, err
+	}
+	return &cpresp.Authorize{IdTagInfo: info}, nil
+}
+
+// HandleStartTransaction is the handler of the StartTransaction requests,
+// the transaction is started even when the id tag isn't accepted, the
+// charge point then stops it, but it's not counted as one of the id tag's
+func (s *Service) HandleStartTransaction(ctx context.Context, req *cpreq.StartTransaction, metadata cs.ChargePointRequestMetadata) (*cpresp.StartTransaction, error) {
+	tx, err := s.tracker.Start(metadata.ChargePointID, req)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.authorize(ctx, req.IdTag, tx.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	return &cpresp.StartTransaction{IdTagInfo: info, TransactionId: tx.ID}, nil
+}
+
+// HandleStopTransaction is the handler of the StopTransaction requests,
+// answering with the IdTagInfo of the id tag stopping it, if there's one
+func (s *Service) HandleStopTransaction(ctx context.Context, req *cpreq.StopTransaction, metadata cs.ChargePointRequestMetadata) (*cpresp.StopTransaction, error) {
+	resp := &cpresp.StopTransaction{}
+	if req.IdTag != "" {
+		info, err := s.authorize(ctx, req.IdTag, 0, false)
+		if err != nil {
+			return nil, err
+		}
+		resp.IdTagInfo = info
+	}
+	tx, err := s.tracker.Stop(metadata.ChargePointID, req)
+	if err != nil {
+		return nil, err
+	}
+	// it's zero when the transaction isn't one the request may stop
+	if tx.ID != 0 {
+		s.mux.Lock()
+		delete(s.running, tx.ID)
+		s.mux.Unlock()
+	}
+	return resp, nil
+}
+
+// Register handles the Authorize, StartTransaction, StopTransaction and
+// MeterValues requests of the router, the latter by the tracker
+func (s *Service) Register(router *cs.Router) {
+	router.OnAuthorize(s.HandleAuthorize)
+	router.OnStartTransaction(s.HandleStartTransaction)
+	router.OnStopTransaction(s.HandleStopTransaction)
+	router.OnMeterValues(s.tracker.HandleMeterValues)
+}
@@ -0,0 +1,198 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/transactions"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	past   = time.Now().Add(-time.Hour)
+	future = time.Now().Add(24 * time.Hour)
+	later  = time.Now().Add(48 * time.Hour)
+)
+
+func tags() IdTagProvider {
+	return Tags(map[string]*IdTag{
+		"ACCEPTED":        {},
+		"BLOCKED":         {Status: enums.AuthorizationStatusBlocked},
+		"EXPIRED":         {ExpiryDate: &past},
+		"EXPIRING":        {ExpiryDate: &later},
+		"UNLIMITED":       {MaxConcurrentTx: -1},
+		"FLEET":           {MaxConcurrentTx: 2, ExpiryDate: &future},
+		"FLEET-1":         {ParentIdTag: "FLEET", ExpiryDate: &later},
+		"FLEET-2":         {ParentIdTag: "FLEET"},
+		"FLEET-3":         {ParentIdTag: "FLEET"},
+		"BLOCKED-FLEET":   {Status: enums.AuthorizationStatusBlocked},
+		"BLOCKED-FLEET-1": {ParentIdTag: "BLOCKED-FLEET"},
+		"GROUP-1":         {ParentIdTag: "GROUP"},
+	})
+}
+
+func metadata(cpID string) cs.ChargePointRequestMetadata {
+	return cs.ChargePointRequestMetadata{ChargePointID: cpID}
+}
+
+func Test_Authorize(t *testing.T) {
+	cases := []struct {
+		IdTag       string
+		Status      enums.AuthorizationStatus
+		ParentIdTag string
+		ExpiryDate  *time.Time
+	}{
+		{"ACCEPTED", enums.AuthorizationStatusAccepted, "", nil},
+		{"UNKNOWN", enums.AuthorizationStatusInvalid, "", nil},
+		{"BLOCKED", enums.AuthorizationStatusBlocked, "", nil},
+		{"EXPIRED", enums.AuthorizationStatusExpired, "", &past},
+		{"EXPIRING", enums.AuthorizationStatusAccepted, "", &later},
+		// the earliest expiry of the id tag and its parent
+		{"FLEET-1", enums.AuthorizationStatusAccepted, "FLEET", &future},
+		{"BLOCKED-FLEET-1", enums.AuthorizationStatusBlocked, "BLOCKED-FLEET", nil},
+		// a parent unknown to the provider only groups id tags
+		{"GROUP-1", enums.AuthorizationStatusAccepted, "GROUP", nil},
+	}
+	service := New(tags())
+	for _, c := range cases {
+		t.Run(c.IdTag, func(t *testing.T) {
+			resp, err := service.HandleAuthorize(context.Background(), &cpreq.Authorize{IdTag: c.IdTag}, metadata("CP01"))
+			assert.NoError(t, err)
+			assert.Equal(t, &cpresp.IdTagInfo{Status: c.Status, ParentIdTag: c.ParentIdTag, ExpiryDate: c.ExpiryDate}, resp.IdTagInfo)
+		})
+	}
+}
+
+func start(t *testing.T, service *Service, cpID string, connectorID int, idTag string) *cpresp.StartTransaction {
+	resp, err := service.HandleStartTransaction(context.Background(), &cpreq.StartTransaction{
+		ConnectorId: connectorID,
+		IdTag:       idTag,
+		Timestamp:   time.Now(),
+	}, metadata(cpID))
+	assert.NoError(t, err)
+	return resp
+}
+
+func stop(t *testing.T, service *Service, cpID string, transactionID int32, idTag string) *cpresp.StopTransaction {
+	resp, err := service.HandleStopTransaction(context.Background(), &cpreq.StopTransaction{
+		TransactionId: int(transactionID),
+		IdTag:         idTag,
+		Timestamp:     time.Now(),
+	}, metadata(cpID))
+	assert.NoError(t, err)
+	return resp
+}
+
+func Test_ConcurrentTx(t *testing.T) {
+	ctx := context.Background()
+	service := New(tags())
+
+	first := start(t, service, "CP01", 1, "ACCEPTED")
+	assert.Equal(t, enums.AuthorizationStatusAccepted, first.IdTagInfo.Status)
+	// e.g. to stop its transaction
+	info, err := service.Authorize(ctx, "ACCEPTED")
+	assert.NoError(t, err)
+	assert.Equal(t, enums.AuthorizationStatusAccepted, info.Status)
+	second := start(t, service, "CP02", 1, "ACCEPTED")
+	assert.Equal(t, enums.AuthorizationStatusConcurrentTx, second.IdTagInfo.Status)
+	// the transaction is started all the same, the charge point stopping it
+	assert.NotEqual(t, first.TransactionId, second.TransactionId)
+	assert.Len(t, service.Tracker().Running(), 2)
+	stop(t, service, "CP02", second.TransactionId, "")
+	// it wasn't counted
+	third := start(t, service, "CP02", 1, "ACCEPTED")
+	assert.Equal(t, enums.AuthorizationStatusConcurrentTx, third.IdTagInfo.Status)
+	stop(t, service, "CP02", third.TransactionId, "")
+
+	// stopped, and the id tag may start another
+	resp := stop(t, service, "CP01", first.TransactionId, "ACCEPTED")
+	assert.Equal(t, enums.AuthorizationStatusAccepted, resp.IdTagInfo.Status)
+	assert.Equal(t, enums.AuthorizationStatusAccepted, start(t, service, "CP02", 1, "ACCEPTED").IdTagInfo.Status)
+
+	// without limit
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, enums.AuthorizationStatusAccepted, start(t, service, "CP03", i, "UNLIMITED").IdTagInfo.Status)
+	}
+}
+
+func Test_ConcurrentTxOfGroup(t *testing.T) {
+	service := New(tags())
+	first := start(t, service, "CP01", 1, "FLEET-1")
+	assert.Equal(t, enums.AuthorizationStatusAccepted, first.IdTagInfo.Status)
+	assert.Equal(t, enums.AuthorizationStatusAccepted, start(t, service, "CP02", 1, "FLEET-2").IdTagInfo.Status)
+	// the group may only be in two transactions
+	assert.Equal(t, enums.AuthorizationStatusConcurrentTx, start(t, service, "CP03", 1, "FLEET-3").IdTagInfo.Status)
+	stop(t, service, "CP01", first.TransactionId, "FLEET-1")
+	assert.Equal(t, enums.AuthorizationStatusAccepted, start(t, service, "CP03", 1, "FLEET-3").IdTagInfo.Status)
+}
+
+func Test_StopTransactionOfOtherChargePoint(t *testing.T) {
+	service := New(tags())
+	tx := start(t, service, "CP01", 1, "ACCEPTED")
+	stop(t, service, "CP02", tx.TransactionId, "")
+	assert.Len(t, service.Tracker().Running(), 1)
+	// the id tag is still in the transaction
+	assert.Equal(t, enums.AuthorizationStatusConcurrentTx, start(t, service, "CP03", 1, "ACCEPTED").IdTagInfo.Status)
+}
+
+func Test_StartTransactionSentAgain(t *testing.T) {
+	service := New(tags())
+	req := &cpreq.StartTransaction{ConnectorId: 1, IdTag: "ACCEPTED", MeterStart: 100, Timestamp: time.Now()}
+	first, err := service.HandleStartTransaction(context.Background(), req, metadata("CP01"))
+	assert.NoError(t, err)
+	again, err := service.HandleStartTransaction(context.Background(), req, metadata("CP01"))
+	assert.NoError(t, err)
+	assert.Equal(t, first, again)
+}
+
+func Test_StopTransactionUnknownIdTag(t *testing.T) {
+	service := New(tags(), WithMaxConcurrentTx(-1))
+	tx := start(t, service, "CP01", 1, "ACCEPTED")
+	resp := stop(t, service, "CP01", tx.TransactionId, "UNKNOWN")
+	assert.Equal(t, enums.AuthorizationStatusInvalid, resp.IdTagInfo.Status)
+	assert.Empty(t, service.Tracker().Running())
+	assert.Nil(t, stop(t, service, "CP01", 42, "").IdTagInfo)
+}
+
+func Test_ProviderError(t *testing.T) {
+	service := New(IdTagProviderFunc(func(ctx context.Context, idTag string) (*IdTag, error) {
+		return nil, errors.New("unreachable")
+	}))
+	_, err := service.Authorize(context.Background(), "ACCEPTED")
+	assert.Error(t, err)
+	_, err = service.HandleStartTransaction(context.Background(), &cpreq.StartTransaction{ConnectorId: 1, IdTag: "ACCEPTED"}, metadata("CP01"))
+	assert.Error(t, err)
+	_, err = service.HandleStopTransaction(context.Background(), &cpreq.StopTransaction{TransactionId: 1, IdTag: "ACCEPTED"}, metadata("CP01"))
+	assert.Error(t, err)
+	// the charge point sends it again, it's still running
+	assert.Len(t, service.Tracker().Running(), 1)
+}
+
+func Test_Register(t *testing.T) {
+	var completed []transactions.Transaction
+	tracker := transactions.NewTracker(transactions.WithListener(func(tx transactions.Transaction) {
+		completed = append(completed, tx)
+	}))
+	router := cs.NewRouter()
+	New(tags(), WithTracker(tracker)).Register(router)
+
+	resp, err := router.Handle(&cpreq.Authorize{IdTag: "BLOCKED"}, metadata("CP01"))
+	assert.NoError(t, err)
+	assert.Equal(t, enums.AuthorizationStatusBlocked, resp.(*cpresp.Authorize).IdTagInfo.Status)
+	resp, err = router.Handle(&cpreq.StartTransaction{ConnectorId: 1, IdTag: "ACCEPTED", MeterStart: 100, Timestamp: time.Now()}, metadata("CP01"))
+	assert.NoError(t, err)
+	txID := resp.(*cpresp.StartTransaction).TransactionId
+	_, err = router.Handle(&cpreq.MeterValues{ConnectorId: 1, TransactionId: txID}, metadata("CP01"))
+	assert.NoError(t, err)
+	_, err = router.Handle(&cpreq.StopTransaction{TransactionId: int(txID), MeterStop: 600}, metadata("CP01"))
+	assert.NoError(t, err)
+	if assert.Len(t, completed, 1) {
+		assert.Equal(t, 500.0, completed[0].Energy())
+	}
+}
@@ -0,0 +1,23 @@
+package cp
+
+//go:generate go run ../internal/gen/clients -side cp
+
+import (
+	"github.com/michaelbironneau/go-ocpp/internal/service"
+)
+
+// CentralSystemClient sends typed requests to the central system,
+// so callers don't have to assert the type of the responses. A
+// response of the wrong type is a *messages.UnexpectedResponseError.
+type CentralSystemClient struct {
+	service   service.CentralSystem
+	chargerID string
+}
+
+// NewCentralSystemClient sends the requests through the charge point
+func NewCentralSystemClient(cpoint ChargePoint) *CentralSystemClient {
+	return &CentralSystemClient{
+		service:   cpoint,
+		chargerID: cpoint.Identity(),
+	}
+}
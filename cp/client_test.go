@@ -0,0 +1,62 @@
+package cp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeChargePoint answers every request with the same response
+type fakeChargePoint struct {
+	ChargePoint
+	resp cpresp.ChargePointResponse
+	err  error
+}
+
+func (cp *fakeChargePoint) Identity() string {
+	return "CP1"
+}
+
+func (cp *fakeChargePoint) Send(chargerID string, req cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error) {
+	return cp.SendContext(context.Background(), chargerID, req)
+}
+
+func (cp *fakeChargePoint) SendContext(ctx context.Context, chargerID string, req cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error) {
+	return cp.resp, cp.err
+}
+
+func Test_CentralSystemClient(t *testing.T) {
+	ctx := context.Background()
+	req := &cpreq.Authorize{IdTag: "VIRTUAL"}
+
+	t.Run("typed response", func(t *testing.T) {
+		client := NewCentralSystemClient(&fakeChargePoint{resp: &cpresp.Authorize{IdTagInfo: &cpresp.IdTagInfo{Status: "Accepted"}}})
+		resp, err := client.Authorize(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, enums.AuthorizationStatusAccepted, resp.IdTagInfo.Status)
+	})
+
+	t.Run("wrong response", func(t *testing.T) {
+		client := NewCentralSystemClient(&fakeChargePoint{resp: &cpresp.Heartbeat{}})
+		resp, err := client.Authorize(ctx, req)
+		assert.Nil(t, resp)
+		var unexpected *messages.UnexpectedResponseError
+		if assert.True(t, errors.As(err, &unexpected)) {
+			assert.Equal(t, "Authorize", unexpected.Action)
+			assert.IsType(t, &cpresp.Heartbeat{}, unexpected.Response)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		sendErr := errors.New("no connection")
+		client := NewCentralSystemClient(&fakeChargePoint{err: sendErr})
+		_, err := client.Authorize(ctx, req)
+		assert.Equal(t, sendErr, err)
+	})
+}
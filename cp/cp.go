@@ -2,12 +2,21 @@ package cp
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
-	"github.com/michaelbironneau/go-ocpp/cs"
+	"net"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/interceptor"
+	"github.com/michaelbironneau/go-ocpp/internal"
 	"github.com/michaelbironneau/go-ocpp/internal/service"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
 	"github.com/michaelbironneau/go-ocpp/ws"
@@ -27,28 +36,126 @@ type ChargePoint interface {
 }
 
 type chargePoint struct {
-	service.CentralSystem
 	identity         string
 	centralSystemURL string
 	headers          http.Header
 	version          ocpp.Version
 	transport        ocpp.Transport
-	conn             *ws.Conn
 	ctx              context.Context
-	connectedChan    chan struct{}
+	// connMux guards the fields below, which
+	// are replaced on every reconnection
+	connMux       sync.Mutex
+	centralSystem service.CentralSystem
+	conn          *ws.Conn
+	connectedChan chan struct{}
+	// requestTimeout is how long Send waits for a central system response
+	requestTimeout time.Duration
+	// queueOptions of the outbound call queue of the connection
+	queueOptions ws.QueueOptions
+	// schemaValidation of the payloads of the connection
+	schemaValidation bool
+	// soapEndpoint is where the central system sends its SOAP requests
+	soapEndpoint string
+	soapListener net.Listener
+	// tlsConfig used to dial a wss:// central system,
+	// holding the client certificate if any
+	tlsConfig *tls.Config
+	// interceptor of the inbound and outbound requests, if any
+	interceptor interceptor.Interceptor
+}
+
+// Option configures the charge point
+type Option func(*chargePoint)
+
+// WithRequestTimeout sets how long a Send to the central
+// system waits for its response, as does a SendContext
+// whose context has no deadline
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(cp *chargePoint) {
+		cp.requestTimeout = timeout
+	}
+}
+
+// WithCallQueue configures the queue in which calls to the
+// central system wait for the previous one to be answered,
+// e.g. its depth or which actions to send first
+func WithCallQueue(options ws.QueueOptions) Option {
+	return func(cp *chargePoint) {
+		cp.queueOptions = options
+	}
+}
+
+// WithSchemaValidation validates the payloads sent to and received
+// from the central system against the OCPP 1.6 JSON schemas
+func WithSchemaValidation() Option {
+	return func(cp *chargePoint) {
+		cp.schemaValidation = true
+	}
+}
+
+// WithSOAPEndpoint sets the URL the central system reaches the
+// charge point at, sent in the WS-Addressing From header. By default
+// it's built from the port and the local address used to reach the
+// central system, which is wrong behind a NAT
+func WithSOAPEndpoint(endpoint string) Option {
+	return func(cp *chargePoint) {
+		cp.soapEndpoint = endpoint
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to
+// dial a wss:// central system, e.g. its CA
+func WithTLSConfig(config *tls.Config) Option {
+	return func(cp *chargePoint) {
+		var certificates []tls.Certificate
+		if cp.tlsConfig != nil {
+			// keep the ones of WithClientCertificate
+			certificates = cp.tlsConfig.Certificates
+		}
+		if config != nil {
+			cp.tlsConfig = config.Clone()
+		} else {
+			cp.tlsConfig = &tls.Config{}
+		}
+		cp.tlsConfig.Certificates = append(cp.tlsConfig.Certificates, certificates...)
+	}
+}
+
+// WithClientCertificate authenticates the charge point to
+// the central system with its certificate (mTLS). If the
+// identity given to New is empty, it's the certificate CN.
+func WithClientCertificate(certificate tls.Certificate) Option {
+	return func(cp *chargePoint) {
+		if cp.tlsConfig == nil {
+			cp.tlsConfig = &tls.Config{}
+		}
+		cp.tlsConfig.Certificates = append(cp.tlsConfig.Certificates, certificate)
+	}
 }
 
 // Run the charge point on the given port
 // and handles each incoming CentralSystemRequest
-func New(ctx context.Context, identity, csURL string, version ocpp.Version, transport ocpp.Transport, port *string, headers http.Header, cshandler cs.ChargePointMessageHandler) (ChargePoint, error) {
+func New(ctx context.Context, identity, csURL string, version ocpp.Version, transport ocpp.Transport, port *string, headers http.Header, cshandler CentralSystemMessageHandler, options ...Option) (ChargePoint, error) {
 	cp := &chargePoint{
 		identity:         identity,
 		centralSystemURL: csURL,
 		version:          version,
 		transport:        transport,
 		ctx:              ctx,
-		headers: headers,
+		headers:          headers,
 		connectedChan:    make(chan struct{}),
+		requestTimeout:   internal.DefaultRequestTimeout,
+	}
+	for _, option := range options {
+		option(cp)
+	}
+	cshandler = cp.intercepted(cshandler)
+	if cp.identity == "" {
+		identity, err := certificateIdentity(cp.tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		cp.identity = identity
 	}
 	if transport == ocpp.JSON {
 		err := cp.getNewWebsocketConnection()
@@ -58,7 +165,13 @@ func New(ctx context.Context, identity, csURL string, version ocpp.Version, tran
 		go cp.handleWebsocketConnection(cshandler)
 	}
 	if transport == ocpp.SOAP {
-		// remove
+		if port == nil {
+			return nil, errors.New("a port is needed to receive the SOAP requests of the central system")
+		}
+		err := cp.startSoap(*port, cshandler)
+		if err != nil {
+			return nil, fmt.Errorf("could not listen to central system requests: %w", err)
+		}
 	}
 	return cp, nil
 }
@@ -66,3 +179,48 @@ func New(ctx context.Context, identity, csURL string, version ocpp.Version, tran
 func (cp *chargePoint) Identity() string {
 	return cp.identity
 }
+
+func (cp *chargePoint) Send(chargerID string, req cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cp.requestTimeout)
+	defer cancel()
+	return cp.SendContext(ctx, chargerID, req)
+}
+
+func (cp *chargePoint) SendContext(ctx context.Context, chargerID string, req cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error) {
+	return cp.send(ctx, req, func(ctx context.Context, req cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error) {
+		svc, err := cp.getCentralSystem()
+		if err != nil {
+			return nil, err
+		}
+		return svc.SendContext(ctx, chargerID, req)
+	})
+}
+
+// certificateIdentity is the CN of the client certificate
+func certificateIdentity(config *tls.Config) (string, error) {
+	if config == nil || len(config.Certificates) == 0 {
+		return "", errors.New("no identity, nor client certificate to take it from")
+	}
+	certificate := config.Certificates[0]
+	leaf := certificate.Leaf
+	if leaf == nil {
+		if len(certificate.Certificate) == 0 {
+			return "", errors.New("empty client certificate")
+		}
+		var err error
+		leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+		if err != nil {
+			return "", fmt.Errorf("could not parse client certificate: %w", err)
+		}
+	}
+	return leaf.Subject.CommonName, nil
+}
+
+func (cp *chargePoint) getCentralSystem() (service.CentralSystem, error) {
+	cp.connMux.Lock()
+	defer cp.connMux.Unlock()
+	if cp.centralSystem == nil {
+		return nil, errors.New("no connection to the central system")
+	}
+	return cp.centralSystem, nil
+}
@@ -0,0 +1,28 @@
+package cp
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithTLSConfig(t *testing.T) {
+	certificate := tls.Certificate{Certificate: [][]byte{[]byte("cert")}}
+
+	cp := &chargePoint{}
+	WithClientCertificate(certificate)(cp)
+	WithTLSConfig(nil)(cp)
+	if assert.NotNil(t, cp.tlsConfig) {
+		assert.Equal(t, []tls.Certificate{certificate}, cp.tlsConfig.Certificates)
+	}
+
+	config := &tls.Config{ServerName: "cs.example.com"}
+	cp = &chargePoint{}
+	WithClientCertificate(certificate)(cp)
+	WithTLSConfig(config)(cp)
+	assert.Equal(t, "cs.example.com", cp.tlsConfig.ServerName)
+	assert.Equal(t, []tls.Certificate{certificate}, cp.tlsConfig.Certificates)
+	// the given one isn't changed
+	assert.Empty(t, config.Certificates)
+}
@@ -0,0 +1,71 @@
+package cp
+
+import (
+	"context"
+
+	"github.com/michaelbironneau/go-ocpp/interceptor"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+)
+
+// WithInterceptors wraps the requests of the central system and the ones
+// sent to it, whatever the transport, the first interceptor being the
+// outermost, e.g. interceptor.Recovery()
+func WithInterceptors(interceptors ...interceptor.Interceptor) Option {
+	return func(cp *chargePoint) {
+		cp.interceptor = interceptor.Chain(interceptors...)
+	}
+}
+
+// intercepted handles the requests of the central system through the interceptors
+func (cp *chargePoint) intercepted(cshandler CentralSystemMessageHandler) CentralSystemMessageHandler {
+	if cp.interceptor == nil {
+		return cshandler
+	}
+	return func(csrequest csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		info := &interceptor.Info{
+			ChargePointID: cp.identity,
+			Action:        csrequest.Action(),
+			Direction:     interceptor.Inbound,
+			Transport:     cp.transport,
+		}
+		resp, err := cp.interceptor(cp.ctx, csrequest, info, func(ctx context.Context, req messages.Request) (messages.Response, error) {
+			return cshandler(req.(csreq.CentralSystemRequest))
+		})
+		if err != nil || resp == nil {
+			return nil, err
+		}
+		csresponse, ok := resp.(csresp.CentralSystemResponse)
+		if !ok {
+			return nil, csresp.ErrorNotCentralSystemResponse
+		}
+		return csresponse, nil
+	}
+}
+
+// send the request to the central system through the interceptors
+func (cp *chargePoint) send(ctx context.Context, req cpreq.ChargePointRequest, send func(ctx context.Context, req cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error)) (cpresp.ChargePointResponse, error) {
+	if cp.interceptor == nil {
+		return send(ctx, req)
+	}
+	info := &interceptor.Info{
+		ChargePointID: cp.identity,
+		Action:        req.Action(),
+		Direction:     interceptor.Outbound,
+		Transport:     cp.transport,
+	}
+	resp, err := cp.interceptor(ctx, req, info, func(ctx context.Context, req messages.Request) (messages.Response, error) {
+		return send(ctx, req.(cpreq.ChargePointRequest))
+	})
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	cpresponse, ok := resp.(cpresp.ChargePointResponse)
+	if !ok {
+		return nil, cpresp.ErrorNotChargePointResponse
+	}
+	return cpresponse, nil
+}
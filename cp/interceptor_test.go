@@ -0,0 +1,108 @@
+package cp
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/interceptor"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/soap"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Interceptors(t *testing.T) {
+	centralSystem := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		resp, _ := soap.Marshal(&cpresp.Heartbeat{}, nil, "http://www.w3.org/2003/05/soap-envelope")
+		w.Write(resp)
+	}))
+	defer centralSystem.Close()
+
+	var infos []interceptor.Info
+	var infosMux sync.Mutex
+	record := func(ctx context.Context, req messages.Request, info *interceptor.Info, next interceptor.Handler) (messages.Response, error) {
+		infosMux.Lock()
+		infos = append(infos, *info)
+		infosMux.Unlock()
+		return next(ctx, req)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	port := "127.0.0.1:0"
+	cpoint, err := New(ctx, "soapCP", centralSystem.URL, ocpp.V15, ocpp.SOAP, &port, nil, func(req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		if _, ok := req.(*csreq.ClearCache); ok {
+			panic("boom")
+		}
+		return &csresp.Reset{Status: "Accepted"}, nil
+	}, WithInterceptors(interceptor.Recovery(), record))
+	if !assert.NoError(t, err) {
+		return
+	}
+	<-cpoint.WaitConnect()
+	addr := "http://" + cpoint.(*chargePoint).soapListener.Addr().String() + "/"
+
+	t.Run("outbound", func(t *testing.T) {
+		resp, err := cpoint.Send("soapCP", &cpreq.Heartbeat{})
+		assert.NoError(t, err)
+		assert.IsType(t, &cpresp.Heartbeat{}, resp)
+	})
+
+	t.Run("inbound", func(t *testing.T) {
+		resp := &csresp.Reset{}
+		assert.NoError(t, soap.NewClient(addr).Call("Reset", &csreq.Reset{Type: "Soft"}, resp, nil))
+		assert.Equal(t, enums.ResetStatusAccepted, resp.Status)
+	})
+
+	t.Run("inbound panic", func(t *testing.T) {
+		assert.Error(t, soap.NewClient(addr).Call("ClearCache", &csreq.ClearCache{}, &csresp.ClearCache{}, nil))
+	})
+
+	infosMux.Lock()
+	defer infosMux.Unlock()
+	assert.Equal(t, []interceptor.Info{
+		{ChargePointID: "soapCP", Action: "Heartbeat", Direction: interceptor.Outbound, Transport: ocpp.SOAP},
+		{ChargePointID: "soapCP", Action: "Reset", Direction: interceptor.Inbound, Transport: ocpp.SOAP},
+		{ChargePointID: "soapCP", Action: "ClearCache", Direction: interceptor.Inbound, Transport: ocpp.SOAP},
+	}, infos)
+}
+
+func Test_InterceptorContext(t *testing.T) {
+	// the central system never answers
+	centralSystem := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		<-r.Context().Done()
+	}))
+	defer centralSystem.Close()
+
+	// the interceptor gives the central system a second to answer
+	deadline := func(ctx context.Context, req messages.Request, info *interceptor.Info, next interceptor.Handler) (messages.Response, error) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		return next(ctx, req)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	port := "127.0.0.1:0"
+	cpoint, err := New(ctx, "soapCP", centralSystem.URL, ocpp.V15, ocpp.SOAP, &port, nil, nil, WithInterceptors(deadline))
+	if !assert.NoError(t, err) {
+		return
+	}
+	<-cpoint.WaitConnect()
+
+	started := time.Now()
+	_, err = cpoint.Send("soapCP", &cpreq.Heartbeat{})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	assert.True(t, time.Since(started) < 10*time.Second)
+}
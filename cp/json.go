@@ -1,8 +1,7 @@
 package cp
 
 import (
-	"github.com/michaelbironneau/go-ocpp/cs"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"strings"
 	"time"
 
 	"github.com/michaelbironneau/go-ocpp/internal/log"
@@ -16,56 +15,73 @@ const (
 )
 
 func (cp *chargePoint) Connection() *ws.Conn {
+	cp.connMux.Lock()
+	defer cp.connMux.Unlock()
 	return cp.conn
 }
 
 // tries to reach CS, if succeeded handle
 func (cp *chargePoint) getNewWebsocketConnection() error {
-	conn, err := ws.Dial(cp.centralSystemURL, cp.version, cp.headers)
+	// the central system identifies the charge point by the URL path
+	csURL := strings.TrimSuffix(cp.centralSystemURL, "/") + "/" + cp.identity
+	conn, err := ws.DialTLS(csURL, cp.version, cp.headers, cp.tlsConfig)
 	if err != nil {
 		return err
 	}
+	conn.SetRequestTimeout(cp.requestTimeout)
+	conn.SetQueueOptions(cp.queueOptions)
+	conn.SetSchemaValidation(cp.schemaValidation)
+	cp.connMux.Lock()
 	cp.conn = conn
-	cp.CentralSystem = service.NewCentralSystemJSON(cp.conn)
+	cp.centralSystem = service.NewCentralSystemJSON(conn)
 	// closing the channel will make the reads non blocking
 	close(cp.connectedChan)
+	cp.connMux.Unlock()
 	return nil
 }
 
-func (cp *chargePoint) handleWebsocketConnection(cshandler cs.ChargePointMessageHandler) {
+func (cp *chargePoint) handleWebsocketConnection(cshandler CentralSystemMessageHandler) {
 	log.Debug("Handling websocket connection...")
+	conn := cp.Connection()
 	for {
 		select {
 		case <-cp.ctx.Done():
-			cp.conn.Close()
+			conn.Close()
 			return
-		case <-cp.conn.WaitClose():
+		case <-conn.WaitClose():
 			log.Debug("Closed connection of Central System")
+			cp.connMux.Lock()
 			cp.connectedChan = make(chan struct{})
+			cp.connMux.Unlock()
 			// try to connect until it is established
 			for {
 				err := cp.getNewWebsocketConnection()
-				if err != nil {
-					log.Error("On restarting connection with Central System: %w", err)
-					<-time.After(websocketConnectionRetryInterval)
-				} else {
+				if err == nil {
 					log.Debug("Got new connection")
 					break
 				}
+				log.Error("On restarting connection with Central System: %w", err)
+				select {
+				case <-cp.ctx.Done():
+					return
+				case <-time.After(websocketConnectionRetryInterval):
+				}
 			}
-		case err := <-cp.conn.ReadMessageAsync():
+			conn = cp.Connection()
+		case err := <-conn.ReadMessageAsync():
 			if err != nil {
 				log.Error("Error reading message: %v", err)
 			}
 			continue
-		case req := <-cp.conn.Requests():
+		case req := <-conn.Requests():
 			log.Debug("Received request")
-			cprequest, ok := req.Request.(cpreq.ChargePointRequest)
+			csrequest, ok := req.Request.(csreq.CentralSystemRequest)
 			if !ok {
 				log.Error(csreq.ErrorNotCentralSystemRequest.Error())
+				continue
 			}
-			cpresponse, err := cshandler(cprequest, cs.ChargePointRequestMetadata{ChargePointID: req.ChargerID})
-			err = cp.conn.SendResponse(req.MessageID, cpresponse, err)
+			csresponse, err := cshandler(csrequest)
+			err = conn.SendResponse(req.MessageID, csresponse, err)
 			if err != nil {
 				log.Error(err.Error())
 			}
@@ -74,9 +90,16 @@ func (cp *chargePoint) handleWebsocketConnection(cshandler cs.ChargePointMessage
 }
 
 func (cp *chargePoint) WaitConnect() <-chan struct{} {
+	cp.connMux.Lock()
+	defer cp.connMux.Unlock()
 	return cp.connectedChan
 }
 
 func (cp *chargePoint) WaitDisconnect() <-chan struct{} {
-	return cp.conn.WaitClose()
+	conn := cp.Connection()
+	if conn == nil {
+		// SOAP charge points are only done with their context
+		return cp.ctx.Done()
+	}
+	return conn.WaitClose()
 }
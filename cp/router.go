@@ -0,0 +1,251 @@
+package cp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/ws"
+)
+
+// ErrNoResponse is returned to the central system when
+// a handler returns neither a response nor an error
+var ErrNoResponse = fmt.Errorf("%w: handler gave no response", ws.InternalError)
+
+type actionHandler func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error)
+
+// Router dispatches each central system request to the handler
+// registered for its action. The others get the reply the OCPP 1.6
+// spec gives to a charge point without the feature, e.g. a Rejected
+// status, see defaultResponse. Its Handle method is the handler to
+// give to New:
+//
+//	router := cp.NewRouter()
+//	router.OnReset(func(ctx context.Context, req *csreq.Reset) (*csresp.Reset, error) {
+//		return &csresp.Reset{Status: "Accepted"}, nil
+//	})
+//	st, err := cp.New(ctx, stationID, centralSystemURL, ocpp.V16, ocpp.JSON, nil, nil, router.Handle)
+type Router struct {
+	mux      sync.RWMutex
+	handlers map[string]actionHandler
+}
+
+func NewRouter() *Router {
+	return &Router{
+		handlers: make(map[string]actionHandler),
+	}
+}
+
+// Handle the request with the handler of its action,
+// or with the default reply if there is none. The
+// central system requests have no context of their
+// own, so the handlers get a background one.
+func (router *Router) Handle(req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	router.mux.RLock()
+	handler, ok := router.handlers[req.Action()]
+	router.mux.RUnlock()
+	if !ok {
+		return defaultResponse(req)
+	}
+	return handler(context.Background(), req)
+}
+
+func (router *Router) on(action string, handler actionHandler) {
+	router.mux.Lock()
+	defer router.mux.Unlock()
+	router.handlers[action] = handler
+}
+
+// defaultResponse is the reply of a charge point which doesn't
+// support the requested feature, or an error if the spec has none
+func defaultResponse(req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	switch req := req.(type) {
+	case *csreq.CancelReservation:
+		return &csresp.CancelReservation{Status: "Rejected"}, nil
+	case *csreq.ChangeAvailability:
+		return &csresp.ChangeAvailability{Status: "Rejected"}, nil
+	case *csreq.ChangeConfiguration:
+		return &csresp.ChangeConfiguration{Status: "NotSupported"}, nil
+	case *csreq.ClearCache:
+		return &csresp.ClearCache{Status: "Rejected"}, nil
+	case *csreq.ClearChargingProfile:
+		return &csresp.ClearChargingProfile{Status: "Unknown"}, nil
+	case *csreq.DataTransfer:
+		return &csresp.DataTransfer{Status: "UnknownVendorId"}, nil
+	case *csreq.GetCompositeSchedule:
+		return &csresp.GetCompositeSchedule{Status: "Rejected"}, nil
+	case *csreq.GetConfiguration:
+		// every requested key is unknown
+		return &csresp.GetConfiguration{UnknownKey: req.Key}, nil
+	case *csreq.GetDiagnostics:
+		// no diagnostics file
+		return &csresp.GetDiagnostics{}, nil
+	case *csreq.GetLocalListVersion:
+		// local authorization list not supported
+		return &csresp.GetLocalListVersion{ListVersion: -1}, nil
+	case *csreq.RemoteStartTransaction:
+		return &csresp.RemoteStartTransaction{Status: "Rejected"}, nil
+	case *csreq.RemoteStopTransaction:
+		return &csresp.RemoteStopTransaction{Status: "Rejected"}, nil
+	case *csreq.ReserveNow:
+		return &csresp.ReserveNow{Status: "Rejected"}, nil
+	case *csreq.Reset:
+		return &csresp.Reset{Status: "Rejected"}, nil
+	case *csreq.SendLocalList:
+		return &csresp.SendLocalList{Status: "NotSupported"}, nil
+	case *csreq.SetChargingProfile:
+		return &csresp.SetChargingProfile{Status: "NotSupported"}, nil
+	case *csreq.TriggerMessage:
+		return &csresp.TriggerMessage{Status: "NotImplemented"}, nil
+	case *csreq.UnlockConnector:
+		return &csresp.UnlockConnector{Status: "NotSupported"}, nil
+	}
+	// e.g. UpdateFirmware, which can't be refused
+	return nil, fmt.Errorf("%w: no handler of %s", ws.NotImplemented, req.Action())
+}
+
+// response makes sure a nil response isn't sent as a typed nil
+func response(resp csresp.CentralSystemResponse, isNil bool, err error) (csresp.CentralSystemResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	if isNil {
+		return nil, ErrNoResponse
+	}
+	return resp, nil
+}
+
+func (router *Router) OnCancelReservation(handler func(ctx context.Context, req *csreq.CancelReservation) (*csresp.CancelReservation, error)) {
+	router.on("CancelReservation", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.CancelReservation))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnChangeAvailability(handler func(ctx context.Context, req *csreq.ChangeAvailability) (*csresp.ChangeAvailability, error)) {
+	router.on("ChangeAvailability", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.ChangeAvailability))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnChangeConfiguration(handler func(ctx context.Context, req *csreq.ChangeConfiguration) (*csresp.ChangeConfiguration, error)) {
+	router.on("ChangeConfiguration", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.ChangeConfiguration))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnClearCache(handler func(ctx context.Context, req *csreq.ClearCache) (*csresp.ClearCache, error)) {
+	router.on("ClearCache", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.ClearCache))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnClearChargingProfile(handler func(ctx context.Context, req *csreq.ClearChargingProfile) (*csresp.ClearChargingProfile, error)) {
+	router.on("ClearChargingProfile", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.ClearChargingProfile))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnDataTransfer(handler func(ctx context.Context, req *csreq.DataTransfer) (*csresp.DataTransfer, error)) {
+	router.on("DataTransfer", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.DataTransfer))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnGetCompositeSchedule(handler func(ctx context.Context, req *csreq.GetCompositeSchedule) (*csresp.GetCompositeSchedule, error)) {
+	router.on("GetCompositeSchedule", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.GetCompositeSchedule))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnGetConfiguration(handler func(ctx context.Context, req *csreq.GetConfiguration) (*csresp.GetConfiguration, error)) {
+	router.on("GetConfiguration", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.GetConfiguration))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnGetDiagnostics(handler func(ctx context.Context, req *csreq.GetDiagnostics) (*csresp.GetDiagnostics, error)) {
+	router.on("GetDiagnostics", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.GetDiagnostics))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnGetLocalListVersion(handler func(ctx context.Context, req *csreq.GetLocalListVersion) (*csresp.GetLocalListVersion, error)) {
+	router.on("GetLocalListVersion", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.GetLocalListVersion))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnRemoteStartTransaction(handler func(ctx context.Context, req *csreq.RemoteStartTransaction) (*csresp.RemoteStartTransaction, error)) {
+	router.on("RemoteStartTransaction", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.RemoteStartTransaction))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnRemoteStopTransaction(handler func(ctx context.Context, req *csreq.RemoteStopTransaction) (*csresp.RemoteStopTransaction, error)) {
+	router.on("RemoteStopTransaction", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.RemoteStopTransaction))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnReserveNow(handler func(ctx context.Context, req *csreq.ReserveNow) (*csresp.ReserveNow, error)) {
+	router.on("ReserveNow", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.ReserveNow))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnReset(handler func(ctx context.Context, req *csreq.Reset) (*csresp.Reset, error)) {
+	router.on("Reset", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.Reset))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnSendLocalList(handler func(ctx context.Context, req *csreq.SendLocalList) (*csresp.SendLocalList, error)) {
+	router.on("SendLocalList", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.SendLocalList))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnSetChargingProfile(handler func(ctx context.Context, req *csreq.SetChargingProfile) (*csresp.SetChargingProfile, error)) {
+	router.on("SetChargingProfile", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.SetChargingProfile))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnTriggerMessage(handler func(ctx context.Context, req *csreq.TriggerMessage) (*csresp.TriggerMessage, error)) {
+	router.on("TriggerMessage", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.TriggerMessage))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnUnlockConnector(handler func(ctx context.Context, req *csreq.UnlockConnector) (*csresp.UnlockConnector, error)) {
+	router.on("UnlockConnector", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.UnlockConnector))
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnUpdateFirmware(handler func(ctx context.Context, req *csreq.UpdateFirmware) (*csresp.UpdateFirmware, error)) {
+	router.on("UpdateFirmware", func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		resp, err := handler(ctx, req.(*csreq.UpdateFirmware))
+		return response(resp, resp == nil, err)
+	})
+}
@@ -0,0 +1,58 @@
+package cp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/ws"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Router(t *testing.T) {
+	router := NewRouter()
+	router.OnReset(func(ctx context.Context, req *csreq.Reset) (*csresp.Reset, error) {
+		assert.NotNil(t, ctx)
+		return &csresp.Reset{Status: "Accepted"}, nil
+	})
+	router.OnRemoteStartTransaction(func(ctx context.Context, req *csreq.RemoteStartTransaction) (*csresp.RemoteStartTransaction, error) {
+		return nil, nil
+	})
+
+	t.Run("registered", func(t *testing.T) {
+		resp, err := router.Handle(&csreq.Reset{Type: "Soft"})
+		assert.NoError(t, err)
+		assert.Equal(t, &csresp.Reset{Status: "Accepted"}, resp)
+	})
+
+	t.Run("no response", func(t *testing.T) {
+		resp, err := router.Handle(&csreq.RemoteStartTransaction{IdTag: "ABC"})
+		assert.Nil(t, resp)
+		assert.True(t, errors.Is(err, ws.InternalError))
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		cases := []struct {
+			req  csreq.CentralSystemRequest
+			resp csresp.CentralSystemResponse
+		}{
+			{&csreq.RemoteStopTransaction{TransactionId: 1}, &csresp.RemoteStopTransaction{Status: "Rejected"}},
+			{&csreq.ChangeConfiguration{Key: "HeartbeatInterval", Value: "60"}, &csresp.ChangeConfiguration{Status: "NotSupported"}},
+			{&csreq.GetConfiguration{Key: []string{"HeartbeatInterval"}}, &csresp.GetConfiguration{UnknownKey: []string{"HeartbeatInterval"}}},
+			{&csreq.GetLocalListVersion{}, &csresp.GetLocalListVersion{ListVersion: -1}},
+			{&csreq.DataTransfer{VendorId: "acme"}, &csresp.DataTransfer{Status: "UnknownVendorId"}},
+			{&csreq.TriggerMessage{RequestedMessage: "Heartbeat"}, &csresp.TriggerMessage{Status: "NotImplemented"}},
+			{&csreq.UnlockConnector{ConnectorId: 1}, &csresp.UnlockConnector{Status: "NotSupported"}},
+		}
+		for _, c := range cases {
+			resp, err := router.Handle(c.req)
+			assert.NoError(t, err, c.req.Action())
+			assert.Equal(t, c.resp, resp, c.req.Action())
+		}
+
+		_, err := router.Handle(&csreq.UpdateFirmware{})
+		assert.True(t, errors.Is(err, ws.NotImplemented))
+	})
+}
@@ -1,30 +1,74 @@
 package cp
 
 import (
-	"context"
 	"errors"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/michaelbironneau/go-ocpp/internal/log"
+	"github.com/michaelbironneau/go-ocpp/internal/service"
 	"github.com/michaelbironneau/go-ocpp/messages"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
 	"github.com/michaelbironneau/go-ocpp/soap"
 )
 
-func handleSoap(ctx context.Context, port string, cshandler CentralSystemMessageHandler) error {
-	handler := soapHandler{cshandler}
-	server := &http.Server{Addr: port, Handler: handler}
-
-	serverErrorCh := make(chan error)
+// startSoap listens on the port for the requests of the central system,
+// until the context of the charge point is done, and sends the requests
+// of the charge point through SOAP calls
+func (cp *chargePoint) startSoap(port string, cshandler CentralSystemMessageHandler) error {
+	listener, err := net.Listen("tcp", port)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: soapHandler{cshandler}}
 	go func() {
-		serverErrorCh <- server.ListenAndServe()
+		err := server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			log.Error("SOAP server stopped: %w", err)
+		}
 	}()
-	select {
-	case <-ctx.Done():
-		return nil
-	case err := <-serverErrorCh:
-		return err
+	go func() {
+		<-cp.ctx.Done()
+		server.Close()
+	}()
+
+	endpoint := cp.soapEndpoint
+	if endpoint == "" {
+		endpoint = defaultSoapEndpoint(cp.centralSystemURL, listener.Addr())
+	}
+	log.Debug("Charge point listening to SOAP requests on: %s", endpoint)
+
+	cp.connMux.Lock()
+	cp.soapListener = listener
+	cp.centralSystem = service.NewCentralSystemSOAP(cp.centralSystemURL, &soap.CallOptions{
+		From:              soap.CallOptionsFrom{Address: endpoint},
+		ChargeBoxIdentity: cp.identity,
+	}, cp.requestTimeout)
+	// there is no connection to wait for
+	close(cp.connectedChan)
+	cp.connMux.Unlock()
+	return nil
+}
+
+// defaultSoapEndpoint is built from the local address used to reach
+// the central system and the port the charge point listens on
+func defaultSoapEndpoint(csURL string, addr net.Addr) string {
+	host := addr.(*net.TCPAddr).IP.String()
+	port := strconv.Itoa(addr.(*net.TCPAddr).Port)
+	if u, err := url.Parse(csURL); err == nil {
+		csPort := u.Port()
+		if csPort == "" {
+			csPort = "80"
+		}
+		// dialing UDP doesn't send anything, it only picks the route
+		if conn, err := net.Dial("udp", net.JoinHostPort(u.Hostname(), csPort)); err == nil {
+			host = conn.LocalAddr().(*net.UDPAddr).IP.String()
+			conn.Close()
+		}
 	}
+	return "http://" + net.JoinHostPort(host, port) + "/"
 }
 
 type soapHandler struct{ cshandler CentralSystemMessageHandler }
@@ -35,7 +79,7 @@ func (s soapHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	err := soap.Handle(w, r, func(request messages.Request, cpID string) (messages.Response, error) {
 		req, ok := request.(csreq.CentralSystemRequest)
 		if !ok {
-			return nil, errors.New("request is not a cprequest")
+			return nil, errors.New("request is not a csrequest")
 		}
 		return s.cshandler(req)
 	})
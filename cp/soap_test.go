@@ -0,0 +1,81 @@
+package cp
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/soap"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SOAPChargePoint(t *testing.T) {
+	headers := make(chan soap.Header, 1)
+	centralSystem := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := ioutil.ReadAll(r.Body)
+		env, err := soap.Unmarshal(raw)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.IsType(t, &cpreq.Heartbeat{}, env.Body.Content)
+		headers <- soap.Header(*env.Header)
+		resp, _ := soap.Marshal(&cpresp.Heartbeat{}, nil, "http://www.w3.org/2003/05/soap-envelope")
+		w.Write(resp)
+	}))
+	defer centralSystem.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	port := "127.0.0.1:0"
+	endpoint := "http://charger.example.com:8080/"
+	cpoint, err := New(ctx, "soapCP", centralSystem.URL, ocpp.V15, ocpp.SOAP, &port, nil, func(req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		return &csresp.Reset{Status: "Accepted"}, nil
+	}, WithSOAPEndpoint(endpoint))
+	if !assert.NoError(t, err) {
+		return
+	}
+	<-cpoint.WaitConnect()
+
+	t.Run("sends requests with its endpoint", func(t *testing.T) {
+		resp, err := cpoint.Send("soapCP", &cpreq.Heartbeat{})
+		assert.NoError(t, err)
+		assert.IsType(t, &cpresp.Heartbeat{}, resp)
+		header := <-headers
+		assert.Equal(t, endpoint, header.From.Address)
+		assert.Equal(t, "soapCP", header.ChargeBoxIdentity)
+	})
+
+	t.Run("handles central system requests", func(t *testing.T) {
+		addr := cpoint.(*chargePoint).soapListener.Addr().String()
+		resp := &csresp.Reset{}
+		err := soap.NewClient("http://"+addr+"/").Call("Reset", &csreq.Reset{Type: "Soft"}, resp, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, enums.ResetStatusAccepted, resp.Status)
+	})
+
+	t.Run("default endpoint", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cpoint, err := New(ctx, "soapCP", centralSystem.URL, ocpp.V15, ocpp.SOAP, &port, nil, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = cpoint.Send("soapCP", &cpreq.Heartbeat{})
+		assert.NoError(t, err)
+		addr := cpoint.(*chargePoint).soapListener.Addr().String()
+		assert.Equal(t, "http://"+addr+"/", (<-headers).From.Address)
+	})
+
+	t.Run("needs a port", func(t *testing.T) {
+		_, err := New(ctx, "soapCP", centralSystem.URL, ocpp.V15, ocpp.SOAP, nil, nil, nil)
+		assert.Error(t, err)
+	})
+}
@@ -1,5 +1,11 @@
 package cpstatus
 
+import (
+	"fmt"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+)
+
 type ErrorCode string
 
 const (
@@ -11,6 +17,8 @@ const (
 	GroundFailure ErrorCode = "GroundFailure"
 	// Temperature inside Charge Point is too high.
 	HighTemperature ErrorCode = "HighTemperature"
+	// OCPP 1.5 only, replaced by EVCommunicationError in OCPP 1.6.
+	Mode3Error ErrorCode = "Mode3Error"
 	// Error in internal hard- or software component.
 	InternalError ErrorCode = "InternalError"
 	// The authorization information received from the Central System is in conflict with the LocalAuthorizationList.
@@ -36,3 +44,30 @@ const (
 	// Wireless communication device reports a weak signal.
 	WeakSignal ErrorCode = "WeakSignal"
 )
+
+// IsValid tells whether it's one of the error codes
+func (code ErrorCode) IsValid() bool {
+	switch code {
+	case ConnectorLockFailure, EVCommunicationError, GroundFailure, HighTemperature,
+		Mode3Error, InternalError, LocalListConflict, NoError, OtherError,
+		OverCurrentFailure, OverVoltage, PowerMeterFailure, PowerSwitchFailure,
+		ReaderFailure, ResetFailure, UnderVoltage, WeakSignal:
+		return true
+	}
+	return false
+}
+
+func (code ErrorCode) MarshalText() ([]byte, error) {
+	if code != "" && !code.IsValid() {
+		return nil, fmt.Errorf("%w: %q isn't an ErrorCode", enums.ErrUnknownValue, string(code))
+	}
+	return []byte(code), nil
+}
+
+func (code *ErrorCode) UnmarshalText(text []byte) error {
+	if len(text) > 0 && !ErrorCode(text).IsValid() {
+		return fmt.Errorf("%w: %q isn't an ErrorCode", enums.ErrUnknownValue, text)
+	}
+	*code = ErrorCode(text)
+	return nil
+}
@@ -0,0 +1,95 @@
+package cs
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrUnauthorized is returned by the built-in authenticators
+	// when a charge point isn't allowed to connect
+	ErrUnauthorized = errors.New("charge point is not authorized")
+)
+
+// Authenticator decides whether a charge point may connect, it's called
+// before the websocket upgrade with the charge point ID taken from the URL,
+// or before a SOAP request is handled with its chargeBoxIdentity, and the
+// TLS state of the connection, nil on plain HTTP.
+// Any error rejects the charge point with a 401, or a SOAP fault.
+type Authenticator interface {
+	Authenticate(r *http.Request, cpID string, tlsState *tls.ConnectionState) error
+}
+
+// AuthenticatorFunc lets a plain function be an Authenticator
+type AuthenticatorFunc func(r *http.Request, cpID string, tlsState *tls.ConnectionState) error
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request, cpID string, tlsState *tls.ConnectionState) error {
+	return f(r, cpID, tlsState)
+}
+
+// PasswordLookup returns the password of the charge point,
+// false if it's unknown
+type PasswordLookup func(cpID string) (password string, ok bool)
+
+// BasicAuth authenticates charge points through HTTP Basic Auth as in the
+// OCPP 1.6 Security Profile 1: the username is the charge point ID and
+// the password the one the lookup gives for it
+func BasicAuth(lookup PasswordLookup) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request, cpID string, tlsState *tls.ConnectionState) error {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != cpID {
+			return ErrUnauthorized
+		}
+		expected, ok := lookup(cpID)
+		if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(expected)) != 1 {
+			return ErrUnauthorized
+		}
+		return nil
+	})
+}
+
+// Allowlist only lets the given charge points connect
+func Allowlist(cpIDs ...string) Authenticator {
+	allowed := make(map[string]bool, len(cpIDs))
+	for _, cpID := range cpIDs {
+		allowed[cpID] = true
+	}
+	return AuthenticatorFunc(func(r *http.Request, cpID string, tlsState *tls.ConnectionState) error {
+		if !allowed[cpID] {
+			return ErrUnauthorized
+		}
+		return nil
+	})
+}
+
+// AllOf only lets a charge point connect if
+// every authenticator lets it, e.g. an allowlist
+// and its basic auth credentials
+func AllOf(authenticators ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request, cpID string, tlsState *tls.ConnectionState) error {
+		for _, authenticator := range authenticators {
+			if err := authenticator.Authenticate(r, cpID, tlsState); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// unauthorized tells the charge point to authenticate
+// through basic auth, without telling why it was rejected
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="OCPP"`)
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// certificateIdentity is the CN of the verified client
+// certificate, empty if the charge point didn't send any
+func certificateIdentity(tlsState *tls.ConnectionState) string {
+	if tlsState == nil || len(tlsState.VerifiedChains) == 0 {
+		return ""
+	}
+	return tlsState.VerifiedChains[0][0].Subject.CommonName
+}
@@ -0,0 +1,133 @@
+package cs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BasicAuth(t *testing.T) {
+	passwords := map[string]string{"cp1": "secret"}
+	auth := BasicAuth(func(cpID string) (string, bool) {
+		password, ok := passwords[cpID]
+		return password, ok
+	})
+	request := func(username, password string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/cp1", nil)
+		if username != "" {
+			r.SetBasicAuth(username, password)
+		}
+		return r
+	}
+
+	assert.NoError(t, auth.Authenticate(request("cp1", "secret"), "cp1", nil))
+	assert.Equal(t, ErrUnauthorized, auth.Authenticate(request("cp1", "wrong"), "cp1", nil))
+	assert.Equal(t, ErrUnauthorized, auth.Authenticate(request("", ""), "cp1", nil))
+	// the username must be the charge point ID
+	assert.Equal(t, ErrUnauthorized, auth.Authenticate(request("cp1", "secret"), "cp2", nil))
+	assert.Equal(t, ErrUnauthorized, auth.Authenticate(request("cp2", ""), "cp2", nil))
+}
+
+func Test_Allowlist(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/cp1", nil)
+	auth := Allowlist("cp1", "cp2")
+	assert.NoError(t, auth.Authenticate(r, "cp1", nil))
+	assert.Equal(t, ErrUnauthorized, auth.Authenticate(r, "cp3", nil))
+
+	both := AllOf(auth, BasicAuth(func(cpID string) (string, bool) { return "secret", true }))
+	r.SetBasicAuth("cp3", "secret")
+	assert.Equal(t, ErrUnauthorized, both.Authenticate(r, "cp3", nil))
+	r.SetBasicAuth("cp1", "secret")
+	assert.NoError(t, both.Authenticate(r, "cp1", nil))
+}
+
+func Test_AuthenticatedConnection(t *testing.T) {
+	csys := New(WithAuthenticator(BasicAuth(func(cpID string) (string, bool) {
+		return "secret", cpID == "cp1"
+	}))).(*centralSystem)
+	handled := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		csys.handleWebsocket(w, r, func(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+			handled <- metadata.ChargePointID
+			return &cpresp.Heartbeat{}, nil
+		})
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+	auth := func(username, password string) http.Header {
+		r := &http.Request{Header: http.Header{}}
+		r.SetBasicAuth(username, password)
+		return r.Header
+	}
+
+	t.Run("rejected", func(t *testing.T) {
+		for _, header := range []http.Header{nil, auth("cp1", "wrong"), auth("cp2", "secret")} {
+			_, resp, err := dialer.Dial(url+"/cp1", header)
+			assert.Error(t, err)
+			if assert.NotNil(t, resp) {
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+				assert.Equal(t, `Basic realm="OCPP"`, resp.Header.Get("WWW-Authenticate"))
+			}
+		}
+		assert.Len(t, handled, 0)
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		socket, _, err := dialer.Dial(url+"/cp1", auth("cp1", "secret"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer socket.Close()
+		assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(`[2,"1","Heartbeat",{}]`)))
+		assert.Equal(t, "cp1", <-handled)
+	})
+}
+
+// soapHeartbeat is a Heartbeat of the charge point, sent from the endpoint
+const soapHeartbeat = `<?xml version="1.0" encoding="UTF-8"?>
+<S:Envelope xmlns:S="http://www.w3.org/2003/05/soap-envelope">
+  <S:Header xmlns:wsa5="http://www.w3.org/2005/08/addressing">
+    <wsa5:To>http://cs:8080/</wsa5:To>
+    <wsa5:Action>/Heartbeat</wsa5:Action>
+    <wsa5:MessageID>uuid:77638163-b4f8-4cbe-af27-54362ea20bd6</wsa5:MessageID>
+    <wsa5:From><wsa5:Address>%s</wsa5:Address></wsa5:From>
+    <chargeBoxIdentity xmlns="urn://Ocpp/Cs/2012/06/">%s</chargeBoxIdentity>
+  </S:Header>
+  <S:Body>
+    <ocpp:heartbeatRequest xmlns:ocpp="urn://Ocpp/Cs/2012/06/"/>
+  </S:Body>
+</S:Envelope>`
+
+func soapRequest(cpID, from string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(soapHeartbeat, from, cpID)))
+}
+
+func Test_AuthenticatedSoapRequest(t *testing.T) {
+	csys := New(WithAuthenticator(Allowlist("cp1"))).(*centralSystem)
+	handled := make(chan string, 1)
+	handler := func(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		handled <- metadata.ChargePointID
+		return &cpresp.Heartbeat{CurrentTime: time.Now()}, nil
+	}
+
+	w := httptest.NewRecorder()
+	csys.handleSoap(w, soapRequest("cp2", "http://192.0.2.1:8080/"), handler)
+	assert.Contains(t, w.Body.String(), "Fault")
+	assert.Len(t, handled, 0)
+	_, err := csys.GetServiceOf("cp2")
+	assert.Error(t, err, "the endpoint of a rejected charge point isn't learned")
+
+	w = httptest.NewRecorder()
+	csys.handleSoap(w, soapRequest("cp1", "http://192.0.2.1:8080/"), handler)
+	assert.NotContains(t, w.Body.String(), "Fault")
+	assert.Equal(t, "cp1", <-handled)
+}
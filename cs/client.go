@@ -0,0 +1,24 @@
+package cs
+
+//go:generate go run ../internal/gen/clients -side cs
+
+import (
+	"github.com/michaelbironneau/go-ocpp/internal/service"
+)
+
+// ChargePointClient sends typed requests to a charge point, so
+// callers don't have to assert the type of the responses. A
+// response of the wrong type is a *messages.UnexpectedResponseError.
+type ChargePointClient struct {
+	service   service.ChargePoint
+	chargerID string
+}
+
+// NewChargePointClient wraps the service of
+// the charge point, e.g. from GetServiceOf
+func NewChargePointClient(cpID string, svc service.ChargePoint) *ChargePointClient {
+	return &ChargePointClient{
+		service:   svc,
+		chargerID: cpID,
+	}
+}
@@ -0,0 +1,57 @@
+package cs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeChargePoint answers every request with the same response
+type fakeChargePoint struct {
+	resp csresp.CentralSystemResponse
+	err  error
+}
+
+func (cp *fakeChargePoint) Send(chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	return cp.SendContext(context.Background(), chargerID, req)
+}
+
+func (cp *fakeChargePoint) SendContext(ctx context.Context, chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	return cp.resp, cp.err
+}
+
+func Test_ChargePointClient(t *testing.T) {
+	ctx := context.Background()
+	req := &csreq.RemoteStartTransaction{IdTag: "VIRTUAL", ConnectorId: 1}
+
+	t.Run("typed response", func(t *testing.T) {
+		client := NewChargePointClient("CP1", &fakeChargePoint{resp: &csresp.RemoteStartTransaction{Status: "Accepted"}})
+		resp, err := client.RemoteStartTransaction(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, enums.RemoteStartStopStatusAccepted, resp.Status)
+	})
+
+	t.Run("wrong response", func(t *testing.T) {
+		client := NewChargePointClient("CP1", &fakeChargePoint{resp: &csresp.Reset{Status: "Accepted"}})
+		resp, err := client.RemoteStartTransaction(ctx, req)
+		assert.Nil(t, resp)
+		var unexpected *messages.UnexpectedResponseError
+		if assert.True(t, errors.As(err, &unexpected)) {
+			assert.Equal(t, "RemoteStartTransaction", unexpected.Action)
+			assert.IsType(t, &csresp.Reset{}, unexpected.Response)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		sendErr := errors.New("no connection")
+		client := NewChargePointClient("CP1", &fakeChargePoint{err: sendErr})
+		_, err := client.RemoteStartTransaction(ctx, req)
+		assert.Equal(t, sendErr, err)
+	})
+}
@@ -1,44 +1,85 @@
 package cs
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
 	"net/http"
 	"net/http/httputil"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/interceptor"
+	"github.com/michaelbironneau/go-ocpp/internal"
 	"github.com/michaelbironneau/go-ocpp/internal/log"
 	"github.com/michaelbironneau/go-ocpp/internal/service"
 	"github.com/michaelbironneau/go-ocpp/messages"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
 	"github.com/michaelbironneau/go-ocpp/soap"
 	"github.com/michaelbironneau/go-ocpp/ws"
+
+	"github.com/gorilla/websocket"
 )
 
 type ChargePointRequestMetadata struct {
 	ChargePointID string
 	HTTPRequest   *http.Request
+	// Version of OCPP spoken by the charge point, on websockets
+	// it's the one negotiated through the subprotocol
+	Version ocpp.Version
+	// CertificateIdentity is the CN of the client certificate
+	// the charge point connected with, empty without mTLS
+	CertificateIdentity string
+	// Tenant of the charge point, from the URL of its
+	// websocket connection, see WithRouteExtractor
+	Tenant string
+	// afterResponse of the request, see AfterResponse
+	afterResponse *responseHooks
 }
 
 // ChargePointMessageHandler handles the OCPP messages coming from the charger
-type ChargePointMessageHandler func(cprequest cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (csresp.CentralSystemResponse, error)
+type ChargePointMessageHandler func(cprequest cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error)
+
+// ErrShuttingDown is returned by Run once the central system is shut down
+var ErrShuttingDown = http.ErrServerClosed
 
 type ChargePointConnectionListener func(cpID string)
 type CentralSystem interface {
+	// the central system can be mounted on any server or
+	// mux, it serves both websockets and SOAP requests
+	http.Handler
+
 	// Run the central system on the given port
 	// and handles each incoming ChargepointRequest
 	Run(port string, cphandler ChargePointMessageHandler) error
+	// RunTLS is like Run over TLS, the config must hold the
+	// certificate of the central system, and the CAs of the charge
+	// points certificates if they authenticate with them (mTLS)
+	RunTLS(port string, config *tls.Config, cphandler ChargePointMessageHandler) error
+	// Shutdown stops accepting connections, closes the websocket
+	// ones, waits for the requests being handled and fires the
+	// disconnection listeners, or gives up once the context is done
+	Shutdown(ctx context.Context) error
+
+	// SetChargePointMessageHandler sets the handler of the
+	// requests when the central system is used as an http.Handler
+	SetChargePointMessageHandler(ChargePointMessageHandler)
 
 	// GetServiceOf a chargepoint to enable
 	// communication with the chargepoint
 	//
-	// the url parameter is *NOT* used if
-	// the link between the CentralSystem
-	// and Chargepoint is via Websocket
-	GetServiceOf(cpID string, version ocpp.Version, url string) (service.ChargePoint, error)
+	// a chargepoint connected via Websocket is
+	// reached through its connection, a SOAP one
+	// at the endpoint learned from its requests
+	GetServiceOf(cpID string) (service.ChargePoint, error)
+
+	// SetChargePointEndpoint sets where a SOAP chargepoint
+	// is reached, instead of the endpoint it advertises,
+	// e.g. when it sits behind a NAT
+	SetChargePointEndpoint(cpID string, url string)
 
 	SetChargePointConnectionListener(ChargePointConnectionListener)
 	SetChargePointDisconnectionListener(ChargePointConnectionListener)
@@ -49,65 +90,292 @@ type CentralSystem interface {
 type centralSystem struct {
 	conns map[string]*ws.Conn
 	// used to symbolize if the connection is connected
-	connChans       map[string]chan struct{}
-	connsConnected  map[string]bool
-	connsCount      map[string]int
+	connChans      map[string]chan struct{}
+	connsConnected map[string]bool
+	connsCount     map[string]int
+	// closed once the connection is closed and forgotten
+	connsCleanedUp  map[*ws.Conn]chan struct{}
 	connMux         sync.Mutex
 	connListener    ChargePointConnectionListener
 	disconnListener ChargePointConnectionListener
+	// requestTimeout is how long Send waits for a charge point response
+	requestTimeout time.Duration
+	// queueOptions of the outbound call queue of every connection
+	queueOptions ws.QueueOptions
+	// schemaValidation of the payloads of every connection
+	schemaValidation bool
+	// soapEndpoints of the charge points talking SOAP
+	soapEndpoints *soapEndpoints
+	// authenticator of the charge points connecting, if any
+	authenticator Authenticator
+	// routeExtractor gets the charge point ID from the URL
+	routeExtractor RouteExtractor
+	cphandler      ChargePointMessageHandler
+	// interceptor of the inbound and outbound requests, if any
+	interceptor interceptor.Interceptor
+	// store of the charge points, if any, its updates
+	// being serialized so none is lost
+	store    Store
+	storeMux sync.Mutex
+	// lastStored is when each charge point was last stored
+	lastStored    map[string]time.Time
+	lastStoredMux sync.Mutex
+	// servers started by Run and RunTLS
+	servers []*http.Server
+	// handlers being served, waited for on shutdown
+	handlers sync.WaitGroup
+	// set on shutdown, along with the charge points
+	// disconnected since, notified once it's done
+	shuttingDown         bool
+	shutdownDisconnected []string
+	shutdownNotified     bool
+}
+
+// Option configures the central system
+type Option func(*centralSystem)
+
+// WithRequestTimeout sets how long a Send to a charge
+// point waits for its response, as does a SendContext
+// whose context has no deadline
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(csys *centralSystem) {
+		csys.requestTimeout = timeout
+	}
 }
 
-func New() CentralSystem {
-	return &centralSystem{
+// WithCallQueue configures the queue in which calls to a charge
+// point wait for the previous one to be answered, e.g. its depth
+// or which actions to send first
+func WithCallQueue(options ws.QueueOptions) Option {
+	return func(csys *centralSystem) {
+		csys.queueOptions = options
+	}
+}
+
+// WithSchemaValidation validates the payloads sent to and received
+// from the charge points against the OCPP 1.6 JSON schemas
+func WithSchemaValidation() Option {
+	return func(csys *centralSystem) {
+		csys.schemaValidation = true
+	}
+}
+
+// WithAuthenticator checks every charge point before its
+// websocket connection is accepted, and before each of its
+// SOAP requests is handled, e.g. with BasicAuth
+func WithAuthenticator(authenticator Authenticator) Option {
+	return func(csys *centralSystem) {
+		csys.authenticator = authenticator
+	}
+}
+
+// WithRouteExtractor sets how the charge point ID and tenant are
+// taken from the URL of the websocket connections, e.g. with
+// PathPattern. By default the whole path is the charge point ID.
+func WithRouteExtractor(extractor RouteExtractor) Option {
+	return func(csys *centralSystem) {
+		csys.routeExtractor = extractor
+	}
+}
+
+func New(options ...Option) CentralSystem {
+	csys := &centralSystem{
 		conns:           make(map[string]*ws.Conn, 0),
 		connChans:       make(map[string]chan struct{}, 0),
 		connsCount:      make(map[string]int, 0),
 		connsConnected:  make(map[string]bool, 0),
+		connsCleanedUp:  make(map[*ws.Conn]chan struct{}, 0),
 		connListener:    func(cpID string) {},
 		disconnListener: func(cpID string) {},
+		requestTimeout:  internal.DefaultRequestTimeout,
+		soapEndpoints:   newSoapEndpoints(),
+		routeExtractor:  defaultRoute,
+		cphandler: func(cprequest cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+			return nil, errors.New("no handler of the charge point requests")
+		},
 	}
+	for _, option := range options {
+		option(csys)
+	}
+	if csys.store != nil {
+		csys.restore()
+	}
+	return csys
 }
 
+// Run the central system until it's shut down, when
+// it returns ErrShuttingDown
 func (csys *centralSystem) Run(port string, cphandler ChargePointMessageHandler) error {
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if ws.IsWebSocketUpgrade(r) {
-			csys.handleWebsocket(w, r, cphandler)
-		} else if r.Method == http.MethodGet {
-			// it's not a SOAP request
-			// it's someone lurking around in this URL
-			// let's present something nice
-			body := fmt.Sprintf(
-				`<h1>OCPP Central System</h1>
-				<p>currently connected with %d OCPP-J stations, and more OCPP-S stations</p>
-				<i>Central System using <a href="https://github.com/voltbras/go-ocpp"/>https://github.com/voltbras/go-ocpp</i>`,
-				len(csys.conns),
-			)
-			w.Write([]byte(body))
-		} else {
-			csys.handleSoap(w, r, cphandler)
+	csys.SetChargePointMessageHandler(cphandler)
+	server := &http.Server{Addr: port, Handler: csys}
+	if err := csys.addServer(server); err != nil {
+		return err
+	}
+	log.Debug("Central system running on port: %s", port)
+	return server.ListenAndServe()
+}
+
+func (csys *centralSystem) RunTLS(port string, config *tls.Config, cphandler ChargePointMessageHandler) error {
+	csys.SetChargePointMessageHandler(cphandler)
+	server := &http.Server{
+		Addr:      port,
+		Handler:   csys,
+		TLSConfig: config,
+	}
+	if err := csys.addServer(server); err != nil {
+		return err
+	}
+	log.Debug("Central system running TLS on port: %s", port)
+	return server.ListenAndServeTLS("", "")
+}
+
+func (csys *centralSystem) addServer(server *http.Server) error {
+	csys.connMux.Lock()
+	defer csys.connMux.Unlock()
+	if csys.shuttingDown {
+		return ErrShuttingDown
+	}
+	csys.servers = append(csys.servers, server)
+	return nil
+}
+
+func (csys *centralSystem) SetChargePointMessageHandler(cphandler ChargePointMessageHandler) {
+	csys.connMux.Lock()
+	defer csys.connMux.Unlock()
+	csys.cphandler = cphandler
+}
+
+func (csys *centralSystem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && !ws.IsWebSocketUpgrade(r) {
+		// it's not a SOAP request
+		// it's someone lurking around in this URL
+		// let's present something nice
+		csys.connMux.Lock()
+		connected := len(csys.conns)
+		csys.connMux.Unlock()
+		body := fmt.Sprintf(
+			`<h1>OCPP Central System</h1>
+			<p>currently connected with %d OCPP-J stations, and more OCPP-S stations</p>
+			<i>Central System using <a href="https://github.com/voltbras/go-ocpp"/>https://github.com/voltbras/go-ocpp</i>`,
+			connected,
+		)
+		w.Write([]byte(body))
+		return
+	}
+
+	csys.connMux.Lock()
+	if csys.shuttingDown {
+		csys.connMux.Unlock()
+		http.Error(w, "central system is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	csys.handlers.Add(1)
+	cphandler := csys.cphandler
+	csys.connMux.Unlock()
+	defer csys.handlers.Done()
+
+	if ws.IsWebSocketUpgrade(r) {
+		csys.handleWebsocket(w, r, cphandler)
+	} else {
+		csys.handleSoap(w, r, cphandler)
+	}
+}
+
+func (csys *centralSystem) Shutdown(ctx context.Context) error {
+	csys.connMux.Lock()
+	csys.shuttingDown = true
+	servers := csys.servers
+	conns := make([]*ws.Conn, 0, len(csys.connsCleanedUp))
+	for conn := range csys.connsCleanedUp {
+		conns = append(conns, conn)
+	}
+	csys.connMux.Unlock()
+
+	var err error
+	for _, server := range servers {
+		// the websocket connections were hijacked, so
+		// this only stops listening and waits for SOAP
+		if serr := server.Shutdown(ctx); serr != nil && err == nil {
+			err = serr
 		}
+	}
+	for _, conn := range conns {
+		conn.CloseWithReason(websocket.CloseGoingAway, "central system shutting down")
+	}
 
-	})
-	log.Debug("Central system running on port: %s", port)
-	return http.ListenAndServe(port, nil)
+	handlersDone := make(chan struct{})
+	go func() {
+		csys.handlers.Wait()
+		close(handlersDone)
+	}()
+	select {
+	case <-handlersDone:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	csys.connMux.Lock()
+	disconnected := csys.shutdownDisconnected
+	csys.shutdownDisconnected = nil
+	// the handlers still running notify by themselves
+	csys.shutdownNotified = true
+	csys.connMux.Unlock()
+	for _, cpID := range disconnected {
+		csys.disconnListener(cpID)
+	}
+	return err
 }
 
 func (csys *centralSystem) handleWebsocket(w http.ResponseWriter, r *http.Request, cphandler ChargePointMessageHandler) {
-	log.Debug("Current WS connections map: %v", csys.conns)
-	cpID := strings.TrimPrefix(r.URL.Path, "/")
-
-	rawReq, _ := httputil.DumpRequest(r, true)
+	// the credentials of the charge point aren't logged
+	redacted := r.Clone(r.Context())
+	redacted.Header.Del("Authorization")
+	rawReq, _ := httputil.DumpRequest(redacted, false)
 	log.Debug("Raw WS request: %s", string(rawReq))
 
+	route, ok := csys.routeExtractor(r)
+	if !ok {
+		log.Debug("No charge point route for %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	cpID := route.ChargePointID
+
+	certID := certificateIdentity(r.TLS)
+	if certID != "" {
+		// with mTLS the certificate tells who the charge point is
+		if cpID != "" && cpID != certID {
+			log.Error("Rejected charge point %s: its certificate is the one of %s", cpID, certID)
+			unauthorized(w)
+			return
+		}
+		cpID = certID
+	}
+
+	if csys.authenticator != nil {
+		if err := csys.authenticator.Authenticate(r, cpID, r.TLS); err != nil {
+			log.Error("Rejected charge point %s: %w", cpID, err)
+			unauthorized(w)
+			return
+		}
+	}
+
 	conn, err := ws.Handshake(w, r, []ocpp.Version{ocpp.V16})
 	if err != nil {
 		log.Error("Couldn't handshake request %w", err)
 		return
 	}
+	log.Debug("Negotiated OCPP %s with %s", conn.Version(), cpID)
+	conn.SetRequestTimeout(csys.requestTimeout)
+	conn.SetQueueOptions(csys.queueOptions)
+	conn.SetSchemaValidation(csys.schemaValidation)
 
 	csys.connMux.Lock()
+	log.Debug("Current WS connections map: %v", csys.conns)
 	csys.conns[cpID] = conn
 	csys.connsCount[cpID]++
+	cleanedUp := make(chan struct{})
+	csys.connsCleanedUp[conn] = cleanedUp
 	if csys.connChans[cpID] == nil {
 		csys.connChans[cpID] = make(chan struct{})
 	}
@@ -115,7 +383,22 @@ func (csys *centralSystem) handleWebsocket(w http.ResponseWriter, r *http.Reques
 		close(csys.connChans[cpID])
 	}
 	csys.connsConnected[cpID] = true
+	// Shutdown may have started since this request was accepted,
+	// in which case it didn't see the connection to close it
+	shuttingDown := csys.shuttingDown
 	csys.connMux.Unlock()
+	if shuttingDown {
+		conn.CloseWithReason(websocket.CloseGoingAway, "central system shutting down")
+	}
+
+	metadata := ChargePointRequestMetadata{
+		ChargePointID:       cpID,
+		HTTPRequest:         r,
+		Version:             conn.Version(),
+		CertificateIdentity: certID,
+		Tenant:              route.Tenant,
+	}
+	csys.storeConnection(cpID, metadata)
 
 	log.Debug("Connected with %s", cpID)
 	go csys.connListener(cpID)
@@ -123,17 +406,28 @@ func (csys *centralSystem) handleWebsocket(w http.ResponseWriter, r *http.Reques
 	defer func() {
 		conn.Close()
 		log.Debug("Closed connection of: %s", cpID)
-		go csys.disconnListener(cpID)
 		csys.connMux.Lock()
+		if csys.shuttingDown && !csys.shutdownNotified {
+			// notified by Shutdown, once every handler is done
+			csys.shutdownDisconnected = append(csys.shutdownDisconnected, cpID)
+		} else {
+			go csys.disconnListener(cpID)
+		}
 		csys.connsCount[cpID]--
 		// if the same CP connected more times before we do the
 		// connection cleanup, don't remove the connection reference
-		if csys.connsCount[cpID] == 0 {
+		disconnected := csys.connsCount[cpID] == 0
+		if disconnected {
 			delete(csys.conns, cpID)
 			csys.connChans[cpID] = make(chan struct{})
 			csys.connsConnected[cpID] = false
 		}
+		delete(csys.connsCleanedUp, conn)
 		csys.connMux.Unlock()
+		if disconnected {
+			csys.storeConnection(cpID, metadata)
+		}
+		close(cleanedUp)
 	}()
 
 	for {
@@ -144,14 +438,15 @@ func (csys *centralSystem) handleWebsocket(w http.ResponseWriter, r *http.Reques
 				log.Error(cpreq.ErrorNotChargePointRequest.Error())
 				continue
 			}
-			cpresponse, err := cphandler(cprequest, ChargePointRequestMetadata{
-				ChargePointID: cpID,
-				HTTPRequest:   r,
-			})
+			reqMetadata := metadata
+			reqMetadata.afterResponse = &responseHooks{}
+			cpresponse, err := csys.handle(cphandler, cprequest, reqMetadata, ocpp.JSON)
+			csys.storeRequest(cprequest, cpresponse, reqMetadata, ocpp.JSON, false)
 			err = conn.SendResponse(req.MessageID, cpresponse, err)
 			if err != nil {
 				log.Error(err.Error())
 			}
+			reqMetadata.afterResponse.run()
 		case <-conn.WaitClose():
 			return
 		case err := <-conn.ReadMessageAsync():
@@ -167,39 +462,67 @@ func (csys *centralSystem) handleWebsocket(w http.ResponseWriter, r *http.Reques
 
 func (csys *centralSystem) handleSoap(w http.ResponseWriter, r *http.Request, cphandler ChargePointMessageHandler) {
 	log.Debug("New SOAP request")
-	err := soap.Handle(w, r, func(request messages.Request, cpID string) (messages.Response, error) {
+	afterResponse := &responseHooks{}
+	err := soap.HandleEnvelope(w, r, func(request messages.Request, header soap.Header) (messages.Response, error) {
+		cpID := header.ChargeBoxIdentity
+		certID := certificateIdentity(r.TLS)
+		if certID != "" && cpID != certID {
+			return nil, fmt.Errorf("charge box identity %s isn't the one of its certificate", cpID)
+		}
+		if csys.authenticator != nil {
+			if err := csys.authenticator.Authenticate(r, cpID, r.TLS); err != nil {
+				log.Error("Rejected charge point %s: %w", cpID, err)
+				return nil, ErrUnauthorized
+			}
+		}
 		req, ok := request.(cpreq.ChargePointRequest)
 		if !ok {
 			return nil, errors.New("request is not a cprequest")
 		}
-		return cphandler(req, ChargePointRequestMetadata{
-			ChargePointID: cpID,
-			HTTPRequest:   r,
-		})
+		metadata := ChargePointRequestMetadata{
+			ChargePointID:       cpID,
+			HTTPRequest:         r,
+			Version:             header.Version,
+			CertificateIdentity: certID,
+			afterResponse:       afterResponse,
+		}
+		cpresponse, err := csys.handle(cphandler, req, metadata, ocpp.SOAP)
+		learned := false
+		if err == nil {
+			// only an authenticated request which was handled tells where the charge point is
+			trusted := trustedEndpoint(header.From.Address, r, certID)
+			learned = csys.soapEndpoints.learn(cpID, header.From.Address, header.To, trusted)
+		}
+		csys.storeRequest(req, cpresponse, metadata, ocpp.SOAP, learned)
+		return cpresponse, err
 	})
 	if err != nil {
 		log.Error("Couldn't handle SOAP request: %w", err)
 	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	afterResponse.run()
 }
 
-func (csys *centralSystem) GetServiceOf(cpID string, version ocpp.Version, url string) (service.ChargePoint, error) {
-	if version == ocpp.V15 {
-		return service.NewChargePointSOAP(url, &soap.CallOptions{
-			ChargeBoxIdentity: cpID,
-			// TODO: insert IP address
-			// From: <url>,
-		}), nil
+func (csys *centralSystem) GetServiceOf(cpID string) (service.ChargePoint, error) {
+	csys.connMux.Lock()
+	conn := csys.conns[cpID]
+	csys.connMux.Unlock()
+	if conn != nil && !isClosed(conn) {
+		return csys.intercepted(service.NewChargePointJSON(conn), ocpp.JSON), nil
 	}
-	if version == ocpp.V16 {
-		csys.connMux.Lock()
-		conn := csys.conns[cpID]
-		csys.connMux.Unlock()
-		if conn == nil { // TODO: or conn closed
-			return nil, errors.New("no connection to this charge point")
-		}
-		return service.NewChargePointJSON(conn), nil
+	if endpoint, ok := csys.soapEndpoints.get(cpID); ok {
+		return csys.intercepted(service.NewChargePointSOAP(endpoint.url(), &soap.CallOptions{
+			ChargeBoxIdentity: cpID,
+			From:              soap.CallOptionsFrom{Address: endpoint.centralSystemURL},
+		}, csys.requestTimeout), ocpp.SOAP), nil
 	}
-	return nil, errors.New("charge point has no configured OCPP version(1.5/1.6)")
+	return nil, errors.New("no connection to this charge point")
+}
+
+func (csys *centralSystem) SetChargePointEndpoint(cpID string, url string) {
+	csys.soapEndpoints.override(cpID, url)
 }
 
 func (csys *centralSystem) SetChargePointConnectionListener(f ChargePointConnectionListener) {
@@ -210,17 +533,27 @@ func (csys *centralSystem) SetChargePointDisconnectionListener(f ChargePointConn
 	csys.disconnListener = f
 }
 
+// WaitDisconnect returns a channel which is closed once the
+// current connection of the charge point is closed and the
+// central system is done cleaning it up
 func (csys *centralSystem) WaitDisconnect(cpID string) <-chan struct{} {
 	csys.connMux.Lock()
-	conn := csys.conns[cpID]
-	csys.connMux.Unlock()
+	defer csys.connMux.Unlock()
+	cleanedUp := csys.connsCleanedUp[csys.conns[cpID]]
+	if cleanedUp == nil {
+		cleanedUp = make(chan struct{})
+		close(cleanedUp)
+	}
+	return cleanedUp
+}
 
-	ch := make(chan struct{})
-	if conn == nil {
-		close(ch)
-		return ch
+func isClosed(conn *ws.Conn) bool {
+	select {
+	case <-conn.WaitClose():
+		return true
+	default:
+		return false
 	}
-	return conn.WaitClose()
 }
 
 func (csys *centralSystem) WaitConnect(cpID string) <-chan struct{} {
@@ -0,0 +1,155 @@
+package cs
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Shutdown(t *testing.T) {
+	handling, release := make(chan struct{}), make(chan struct{})
+	csys := New()
+	csys.SetChargePointMessageHandler(func(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		close(handling)
+		<-release
+		return &cpresp.Heartbeat{}, nil
+	})
+	var disconnected []string
+	var disconnectedMux sync.Mutex
+	csys.SetChargePointDisconnectionListener(func(cpID string) {
+		disconnectedMux.Lock()
+		defer disconnectedMux.Unlock()
+		disconnected = append(disconnected, cpID)
+	})
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+
+	cpIDs := []string{"cp1", "cp2", "cp3"}
+	sockets := make([]*websocket.Conn, len(cpIDs))
+	for i, cpID := range cpIDs {
+		socket, _, err := dialer.Dial(url+"/"+cpID, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer socket.Close()
+		sockets[i] = socket
+		<-csys.WaitConnect(cpID)
+	}
+	assert.NoError(t, sockets[0].WriteMessage(websocket.TextMessage, []byte(`[2,"1","Heartbeat",{}]`)))
+	<-handling
+
+	shutdown := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdown <- csys.Shutdown(ctx)
+	}()
+
+	for _, socket := range sockets {
+		_, _, err := socket.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+	}
+	_, resp, err := dialer.Dial(url+"/cp4", nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	select {
+	case <-shutdown:
+		t.Fatal("shut down before the request was handled")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	assert.NoError(t, <-shutdown)
+
+	disconnectedMux.Lock()
+	defer disconnectedMux.Unlock()
+	sort.Strings(disconnected)
+	assert.Equal(t, cpIDs, disconnected)
+}
+
+func Test_ConnectDuringShutdown(t *testing.T) {
+	authenticating, release := make(chan struct{}), make(chan struct{})
+	csys := New(WithAuthenticator(AuthenticatorFunc(func(r *http.Request, cpID string, tlsState *tls.ConnectionState) error {
+		// the request was accepted, but the connection isn't there yet
+		close(authenticating)
+		<-release
+		return nil
+	}))).(*centralSystem)
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+
+	type dialed struct {
+		socket *websocket.Conn
+		err    error
+	}
+	dial := make(chan dialed)
+	go func() {
+		socket, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/cp1", nil)
+		dial <- dialed{socket, err}
+	}()
+	<-authenticating
+
+	shutdown := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdown <- csys.Shutdown(ctx)
+	}()
+	assert.Eventually(t, func() bool {
+		csys.connMux.Lock()
+		defer csys.connMux.Unlock()
+		return csys.shuttingDown
+	}, time.Second, time.Millisecond)
+	close(release)
+
+	d := <-dial
+	if assert.NoError(t, d.err) {
+		defer d.socket.Close()
+		d.socket.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err := d.socket.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+	}
+	assert.NoError(t, <-shutdown)
+}
+
+func Test_ShutdownDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	handling := make(chan struct{})
+	csys := New()
+	csys.SetChargePointMessageHandler(func(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		close(handling)
+		<-release
+		return &cpresp.Heartbeat{}, nil
+	})
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+	socket, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/cp1", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer socket.Close()
+	assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(`[2,"1","Heartbeat",{}]`)))
+	<-handling
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, csys.Shutdown(ctx))
+}
@@ -0,0 +1,115 @@
+package cs
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/michaelbironneau/go-ocpp/internal/log"
+)
+
+// anonymousAddress is the WS-Addressing From of a sender
+// which can't be reached back, so there's nothing to learn
+const anonymousAddress = "http://www.w3.org/2005/08/addressing/anonymous"
+
+// soapEndpoint is how the central system reaches a SOAP charge point
+type soapEndpoint struct {
+	// learned from the From header of the charge point requests
+	learned string
+	// set by hand, e.g. for a charge point behind a NAT,
+	// it wins over the learned one
+	override string
+	// centralSystemURL the charge point sends its requests to,
+	// used as the From header of the requests to the charge point
+	centralSystemURL string
+}
+
+func (e *soapEndpoint) url() string {
+	if e.override != "" {
+		return e.override
+	}
+	return e.learned
+}
+
+// soapEndpoints keeps track of where each SOAP charge point is
+type soapEndpoints struct {
+	mux       sync.Mutex
+	endpoints map[string]*soapEndpoint
+}
+
+func newSoapEndpoints() *soapEndpoints {
+	return &soapEndpoints{
+		endpoints: make(map[string]*soapEndpoint),
+	}
+}
+
+func (s *soapEndpoints) getOrCreate(cpID string) *soapEndpoint {
+	endpoint := s.endpoints[cpID]
+	if endpoint == nil {
+		endpoint = &soapEndpoint{}
+		s.endpoints[cpID] = endpoint
+	}
+	return endpoint
+}
+
+// learn the endpoint of the charge point from the addressing headers
+// of the request it sent, telling whether it changed.
+// An endpoint already learned is only replaced by a trusted one, e.g.
+// on the host the request came from, so a request can't send the
+// calls to the charge point to someone else.
+func (s *soapEndpoints) learn(cpID, from, to string, trusted bool) bool {
+	if cpID == "" {
+		return false
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	endpoint := s.getOrCreate(cpID)
+	learned := *endpoint
+	if from != "" && from != anonymousAddress {
+		if endpoint.learned != "" && endpoint.learned != from && !trusted {
+			log.Error("Not replacing the endpoint %s of %s with the untrusted %s", endpoint.learned, cpID, from)
+			return false
+		}
+		endpoint.learned = from
+	}
+	if to != "" {
+		endpoint.centralSystemURL = to
+	}
+	return *endpoint != learned
+}
+
+func (s *soapEndpoints) override(cpID, url string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.getOrCreate(cpID).override = url
+}
+
+// get returns a copy of the endpoint, if its URL is known
+func (s *soapEndpoints) get(cpID string) (soapEndpoint, bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	endpoint := s.endpoints[cpID]
+	if endpoint == nil || endpoint.url() == "" {
+		return soapEndpoint{}, false
+	}
+	return *endpoint, true
+}
+
+// trustedEndpoint tells whether the endpoint sent by a charge point can
+// replace the one learned before: the charge point has a verified
+// certificate, or the endpoint is on the host the request came from
+func trustedEndpoint(from string, r *http.Request, certID string) bool {
+	if certID != "" {
+		return true
+	}
+	endpoint, err := url.Parse(from)
+	if err != nil {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return endpoint.Hostname() == host
+}
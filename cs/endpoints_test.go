@@ -0,0 +1,95 @@
+package cs
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SoapEndpoints(t *testing.T) {
+	endpoints := newSoapEndpoints()
+
+	_, ok := endpoints.get("cp1")
+	assert.False(t, ok)
+
+	endpoints.learn("cp1", anonymousAddress, "http://cs:8080/", true)
+	_, ok = endpoints.get("cp1")
+	assert.False(t, ok, "anonymous senders can't be reached")
+
+	assert.True(t, endpoints.learn("cp1", "http://10.0.0.2:8080/", "http://cs:8080/", true))
+	assert.False(t, endpoints.learn("cp1", "http://10.0.0.2:8080/", "http://cs:8080/", true), "nothing changed")
+	endpoint, ok := endpoints.get("cp1")
+	assert.True(t, ok)
+	assert.Equal(t, "http://10.0.0.2:8080/", endpoint.url())
+	assert.Equal(t, "http://cs:8080/", endpoint.centralSystemURL)
+
+	endpoints.override("cp1", "http://203.0.113.7:8080/")
+	endpoints.learn("cp1", "http://10.0.0.3:8080/", "", true)
+	endpoint, _ = endpoints.get("cp1")
+	assert.Equal(t, "http://203.0.113.7:8080/", endpoint.url())
+	assert.Equal(t, "http://10.0.0.3:8080/", endpoint.learned)
+	assert.Equal(t, "http://cs:8080/", endpoint.centralSystemURL)
+
+	// a charge point can be set up before it sent anything
+	endpoints.override("cp2", "http://203.0.113.8:8080/")
+	endpoint, ok = endpoints.get("cp2")
+	assert.True(t, ok)
+	assert.Equal(t, "http://203.0.113.8:8080/", endpoint.url())
+
+	endpoints.learn("", "http://10.0.0.4:8080/", "", true)
+	assert.Len(t, endpoints.endpoints, 2)
+
+	// a learned endpoint is only replaced by a trusted one
+	assert.False(t, endpoints.learn("cp1", "http://198.51.100.1:8080/", "http://evil:8080/", false))
+	endpoint, _ = endpoints.get("cp1")
+	assert.Equal(t, "http://10.0.0.3:8080/", endpoint.learned)
+	assert.Equal(t, "http://cs:8080/", endpoint.centralSystemURL)
+	assert.True(t, endpoints.learn("cp3", "http://198.51.100.1:8080/", "", false), "nothing to replace")
+}
+
+func Test_TrustedEndpoint(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.RemoteAddr = "192.0.2.1:41234"
+	assert.True(t, trustedEndpoint("http://192.0.2.1:8080/", r, ""))
+	assert.False(t, trustedEndpoint("http://198.51.100.1:8080/", r, ""))
+	assert.False(t, trustedEndpoint("http://cp.example.com:8080/", r, ""))
+	assert.True(t, trustedEndpoint("http://198.51.100.1:8080/", r, "cp1"), "its certificate was verified")
+}
+
+func Test_SoapEndpointHijack(t *testing.T) {
+	csys := New().(*centralSystem)
+	var fail bool
+	handler := func(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		if fail {
+			return nil, errors.New("failed")
+		}
+		return &cpresp.Heartbeat{CurrentTime: time.Now()}, nil
+	}
+	send := func(cpID, from, remoteAddr string) {
+		r := soapRequest(cpID, from)
+		r.RemoteAddr = remoteAddr
+		csys.handleSoap(httptest.NewRecorder(), r, handler)
+	}
+
+	fail = true
+	send("cp1", "http://192.0.2.1:8080/", "192.0.2.1:41234")
+	_, ok := csys.soapEndpoints.get("cp1")
+	assert.False(t, ok, "a request which failed tells nothing")
+
+	fail = false
+	send("cp1", "http://192.0.2.1:8080/", "192.0.2.1:41234")
+	send("cp1", "http://198.51.100.1:8080/", "198.51.100.2:41234")
+	endpoint, _ := csys.soapEndpoints.get("cp1")
+	assert.Equal(t, "http://192.0.2.1:8080/", endpoint.url())
+
+	// the charge point itself can move
+	send("cp1", "http://192.0.2.2:8080/", "192.0.2.2:41234")
+	endpoint, _ = csys.soapEndpoints.get("cp1")
+	assert.Equal(t, "http://192.0.2.2:8080/", endpoint.url())
+}
@@ -0,0 +1,41 @@
+package cs
+
+import "sync"
+
+// responseHooks run once the response to a request was sent
+type responseHooks struct {
+	mux   sync.Mutex
+	hooks []func()
+	sent  bool
+}
+
+// AfterResponse runs f in its own goroutine once the response to the
+// request was sent to the charge point, e.g. to send it requests which
+// must follow its BootNotification being accepted. f runs right away when
+// the response was already sent, or the metadata doesn't come from the
+// central system.
+func (metadata ChargePointRequestMetadata) AfterResponse(f func()) {
+	hooks := metadata.afterResponse
+	if hooks == nil {
+		go f()
+		return
+	}
+	hooks.mux.Lock()
+	defer hooks.mux.Unlock()
+	if hooks.sent {
+		go f()
+		return
+	}
+	hooks.hooks = append(hooks.hooks, f)
+}
+
+// run the hooks, the response being sent
+func (hooks *responseHooks) run() {
+	hooks.mux.Lock()
+	defer hooks.mux.Unlock()
+	hooks.sent = true
+	for _, f := range hooks.hooks {
+		go f()
+	}
+	hooks.hooks = nil
+}
@@ -0,0 +1,63 @@
+package cs
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AfterResponse(t *testing.T) {
+	csys := New()
+	csys.SetChargePointMessageHandler(func(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		metadata.AfterResponse(func() {
+			svc, err := csys.GetServiceOf(metadata.ChargePointID)
+			if err != nil {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			svc.SendContext(ctx, metadata.ChargePointID, &csreq.GetConfiguration{})
+		})
+		return &cpresp.Heartbeat{CurrentTime: time.Now()}, nil
+	})
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+	socket, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/cp1", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer socket.Close()
+	assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(`[2,"1","Heartbeat",{}]`)))
+
+	// the response comes first, then the request of the hook
+	socket.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var frames []string
+	for i := 0; i < 2; i++ {
+		_, raw, err := socket.ReadMessage()
+		if !assert.NoError(t, err) {
+			return
+		}
+		frames = append(frames, string(raw))
+	}
+	assert.True(t, strings.HasPrefix(frames[0], `[3,"1",`), "unexpected frame: %s", frames[0])
+	assert.Contains(t, frames[1], `"GetConfiguration"`)
+
+	t.Run("outside of the central system", func(t *testing.T) {
+		ran := make(chan struct{})
+		ChargePointRequestMetadata{ChargePointID: "cp1"}.AfterResponse(func() { close(ran) })
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Fatal("the hook didn't run")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package cs
+
+import (
+	"context"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/interceptor"
+	"github.com/michaelbironneau/go-ocpp/internal/service"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+)
+
+// WithInterceptors wraps the requests of the charge points and the ones
+// sent to them, whatever the transport, the first interceptor being the
+// outermost, e.g. interceptor.Recovery()
+func WithInterceptors(interceptors ...interceptor.Interceptor) Option {
+	return func(csys *centralSystem) {
+		csys.interceptor = interceptor.Chain(interceptors...)
+	}
+}
+
+// handle the request of the charge point through the interceptors
+func (csys *centralSystem) handle(cphandler ChargePointMessageHandler, cprequest cpreq.ChargePointRequest, metadata ChargePointRequestMetadata, transport ocpp.Transport) (cpresp.ChargePointResponse, error) {
+	if csys.interceptor == nil {
+		return cphandler(cprequest, metadata)
+	}
+	info := &interceptor.Info{
+		ChargePointID: metadata.ChargePointID,
+		Action:        cprequest.Action(),
+		Direction:     interceptor.Inbound,
+		Transport:     transport,
+	}
+	resp, err := csys.interceptor(metadata.HTTPRequest.Context(), cprequest, info, func(ctx context.Context, req messages.Request) (messages.Response, error) {
+		return cphandler(req.(cpreq.ChargePointRequest), metadata)
+	})
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	cpresponse, ok := resp.(cpresp.ChargePointResponse)
+	if !ok {
+		return nil, cpresp.ErrorNotChargePointResponse
+	}
+	return cpresponse, nil
+}
+
+// interceptedChargePoint sends the requests
+// to the charge point through the interceptors
+type interceptedChargePoint struct {
+	service.ChargePoint
+	interceptor interceptor.Interceptor
+	transport   ocpp.Transport
+	// timeout of Send
+	timeout time.Duration
+}
+
+func (s *interceptedChargePoint) Send(cpID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.SendContext(ctx, cpID, req)
+}
+
+func (s *interceptedChargePoint) SendContext(ctx context.Context, cpID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	return s.send(ctx, cpID, req, func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		return s.ChargePoint.SendContext(ctx, cpID, req)
+	})
+}
+
+func (s *interceptedChargePoint) send(ctx context.Context, cpID string, req csreq.CentralSystemRequest, send func(ctx context.Context, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error)) (csresp.CentralSystemResponse, error) {
+	info := &interceptor.Info{
+		ChargePointID: cpID,
+		Action:        req.Action(),
+		Direction:     interceptor.Outbound,
+		Transport:     s.transport,
+	}
+	resp, err := s.interceptor(ctx, req, info, func(ctx context.Context, req messages.Request) (messages.Response, error) {
+		return send(ctx, req.(csreq.CentralSystemRequest))
+	})
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	csresponse, ok := resp.(csresp.CentralSystemResponse)
+	if !ok {
+		return nil, csresp.ErrorNotCentralSystemResponse
+	}
+	return csresponse, nil
+}
+
+// intercepted wraps the service of the charge point in the interceptors, if any
+func (csys *centralSystem) intercepted(svc service.ChargePoint, transport ocpp.Transport) service.ChargePoint {
+	if csys.interceptor == nil {
+		return svc
+	}
+	return &interceptedChargePoint{svc, csys.interceptor, transport, csys.requestTimeout}
+}
@@ -0,0 +1,120 @@
+package cs
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/interceptor"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Interceptors(t *testing.T) {
+	var infos []interceptor.Info
+	var infosMux sync.Mutex
+	record := func(ctx context.Context, req messages.Request, info *interceptor.Info, next interceptor.Handler) (messages.Response, error) {
+		infosMux.Lock()
+		infos = append(infos, *info)
+		infosMux.Unlock()
+		return next(ctx, req)
+	}
+	csys := New(WithInterceptors(interceptor.Recovery(), record))
+	csys.SetChargePointMessageHandler(func(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		if _, ok := req.(*cpreq.StatusNotification); ok {
+			panic("boom")
+		}
+		return &cpresp.Heartbeat{}, nil
+	})
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+	socket, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/cp1", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer socket.Close()
+	<-csys.WaitConnect("cp1")
+
+	t.Run("inbound", func(t *testing.T) {
+		assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(`[2,"1","Heartbeat",{}]`)))
+		_, msg, err := socket.ReadMessage()
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(msg), `[3,"1",`), string(msg))
+	})
+
+	t.Run("inbound panic", func(t *testing.T) {
+		assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(`[2,"2","StatusNotification",{"connectorId":1,"errorCode":"NoError","status":"Available"}]`)))
+		_, msg, err := socket.ReadMessage()
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(msg), `[4,"2","InternalError",`), string(msg))
+	})
+
+	t.Run("outbound", func(t *testing.T) {
+		svc, err := csys.GetServiceOf("cp1")
+		if !assert.NoError(t, err) {
+			return
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			var call struct {
+				OCPP []interface{} `json:"ocpp"`
+			}
+			if assert.NoError(t, socket.ReadJSON(&call)) {
+				socket.WriteMessage(websocket.TextMessage, []byte(`[3,"`+call.OCPP[1].(string)+`",{"status":"Accepted"}]`))
+			}
+		}()
+		resp, err := svc.Send("cp1", &csreq.Reset{Type: "Soft"})
+		assert.NoError(t, err)
+		assert.Equal(t, &csresp.Reset{Status: "Accepted"}, resp)
+		<-done
+	})
+
+	infosMux.Lock()
+	defer infosMux.Unlock()
+	assert.Equal(t, []interceptor.Info{
+		{ChargePointID: "cp1", Action: "Heartbeat", Direction: interceptor.Inbound, Transport: ocpp.JSON},
+		{ChargePointID: "cp1", Action: "StatusNotification", Direction: interceptor.Inbound, Transport: ocpp.JSON},
+		{ChargePointID: "cp1", Action: "Reset", Direction: interceptor.Outbound, Transport: ocpp.JSON},
+	}, infos)
+}
+
+func Test_InterceptorContext(t *testing.T) {
+	// the interceptor gives the charge point a second to answer
+	deadline := func(ctx context.Context, req messages.Request, info *interceptor.Info, next interceptor.Handler) (messages.Response, error) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		return next(ctx, req)
+	}
+	csys := New(WithInterceptors(deadline))
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+	socket, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/cp1", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer socket.Close()
+	<-csys.WaitConnect("cp1")
+	svc, err := csys.GetServiceOf("cp1")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the charge point never answers
+	started := time.Now()
+	_, err = svc.Send("cp1", &csreq.Reset{Type: "Soft"})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	assert.True(t, time.Since(started) < 10*time.Second)
+}
@@ -0,0 +1,58 @@
+package cs
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ChargePointRoute is what the URL of a websocket
+// connection tells about the charge point
+type ChargePointRoute struct {
+	ChargePointID string
+	// Tenant the charge point belongs to, if the URL tells
+	Tenant string
+}
+
+// RouteExtractor gets the route of the charge point from the request,
+// the connection is refused with a 404 if it returns false
+type RouteExtractor func(r *http.Request) (ChargePointRoute, bool)
+
+// defaultRoute takes the whole path as the charge point ID
+func defaultRoute(r *http.Request) (ChargePointRoute, bool) {
+	return ChargePointRoute{ChargePointID: strings.TrimPrefix(r.URL.Path, "/")}, true
+}
+
+// PathPattern routes the requests whose path matches the pattern,
+// segment by segment. The {id} segment is the charge point ID, the
+// {tenant} one its tenant, and any other segment must match as is, e.g.:
+//
+// cs.PathPattern("/ocpp/v16/{id}")
+// cs.PathPattern("/{tenant}/ocpp/{id}")
+//
+// The query string is ignored, as well as a trailing slash.
+func PathPattern(pattern string) RouteExtractor {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	return func(r *http.Request) (ChargePointRoute, bool) {
+		segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(segments) != len(patternSegments) {
+			return ChargePointRoute{}, false
+		}
+		var route ChargePointRoute
+		for i, segment := range patternSegments {
+			switch segment {
+			case "{id}":
+				route.ChargePointID = segments[i]
+			case "{tenant}":
+				route.Tenant = segments[i]
+			default:
+				if segments[i] != segment {
+					return ChargePointRoute{}, false
+				}
+			}
+		}
+		if route.ChargePointID == "" {
+			return ChargePointRoute{}, false
+		}
+		return route, true
+	}
+}
@@ -0,0 +1,73 @@
+package cs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PathPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		url     string
+		route   ChargePointRoute
+		ok      bool
+	}{
+		{"/ocpp/v16/{id}", "/ocpp/v16/CP1", ChargePointRoute{ChargePointID: "CP1"}, true},
+		{"/ocpp/v16/{id}", "/ocpp/v16/CP1?vendor=acme", ChargePointRoute{ChargePointID: "CP1"}, true},
+		{"/ocpp/v16/{id}", "/ocpp/v16/CP1/", ChargePointRoute{ChargePointID: "CP1"}, true},
+		{"/ocpp/v16/{id}", "/ocpp/v15/CP1", ChargePointRoute{}, false},
+		{"/ocpp/v16/{id}", "/ocpp/v16", ChargePointRoute{}, false},
+		{"/ocpp/v16/{id}", "/ocpp/v16/CP1/extra", ChargePointRoute{}, false},
+		{"/ocpp/v16/{id}", "/ocpp/v16//", ChargePointRoute{}, false},
+		{"/{tenant}/ocpp/{id}", "/acme/ocpp/CP1", ChargePointRoute{ChargePointID: "CP1", Tenant: "acme"}, true},
+		{"/{tenant}/ocpp/{id}", "/acme/CP1", ChargePointRoute{}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.pattern+" "+c.url, func(t *testing.T) {
+			route, ok := PathPattern(c.pattern)(httptest.NewRequest(http.MethodGet, c.url, nil))
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.route, route)
+		})
+	}
+}
+
+func Test_RouteExtractor(t *testing.T) {
+	metadatas := make(chan ChargePointRequestMetadata, 1)
+	csys := New(WithRouteExtractor(PathPattern("/{tenant}/ocpp/v16/{id}")))
+	csys.SetChargePointMessageHandler(func(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		metadatas <- metadata
+		return &cpresp.Heartbeat{}, nil
+	})
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+
+	t.Run("not found", func(t *testing.T) {
+		_, resp, err := dialer.Dial(url+"/CP1", nil)
+		assert.Error(t, err)
+		if assert.NotNil(t, resp) {
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		}
+	})
+
+	t.Run("routed", func(t *testing.T) {
+		socket, _, err := dialer.Dial(url+"/acme/ocpp/v16/CP1?token=abc", nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer socket.Close()
+		assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(`[2,"1","Heartbeat",{}]`)))
+		metadata := <-metadatas
+		assert.Equal(t, "CP1", metadata.ChargePointID)
+		assert.Equal(t, "acme", metadata.Tenant)
+		<-csys.WaitConnect("CP1")
+	})
+}
@@ -0,0 +1,142 @@
+package cs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/ws"
+)
+
+// ErrNoResponse is returned to the charge point when
+// a handler returns neither a response nor an error
+var ErrNoResponse = fmt.Errorf("%w: handler gave no response", ws.InternalError)
+
+type actionHandler func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error)
+
+// Router dispatches each charge point request to the handler
+// registered for its action, the others are answered with
+// a NotSupported error. Its Handle method is the handler
+// to give to Run:
+//
+//	router := cs.NewRouter()
+//	router.OnHeartbeat(func(ctx context.Context, req *cpreq.Heartbeat, metadata cs.ChargePointRequestMetadata) (*cpresp.Heartbeat, error) {
+//		return &cpresp.Heartbeat{CurrentTime: time.Now()}, nil
+//	})
+//	csys.Run(":12811", router.Handle)
+type Router struct {
+	mux      sync.RWMutex
+	handlers map[string]actionHandler
+}
+
+func NewRouter() *Router {
+	return &Router{
+		handlers: make(map[string]actionHandler),
+	}
+}
+
+// Handle the request with the handler of its action. The context
+// is the one of the HTTP request, so on websockets it's
+// done once the charge point is disconnected.
+func (router *Router) Handle(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+	router.mux.RLock()
+	handler, ok := router.handlers[req.Action()]
+	router.mux.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: no handler of %s", ws.NotSupported, req.Action())
+	}
+	ctx := context.Background()
+	if metadata.HTTPRequest != nil {
+		ctx = metadata.HTTPRequest.Context()
+	}
+	return handler(ctx, req, metadata)
+}
+
+func (router *Router) on(action string, handler actionHandler) {
+	router.mux.Lock()
+	defer router.mux.Unlock()
+	router.handlers[action] = handler
+}
+
+// response makes sure a nil response isn't sent as a typed nil
+func response(resp cpresp.ChargePointResponse, isNil bool, err error) (cpresp.ChargePointResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	if isNil {
+		return nil, ErrNoResponse
+	}
+	return resp, nil
+}
+
+func (router *Router) OnAuthorize(handler func(ctx context.Context, req *cpreq.Authorize, metadata ChargePointRequestMetadata) (*cpresp.Authorize, error)) {
+	router.on("Authorize", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.Authorize), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnBootNotification(handler func(ctx context.Context, req *cpreq.BootNotification, metadata ChargePointRequestMetadata) (*cpresp.BootNotification, error)) {
+	router.on("BootNotification", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.BootNotification), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnDataTransfer(handler func(ctx context.Context, req *cpreq.DataTransfer, metadata ChargePointRequestMetadata) (*cpresp.DataTransfer, error)) {
+	router.on("DataTransfer", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.DataTransfer), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnDiagnosticsStatusNotification(handler func(ctx context.Context, req *cpreq.DiagnosticsStatusNotification, metadata ChargePointRequestMetadata) (*cpresp.DiagnosticsStatusNotification, error)) {
+	router.on("DiagnosticsStatusNotification", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.DiagnosticsStatusNotification), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnFirmwareStatusNotification(handler func(ctx context.Context, req *cpreq.FirmwareStatusNotification, metadata ChargePointRequestMetadata) (*cpresp.FirmwareStatusNotification, error)) {
+	router.on("FirmwareStatusNotification", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.FirmwareStatusNotification), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnHeartbeat(handler func(ctx context.Context, req *cpreq.Heartbeat, metadata ChargePointRequestMetadata) (*cpresp.Heartbeat, error)) {
+	router.on("Heartbeat", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.Heartbeat), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnMeterValues(handler func(ctx context.Context, req *cpreq.MeterValues, metadata ChargePointRequestMetadata) (*cpresp.MeterValues, error)) {
+	router.on("MeterValues", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.MeterValues), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnStartTransaction(handler func(ctx context.Context, req *cpreq.StartTransaction, metadata ChargePointRequestMetadata) (*cpresp.StartTransaction, error)) {
+	router.on("StartTransaction", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.StartTransaction), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnStatusNotification(handler func(ctx context.Context, req *cpreq.StatusNotification, metadata ChargePointRequestMetadata) (*cpresp.StatusNotification, error)) {
+	router.on("StatusNotification", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.StatusNotification), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
+
+func (router *Router) OnStopTransaction(handler func(ctx context.Context, req *cpreq.StopTransaction, metadata ChargePointRequestMetadata) (*cpresp.StopTransaction, error)) {
+	router.on("StopTransaction", func(ctx context.Context, req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		resp, err := handler(ctx, req.(*cpreq.StopTransaction), metadata)
+		return response(resp, resp == nil, err)
+	})
+}
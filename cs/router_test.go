@@ -0,0 +1,86 @@
+package cs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/ws"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Router(t *testing.T) {
+	router := NewRouter()
+	router.OnBootNotification(func(ctx context.Context, req *cpreq.BootNotification, metadata ChargePointRequestMetadata) (*cpresp.BootNotification, error) {
+		assert.Equal(t, "CP1", metadata.ChargePointID)
+		assert.NoError(t, ctx.Err())
+		return &cpresp.BootNotification{Status: "Accepted", Interval: 60}, nil
+	})
+	router.OnStatusNotification(func(ctx context.Context, req *cpreq.StatusNotification, metadata ChargePointRequestMetadata) (*cpresp.StatusNotification, error) {
+		return nil, fmt.Errorf("%w: no connector %d", ws.PropertyConstraintViolation, req.ConnectorId)
+	})
+	router.OnHeartbeat(func(ctx context.Context, req *cpreq.Heartbeat, metadata ChargePointRequestMetadata) (*cpresp.Heartbeat, error) {
+		return nil, nil
+	})
+
+	csys := New()
+	csys.SetChargePointMessageHandler(router.Handle)
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+	socket, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/CP1", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer socket.Close()
+
+	call := func(action string, payload string) []interface{} {
+		msg := fmt.Sprintf(`[2,"%s","%s",%s]`, action, action, payload)
+		assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(msg)))
+		_, raw, err := socket.ReadMessage()
+		assert.NoError(t, err)
+		var resp []interface{}
+		assert.NoError(t, json.Unmarshal(raw, &resp))
+		return resp
+	}
+	errorCode := func(resp []interface{}) interface{} {
+		if assert.Len(t, resp, 5) {
+			return resp[2]
+		}
+		return nil
+	}
+
+	cases := []struct {
+		name    string
+		action  string
+		payload string
+		code    ws.ErrorCode
+	}{
+		{"unregistered action", "Authorize", `{"idTag":"ABC"}`, ws.NotSupported},
+		{"unknown action", "Foo", `{}`, ws.NotImplemented},
+		{"error code from the handler", "StatusNotification", `{"connectorId":7,"errorCode":"NoError","status":"Available"}`, ws.PropertyConstraintViolation},
+		{"no response", "Heartbeat", `{}`, ws.InternalError},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp := call(c.action, c.payload)
+			assert.Equal(t, float64(ws.CallError), resp[0])
+			assert.Equal(t, string(c.code), errorCode(resp))
+		})
+	}
+
+	t.Run("registered action", func(t *testing.T) {
+		resp := call("BootNotification", `{"chargePointVendor":"acme","chargePointModel":"one"}`)
+		assert.Equal(t, float64(ws.CallResult), resp[0])
+		if assert.Len(t, resp, 3) {
+			payload := resp[2].(map[string]interface{})
+			assert.Equal(t, "Accepted", payload["status"])
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package cs
+
+import (
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/internal/log"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+)
+
+// ChargePointInfo is what the central system knows of a charge point
+type ChargePointInfo struct {
+	ID        string         `json:"id"`
+	Tenant    string         `json:"tenant,omitempty"`
+	Transport ocpp.Transport `json:"transport"`
+	Version   ocpp.Version   `json:"version"`
+	// Endpoint of a SOAP charge point, learned from its requests,
+	// and the URL of the central system they're sent to
+	Endpoint         string `json:"endpoint,omitempty"`
+	CentralSystemURL string `json:"centralSystemUrl,omitempty"`
+	// Connected tells whether a websocket charge point is connected,
+	// a restarted central system not knowing until it reconnects
+	Connected bool `json:"connected"`
+	// LastSeen is when the charge point last connected or sent a request,
+	// stored every 5 minutes at most when nothing else changed
+	LastSeen time.Time `json:"lastSeen"`
+	Boot     *BootInfo `json:"boot,omitempty"`
+	// Connectors are the last status of each connector,
+	// the connector 0 being the charge point itself
+	Connectors map[int]*ConnectorStatus `json:"connectors,omitempty"`
+}
+
+// BootInfo is the last BootNotification of a charge point
+type BootInfo struct {
+	Notification *cpreq.BootNotification `json:"notification"`
+	// Status the central system answered with
+	Status enums.RegistrationStatus `json:"status"`
+	Time   time.Time                `json:"time"`
+}
+
+// ConnectorStatus is the last StatusNotification of a connector
+type ConnectorStatus struct {
+	Notification *cpreq.StatusNotification `json:"notification"`
+	// Time it was received
+	Time time.Time `json:"time"`
+}
+
+// Store keeps what the central system learns of the charge points,
+// so it still knows the status of its fleet once it restarts
+type Store interface {
+	SaveChargePoint(info *ChargePointInfo) error
+	// ChargePoint returns nil when the charge point is unknown
+	ChargePoint(cpID string) (*ChargePointInfo, error)
+	ChargePoints() ([]*ChargePointInfo, error)
+}
+
+// WithStore keeps the charge points, their connection, boot
+// notification and connector status in the store. Once restarted,
+// the central system reaches the SOAP ones at their stored endpoint.
+func WithStore(store Store) Option {
+	return func(csys *centralSystem) {
+		csys.store = store
+	}
+}
+
+// restore what's stored of the charge points, none
+// being connected as the central system just started
+func (csys *centralSystem) restore() {
+	infos, err := csys.store.ChargePoints()
+	if err != nil {
+		log.Error("Couldn't restore the charge points: %w", err)
+		return
+	}
+	for _, info := range infos {
+		if info.Transport == ocpp.SOAP && info.Endpoint != "" {
+			csys.soapEndpoints.learn(info.ID, info.Endpoint, info.CentralSystemURL, true)
+		}
+		if info.Connected {
+			info.Connected = false
+			if err := csys.store.SaveChargePoint(info); err != nil {
+				log.Error("Couldn't save charge point %s: %w", info.ID, err)
+			}
+		}
+	}
+}
+
+// lastSeenInterval is how often the LastSeen of a charge point is stored
+// at most, when its requests don't change anything else that's stored
+const lastSeenInterval = 5 * time.Minute
+
+// updateChargePoint stores what update changes
+// of the charge point, if there's a store
+func (csys *centralSystem) updateChargePoint(cpID string, update func(info *ChargePointInfo)) {
+	if csys.store == nil || cpID == "" {
+		return
+	}
+	csys.storeMux.Lock()
+	defer csys.storeMux.Unlock()
+	info, err := csys.store.ChargePoint(cpID)
+	if err != nil {
+		log.Error("Couldn't get charge point %s: %w", cpID, err)
+		return
+	}
+	if info == nil {
+		info = &ChargePointInfo{ID: cpID}
+	}
+	update(info)
+	if err := csys.store.SaveChargePoint(info); err != nil {
+		log.Error("Couldn't save charge point %s: %w", cpID, err)
+		return
+	}
+	csys.lastStoredMux.Lock()
+	if csys.lastStored == nil {
+		csys.lastStored = make(map[string]time.Time)
+	}
+	csys.lastStored[cpID] = info.LastSeen
+	csys.lastStoredMux.Unlock()
+}
+
+// lastSeenDue tells whether the LastSeen of the charge point
+// was stored more than lastSeenInterval ago
+func (csys *centralSystem) lastSeenDue(cpID string, now time.Time) bool {
+	csys.lastStoredMux.Lock()
+	defer csys.lastStoredMux.Unlock()
+	return now.Sub(csys.lastStored[cpID]) >= lastSeenInterval
+}
+
+// storeConnection stores whether the charge point is connected,
+// as it is when it's stored, it may have reconnected since
+func (csys *centralSystem) storeConnection(cpID string, metadata ChargePointRequestMetadata) {
+	csys.updateChargePoint(cpID, func(info *ChargePointInfo) {
+		csys.connMux.Lock()
+		info.Connected = csys.connsConnected[cpID]
+		csys.connMux.Unlock()
+		info.Transport = ocpp.JSON
+		if metadata.Version != "" {
+			info.Version = metadata.Version
+		}
+		if metadata.Tenant != "" {
+			info.Tenant = metadata.Tenant
+		}
+		info.LastSeen = time.Now()
+	})
+}
+
+// storeRequest stores what the request of the charge point tells of it:
+// its boot, the status of a connector or the SOAP endpoint it was learned
+// from, when it changed. The other requests only store when it was last
+// seen, every lastSeenInterval, so they're not each written to the store.
+func (csys *centralSystem) storeRequest(req cpreq.ChargePointRequest, resp cpresp.ChargePointResponse, metadata ChargePointRequestMetadata, transport ocpp.Transport, endpointChanged bool) {
+	if csys.store == nil || metadata.ChargePointID == "" {
+		return
+	}
+	now := time.Now()
+	switch req.(type) {
+	case *cpreq.BootNotification, *cpreq.StatusNotification:
+	default:
+		if !endpointChanged && !csys.lastSeenDue(metadata.ChargePointID, now) {
+			return
+		}
+	}
+	csys.updateChargePoint(metadata.ChargePointID, func(info *ChargePointInfo) {
+		info.LastSeen = now
+		info.Transport = transport
+		info.Version = metadata.Version
+		if transport == ocpp.SOAP {
+			if endpoint, ok := csys.soapEndpoints.get(metadata.ChargePointID); ok {
+				info.Endpoint, info.CentralSystemURL = endpoint.learned, endpoint.centralSystemURL
+			}
+		}
+		switch req := req.(type) {
+		case *cpreq.BootNotification:
+			// the boot is only known once it's answered
+			if resp, ok := resp.(*cpresp.BootNotification); ok && resp != nil {
+				info.Boot = &BootInfo{Notification: req, Status: resp.Status, Time: now}
+			}
+		case *cpreq.StatusNotification:
+			if info.Connectors == nil {
+				info.Connectors = make(map[int]*ConnectorStatus)
+			}
+			info.Connectors[req.ConnectorId] = &ConnectorStatus{Notification: req, Time: now}
+		}
+	})
+}
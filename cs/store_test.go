@@ -0,0 +1,150 @@
+package cs
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	mux          sync.Mutex
+	chargePoints map[string]ChargePointInfo
+	saves        int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{chargePoints: make(map[string]ChargePointInfo)}
+}
+
+func (s *fakeStore) SaveChargePoint(info *ChargePointInfo) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.chargePoints[info.ID] = *info
+	s.saves++
+	return nil
+}
+
+func (s *fakeStore) ChargePoint(cpID string) (*ChargePointInfo, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	info, ok := s.chargePoints[cpID]
+	if !ok {
+		return nil, nil
+	}
+	return &info, nil
+}
+
+func (s *fakeStore) ChargePoints() ([]*ChargePointInfo, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	var infos []*ChargePointInfo
+	for _, info := range s.chargePoints {
+		info := info
+		infos = append(infos, &info)
+	}
+	return infos, nil
+}
+
+func (s *fakeStore) get(cpID string) ChargePointInfo {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.chargePoints[cpID]
+}
+
+func (s *fakeStore) saved() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.saves
+}
+
+func Test_StoreWebsocketChargePoint(t *testing.T) {
+	store := newFakeStore()
+	csys := New(WithStore(store), WithRouteExtractor(PathPattern("/ocpp/{tenant}/{id}")))
+	csys.SetChargePointMessageHandler(func(req cpreq.ChargePointRequest, metadata ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		switch req.(type) {
+		case *cpreq.BootNotification:
+			return &cpresp.BootNotification{Status: enums.RegistrationStatusAccepted, CurrentTime: time.Now(), Interval: 60}, nil
+		case *cpreq.StatusNotification:
+			return &cpresp.StatusNotification{}, nil
+		}
+		return &cpresp.Heartbeat{CurrentTime: time.Now()}, nil
+	})
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+	socket, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ocpp/acme/cp1", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	<-csys.WaitConnect("cp1")
+	for _, message := range []string{
+		`[2,"1","BootNotification",{"chargePointVendor":"ACME","chargePointModel":"Model 1"}]`,
+		`[2,"2","StatusNotification",{"connectorId":1,"errorCode":"NoError","status":"Charging"}]`,
+		`[2,"3","StatusNotification",{"connectorId":2,"errorCode":"GroundFailure","status":"Faulted"}]`,
+	} {
+		assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(message)))
+		_, _, err := socket.ReadMessage()
+		assert.NoError(t, err)
+	}
+
+	info := store.get("cp1")
+	assert.True(t, info.Connected)
+	assert.Equal(t, "acme", info.Tenant)
+	assert.Equal(t, ocpp.JSON, info.Transport)
+	assert.Equal(t, ocpp.V16, info.Version)
+	assert.False(t, info.LastSeen.IsZero())
+	if assert.NotNil(t, info.Boot) {
+		assert.Equal(t, "ACME", info.Boot.Notification.ChargePointVendor)
+		assert.Equal(t, enums.RegistrationStatusAccepted, info.Boot.Status)
+	}
+	if assert.Len(t, info.Connectors, 2) {
+		assert.Equal(t, enums.ChargePointStatusCharging, info.Connectors[1].Notification.Status)
+		assert.Equal(t, enums.ChargePointStatusFaulted, info.Connectors[2].Notification.Status)
+	}
+
+	// the other requests don't change what's stored, but its LastSeen now and then
+	saved := store.saved()
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(`[2,"`+strconv.Itoa(4+i)+`","Heartbeat",{}]`)))
+		_, _, err := socket.ReadMessage()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, saved, store.saved())
+
+	disconnected := csys.WaitDisconnect("cp1")
+	socket.Close()
+	<-disconnected
+	info = store.get("cp1")
+	assert.False(t, info.Connected)
+	assert.NotNil(t, info.Boot)
+}
+
+func Test_StoreRestore(t *testing.T) {
+	store := newFakeStore()
+	store.SaveChargePoint(&ChargePointInfo{ID: "cp1", Transport: ocpp.JSON, Connected: true})
+	store.SaveChargePoint(&ChargePointInfo{
+		ID:               "cp2",
+		Transport:        ocpp.SOAP,
+		Endpoint:         "http://cp2.example.com/ocpp",
+		CentralSystemURL: "http://cs.example.com/ocpp",
+	})
+	csys := New(WithStore(store))
+
+	// the central system just started, cp1 is no longer connected
+	assert.False(t, store.get("cp1").Connected)
+	_, err := csys.GetServiceOf("cp1")
+	assert.Error(t, err)
+	// cp2 is reached where it was
+	_, err = csys.GetServiceOf("cp2")
+	assert.NoError(t, err)
+}
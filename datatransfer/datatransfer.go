@@ -0,0 +1,100 @@
+// Package datatransfer decodes the data of the vendor specific
+// DataTransfer messages, sent by both sides
+package datatransfer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+)
+
+var (
+	// ErrUnknownVendorId is returned by Decode when
+	// no decoder is registered for the vendor
+	ErrUnknownVendorId = errors.New("unknown vendor id")
+	// ErrUnknownMessageId is returned by Decode when the vendor
+	// is known but no decoder is registered for the message
+	ErrUnknownMessageId = errors.New("unknown message id")
+)
+
+// Decoder decodes the data of a DataTransfer into its typed value
+type Decoder func(data string) (interface{}, error)
+
+// JSON decodes the data as JSON into the value
+// given by newValue, which must be a pointer, e.g.
+//
+//	registry.Register("com.acme", "Tariff", datatransfer.JSON(func() interface{} { return &Tariff{} }))
+func JSON(newValue func() interface{}) Decoder {
+	return func(data string) (interface{}, error) {
+		value := newValue()
+		if err := json.Unmarshal([]byte(data), value); err != nil {
+			return nil, fmt.Errorf("decoding data: %w", err)
+		}
+		return value, nil
+	}
+}
+
+// Registry of the decoders, by vendorId and messageId
+type Registry struct {
+	mux      sync.RWMutex
+	decoders map[string]map[string]Decoder
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		decoders: make(map[string]map[string]Decoder),
+	}
+}
+
+// Register the decoder of the given message of the vendor. With an
+// empty messageId, it decodes the messages of the vendor which don't
+// have a decoder of their own, including the ones without a messageId.
+func (r *Registry) Register(vendorID, messageID string, decoder Decoder) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.decoders[vendorID] == nil {
+		r.decoders[vendorID] = make(map[string]Decoder)
+	}
+	r.decoders[vendorID][messageID] = decoder
+}
+
+// Decode the data with the decoder registered for the vendor and message,
+// ErrUnknownVendorId or ErrUnknownMessageId if there's none
+func (r *Registry) Decode(vendorID, messageID, data string) (interface{}, error) {
+	r.mux.RLock()
+	vendor, ok := r.decoders[vendorID]
+	var decoder Decoder
+	if ok {
+		decoder, ok = vendor[messageID]
+		if !ok {
+			decoder, ok = vendor[""]
+		}
+	}
+	r.mux.RUnlock()
+	if vendor == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownVendorId, vendorID)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s of %s", ErrUnknownMessageId, messageID, vendorID)
+	}
+	return decoder(data)
+}
+
+// Status of the DataTransfer response to a message which
+// Decode returned the error for, Rejected if it couldn't be
+// decoded, Accepted if it was
+func Status(err error) enums.DataTransferStatus {
+	switch {
+	case err == nil:
+		return enums.DataTransferStatusAccepted
+	case errors.Is(err, ErrUnknownVendorId):
+		return enums.DataTransferStatusUnknownVendorId
+	case errors.Is(err, ErrUnknownMessageId):
+		return enums.DataTransferStatusUnknownMessageId
+	default:
+		return enums.DataTransferStatusRejected
+	}
+}
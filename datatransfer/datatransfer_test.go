@@ -0,0 +1,45 @@
+package datatransfer
+
+import (
+	"testing"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+type tariff struct {
+	Price float64 `json:"price"`
+}
+
+type firmware struct {
+	Build string `json:"build"`
+}
+
+func Test_Registry(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("com.acme", "Tariff", JSON(func() interface{} { return &tariff{} }))
+	registry.Register("com.example", "", JSON(func() interface{} { return &firmware{} }))
+
+	tests := []struct {
+		name      string
+		vendorID  string
+		messageID string
+		data      string
+		value     interface{}
+		status    enums.DataTransferStatus
+	}{
+		{"registered message", "com.acme", "Tariff", `{"price":0.25}`, &tariff{Price: 0.25}, "Accepted"},
+		{"unknown vendor", "org.other", "Tariff", `{}`, nil, "UnknownVendorId"},
+		{"unknown message", "com.acme", "Firmware", `{}`, nil, "UnknownMessageId"},
+		{"vendor fallback", "com.example", "Firmware", `{"build":"42"}`, &firmware{Build: "42"}, "Accepted"},
+		{"vendor fallback without messageId", "com.example", "", `{"build":"42"}`, &firmware{Build: "42"}, "Accepted"},
+		{"invalid data", "com.acme", "Tariff", `not json`, nil, "Rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := registry.Decode(tt.vendorID, tt.messageID, tt.data)
+			assert.Equal(t, tt.value, value)
+			assert.Equal(t, tt.status, Status(err))
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Package enums holds the enumerations of the OCPP 1.5 and 1.6 messages.
+// Their text marshalling rejects the values they don't have, an empty
+// value being an unset field.
+package enums
+
+//go:generate go run ../internal/gen/enums
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownValue is returned when marshalling
+// a value which isn't one of the enumeration
+var ErrUnknownValue = errors.New("unknown enumeration value")
+
+func marshalText(value string, valid bool, enum string) ([]byte, error) {
+	if value != "" && !valid {
+		return nil, fmt.Errorf("%w: %q isn't a %s", ErrUnknownValue, value, enum)
+	}
+	return []byte(value), nil
+}
+
+func unmarshalText(text []byte, valid bool, enum string) error {
+	if len(text) > 0 && !valid {
+		return fmt.Errorf("%w: %q isn't a %s", ErrUnknownValue, text, enum)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package enums
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IsValid(t *testing.T) {
+	assert.True(t, ChargePointStatusAvailable.IsValid())
+	assert.True(t, ChargePointStatusOccupied.IsValid())
+	assert.False(t, ChargePointStatus("Sleeping").IsValid())
+	assert.False(t, ChargePointStatus("").IsValid())
+}
+
+func Test_TextMarshalling(t *testing.T) {
+	type message struct {
+		XMLName xml.Name            `json:"-" xml:"message"`
+		Status  AuthorizationStatus `json:"status,omitempty" xml:"status,omitempty"`
+		Unit    UnitOfMeasure       `json:"unit,omitempty" xml:"unit,omitempty"`
+	}
+
+	t.Run("output unchanged", func(t *testing.T) {
+		m := message{Status: AuthorizationStatusAccepted, Unit: UnitOfMeasureWh}
+		raw, err := json.Marshal(m)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"status":"Accepted","unit":"Wh"}`, string(raw))
+		raw, err = xml.Marshal(m)
+		assert.NoError(t, err)
+		assert.Equal(t, `<message><status>Accepted</status><unit>Wh</unit></message>`, string(raw))
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		raw, err := json.Marshal(message{})
+		assert.NoError(t, err)
+		assert.Equal(t, `{}`, string(raw))
+		var m message
+		assert.NoError(t, json.Unmarshal([]byte(`{"status":""}`), &m))
+		assert.Equal(t, AuthorizationStatus(""), m.Status)
+	})
+
+	t.Run("unknown value", func(t *testing.T) {
+		_, err := json.Marshal(message{Status: "Maybe"})
+		assert.True(t, errors.Is(err, ErrUnknownValue), "unexpected error: %v", err)
+		_, err = xml.Marshal(message{Status: "Maybe"})
+		assert.True(t, errors.Is(err, ErrUnknownValue), "unexpected error: %v", err)
+		var m message
+		err = json.Unmarshal([]byte(`{"status":"Maybe"}`), &m)
+		assert.True(t, errors.Is(err, ErrUnknownValue), "unexpected error: %v", err)
+		err = xml.Unmarshal([]byte(`<message><status>Maybe</status></message>`), &m)
+		assert.True(t, errors.Is(err, ErrUnknownValue), "unexpected error: %v", err)
+	})
+}
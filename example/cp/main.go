@@ -16,14 +16,14 @@ import (
 func main() {
 	stationID := "5"
 	centralSystemURL := "ws://localhost:12811"
-	st, err := cp.New(context.Background(), stationID, centralSystemURL, ocpp.V16, ocpp.JSON, nil, func(cprequest csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	st, err := cp.New(context.Background(), stationID, centralSystemURL, ocpp.V16, ocpp.JSON, nil, nil, func(cprequest csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
 		return nil, errors.New("not supported")
 	}) // or ocpp.SOAP
 	if err != nil {
 		fmt.Println("could not create charge point:", err)
 		return
 	}
-	rawResp, err := st.Send(&cpreq.Heartbeat{})
+	rawResp, err := st.Send(stationID, &cpreq.Heartbeat{})
 	if err != nil {
 		fmt.Println("could't send heartbeat:", err)
 		return
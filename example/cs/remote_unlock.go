@@ -1,22 +1,21 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"time"
 
-	"github.com/michaelbironneau/go-ocpp"
 	"github.com/michaelbironneau/go-ocpp/cs"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
 )
 
 func remote_unlock_main() {
 	csys := cs.New()
 	// this runs the central system on the given port
 	// and handles each incoming ChargepointRequest
-	go csys.Run(":12811", func(req cpreq.ChargePointRequest, cpID string) (cpresp.ChargePointResponse, error) {
+	go csys.Run(":12811", func(req cpreq.ChargePointRequest, metadata cs.ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
 		switch req.(type) {
 		case *cpreq.BootNotification:
 			return &cpresp.BootNotification{
@@ -37,13 +36,12 @@ func remote_unlock_main() {
 	})
 
 	cpID := "<CHARGEPOINT_ID>"
-	// cpURL is only used if it's SOAP
-	// if the communication is via WS
-	// the cpURL will be ignored
-	cpURL := "<CHARGEPOINT_URL>"
-	cpVersion := ocpp.V16
+	// a SOAP charger is reached at the endpoint
+	// learned from its requests, unless it's set
+	// by hand, e.g. when it's behind a NAT:
+	// csys.SetChargePointEndpoint(cpID, "<CHARGEPOINT_URL>")
 
-	cpService, err := csys.GetServiceOf(cpID, cpVersion, cpURL)
+	cpService, err := csys.GetServiceOf(cpID)
 	if err != nil {
 		panic(err)
 	}
@@ -53,7 +51,8 @@ func remote_unlock_main() {
 	// only a purely remote transaction(i.e. no local action needed)
 	// it can be anything(e.g. "VIRTUAL")
 	tag := "VIRTUAL"
-	cpResp, err := cpService.Send(&csreq.RemoteStartTransaction{
+	client := cs.NewChargePointClient(cpID, cpService)
+	resp, err := client.RemoteStartTransaction(context.Background(), &csreq.RemoteStartTransaction{
 		IdTag:       tag,
 		ConnectorId: 1,
 	})
@@ -61,7 +60,6 @@ func remote_unlock_main() {
 		// error on communicating
 		panic(err)
 	}
-	resp := cpResp.(*csresp.RemoteStartTransaction)
 	if resp.Status != "Accepted" {
 		panic("not accepted")
 	}
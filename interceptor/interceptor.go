@@ -0,0 +1,99 @@
+// Package interceptor wraps the OCPP messages handled and sent by the
+// central system and the charge points, the same way whatever the transport
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/internal/log"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/ws"
+)
+
+// Direction of a message, from the point of view of who intercepts it
+type Direction string
+
+const (
+	// Inbound requests are handled
+	Inbound Direction = "inbound"
+	// Outbound requests are sent
+	Outbound Direction = "outbound"
+)
+
+// Info about the intercepted request
+type Info struct {
+	ChargePointID string
+	Action        string
+	Direction     Direction
+	Transport     ocpp.Transport
+}
+
+// Handler handles an inbound request, or sends an outbound one
+type Handler func(ctx context.Context, req messages.Request) (messages.Response, error)
+
+// Interceptor is called instead of the handler, which it may call through next
+type Interceptor func(ctx context.Context, req messages.Request, info *Info, next Handler) (messages.Response, error)
+
+// Chain the interceptors into one, the first one being the outermost
+func Chain(interceptors ...Interceptor) Interceptor {
+	return func(ctx context.Context, req messages.Request, info *Info, next Handler) (messages.Response, error) {
+		handler := next
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], handler
+			handler = func(ctx context.Context, req messages.Request) (messages.Response, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Recovery turns a panic into an InternalError
+func Recovery() Interceptor {
+	return func(ctx context.Context, req messages.Request, info *Info, next Handler) (resp messages.Response, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("Panic on %s %s of %s: %v\n%s", info.Direction, info.Action, info.ChargePointID, r, debug.Stack())
+				resp, err = nil, fmt.Errorf("%w: panic on %s", ws.InternalError, info.Action)
+			}
+		}()
+		return next(ctx, req)
+	}
+}
+
+// Entry is what Logging logs of a request
+type Entry struct {
+	Info
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// Logging gives an entry to the logger once each request is done
+func Logging(logger func(entry Entry)) Interceptor {
+	return func(ctx context.Context, req messages.Request, info *Info, next Handler) (messages.Response, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+		logger(Entry{
+			Info:     *info,
+			Start:    start,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+		return resp, err
+	}
+}
+
+// Timing gives how long each request took to the observer, e.g. a histogram
+func Timing(observe func(info Info, duration time.Duration, err error)) Interceptor {
+	return func(ctx context.Context, req messages.Request, info *Info, next Handler) (messages.Response, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+		observe(*info, time.Since(start), err)
+		return resp, err
+	}
+}
@@ -0,0 +1,107 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/ws"
+	"github.com/stretchr/testify/assert"
+)
+
+var info = &Info{
+	ChargePointID: "cp1",
+	Action:        "Heartbeat",
+	Direction:     Inbound,
+	Transport:     ocpp.JSON,
+}
+
+func heartbeat(ctx context.Context, req messages.Request) (messages.Response, error) {
+	return &cpresp.Heartbeat{}, nil
+}
+
+func Test_Chain(t *testing.T) {
+	var calls []string
+	recorder := func(name string) Interceptor {
+		return func(ctx context.Context, req messages.Request, info *Info, next Handler) (messages.Response, error) {
+			calls = append(calls, name+" before")
+			resp, err := next(ctx, req)
+			calls = append(calls, name+" after")
+			return resp, err
+		}
+	}
+	resp, err := Chain(recorder("first"), recorder("second"))(context.Background(), &cpreq.Heartbeat{}, info, func(ctx context.Context, req messages.Request) (messages.Response, error) {
+		calls = append(calls, "handler")
+		return heartbeat(ctx, req)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &cpresp.Heartbeat{}, resp)
+	assert.Equal(t, []string{"first before", "second before", "handler", "second after", "first after"}, calls)
+
+	resp, err = Chain()(context.Background(), &cpreq.Heartbeat{}, info, heartbeat)
+	assert.NoError(t, err)
+	assert.Equal(t, &cpresp.Heartbeat{}, resp)
+}
+
+func Test_ChainShortCircuit(t *testing.T) {
+	rejected := errors.New("rejected")
+	reject := func(ctx context.Context, req messages.Request, info *Info, next Handler) (messages.Response, error) {
+		return nil, rejected
+	}
+	called := false
+	_, err := Chain(reject)(context.Background(), &cpreq.Heartbeat{}, info, func(ctx context.Context, req messages.Request) (messages.Response, error) {
+		called = true
+		return nil, nil
+	})
+	assert.Equal(t, rejected, err)
+	assert.False(t, called)
+}
+
+func Test_Recovery(t *testing.T) {
+	resp, err := Recovery()(context.Background(), &cpreq.Heartbeat{}, info, func(ctx context.Context, req messages.Request) (messages.Response, error) {
+		panic("boom")
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ws.InternalError), "unexpected error: %v", err)
+
+	resp, err = Recovery()(context.Background(), &cpreq.Heartbeat{}, info, heartbeat)
+	assert.NoError(t, err)
+	assert.Equal(t, &cpresp.Heartbeat{}, resp)
+}
+
+func Test_Logging(t *testing.T) {
+	failed := errors.New("failed")
+	var entries []Entry
+	_, err := Logging(func(entry Entry) {
+		entries = append(entries, entry)
+	})(context.Background(), &cpreq.Heartbeat{}, info, func(ctx context.Context, req messages.Request) (messages.Response, error) {
+		time.Sleep(time.Millisecond)
+		return nil, failed
+	})
+	assert.Equal(t, failed, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, *info, entries[0].Info)
+		assert.Equal(t, failed, entries[0].Err)
+		assert.False(t, entries[0].Start.IsZero())
+		assert.True(t, entries[0].Duration >= time.Millisecond)
+	}
+}
+
+func Test_Timing(t *testing.T) {
+	var observed time.Duration
+	var observedInfo Info
+	_, err := Timing(func(info Info, duration time.Duration, err error) {
+		observedInfo, observed = info, duration
+	})(context.Background(), &cpreq.Heartbeat{}, info, func(ctx context.Context, req messages.Request) (messages.Response, error) {
+		time.Sleep(time.Millisecond)
+		return heartbeat(ctx, req)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, *info, observedInfo)
+	assert.True(t, observed >= time.Millisecond)
+}
@@ -0,0 +1,94 @@
+// Command clients generates the typed methods of the
+// cs.ChargePointClient and cp.CentralSystemClient
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"text/template"
+)
+
+type side struct {
+	Package string
+	Client  string
+	ReqPkg  string
+	RespPkg string
+	Actions []string
+}
+
+var sides = map[string]side{
+	"cs": {
+		Package: "cs",
+		Client:  "ChargePointClient",
+		ReqPkg:  "csreq",
+		RespPkg: "csresp",
+		Actions: []string{
+			"CancelReservation", "ChangeAvailability", "ChangeConfiguration", "ClearCache",
+			"ClearChargingProfile", "DataTransfer", "GetCompositeSchedule", "GetConfiguration",
+			"GetDiagnostics", "GetLocalListVersion", "RemoteStartTransaction", "RemoteStopTransaction",
+			"ReserveNow", "Reset", "SendLocalList", "SetChargingProfile", "TriggerMessage",
+			"UnlockConnector", "UpdateFirmware",
+		},
+	},
+	"cp": {
+		Package: "cp",
+		Client:  "CentralSystemClient",
+		ReqPkg:  "cpreq",
+		RespPkg: "cpresp",
+		Actions: []string{
+			"Authorize", "BootNotification", "DataTransfer", "DiagnosticsStatusNotification",
+			"FirmwareStatusNotification", "Heartbeat", "MeterValues", "StartTransaction",
+			"StatusNotification", "StopTransaction",
+		},
+	},
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by internal/gen/clients; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/{{.ReqPkg}}"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/{{.RespPkg}}"
+)
+{{range .Actions}}
+// {{.}} sends the request and waits for its response until the context is done
+func (client *{{$.Client}}) {{.}}(ctx context.Context, req *{{$.ReqPkg}}.{{.}}) (*{{$.RespPkg}}.{{.}}, error) {
+	rawResp, err := client.service.SendContext(ctx, client.chargerID, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, ok := rawResp.(*{{$.RespPkg}}.{{.}})
+	if !ok {
+		return nil, &messages.UnexpectedResponseError{Action: req.Action(), Response: rawResp}
+	}
+	return resp, nil
+}
+{{end}}`))
+
+func main() {
+	sideName := flag.String("side", "", "cs or cp")
+	output := flag.String("o", "client_gen.go", "output file")
+	flag.Parse()
+	s, ok := sides[*sideName]
+	if !ok {
+		log.Fatalf("unknown side %q", *sideName)
+	}
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, s); err != nil {
+		log.Fatal(err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
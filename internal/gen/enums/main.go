@@ -0,0 +1,132 @@
+// Command enums generates the OCPP 1.5/1.6 enumerations of the enums
+// package, with their values in both versions
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type enum struct {
+	Name   string
+	Doc    string
+	Values []string
+}
+
+var enumerations = []enum{
+	{"AuthorizationStatus", "status of an idTag", []string{"Accepted", "Blocked", "Expired", "Invalid", "ConcurrentTx"}},
+	{"AvailabilityStatus", "answer to a ChangeAvailability", []string{"Accepted", "Rejected", "Scheduled"}},
+	{"AvailabilityType", "availability asked by a ChangeAvailability", []string{"Inoperative", "Operative"}},
+	{"CancelReservationStatus", "answer to a CancelReservation", []string{"Accepted", "Rejected"}},
+	{"ChargePointStatus", "status of a connector, Occupied is OCPP 1.5 only", []string{"Available", "Preparing", "Charging", "SuspendedEVSE", "SuspendedEV", "Finishing", "Reserved", "Unavailable", "Faulted", "Occupied"}},
+	{"ChargingProfileKind", "kind of a charging schedule", []string{"Absolute", "Recurring", "Relative"}},
+	{"ChargingProfilePurpose", "purpose of a charging profile", []string{"ChargePointMaxProfile", "TxDefaultProfile", "TxProfile"}},
+	{"ChargingProfileStatus", "answer to a SetChargingProfile", []string{"Accepted", "Rejected", "NotSupported"}},
+	{"ChargingRateUnit", "unit of the limits of a charging schedule", []string{"A", "W"}},
+	{"ClearCacheStatus", "answer to a ClearCache", []string{"Accepted", "Rejected"}},
+	{"ClearChargingProfileStatus", "answer to a ClearChargingProfile", []string{"Accepted", "Unknown"}},
+	{"ConfigurationStatus", "answer to a ChangeConfiguration", []string{"Accepted", "Rejected", "RebootRequired", "NotSupported"}},
+	{"DataTransferStatus", "answer to a DataTransfer", []string{"Accepted", "Rejected", "UnknownMessageId", "UnknownVendorId"}},
+	{"DiagnosticsStatus", "status of a diagnostics upload", []string{"Idle", "Uploaded", "UploadFailed", "Uploading"}},
+	{"FirmwareStatus", "status of a firmware update", []string{"Downloaded", "DownloadFailed", "Downloading", "Idle", "InstallationFailed", "Installing", "Installed"}},
+	{"GetCompositeScheduleStatus", "answer to a GetCompositeSchedule", []string{"Accepted", "Rejected"}},
+	{"Location", "where a value is sampled", []string{"Cable", "EV", "Inlet", "Outlet", "Body"}},
+	{"Measurand", "what a sampled value measures", []string{"Energy.Active.Export.Register", "Energy.Active.Import.Register", "Energy.Reactive.Export.Register", "Energy.Reactive.Import.Register", "Energy.Active.Export.Interval", "Energy.Active.Import.Interval", "Energy.Reactive.Export.Interval", "Energy.Reactive.Import.Interval", "Power.Active.Export", "Power.Active.Import", "Power.Offered", "Power.Reactive.Export", "Power.Reactive.Import", "Power.Factor", "Current.Import", "Current.Export", "Current.Offered", "Voltage", "Frequency", "Temperature", "SoC", "RPM"}},
+	{"MessageTrigger", "message asked by a TriggerMessage", []string{"BootNotification", "DiagnosticsStatusNotification", "FirmwareStatusNotification", "Heartbeat", "MeterValues", "StatusNotification"}},
+	{"Phase", "phase of a sampled value", []string{"L1", "L2", "L3", "N", "L1-N", "L2-N", "L3-N", "L1-L2", "L2-L3", "L3-L1"}},
+	{"ReadingContext", "why a value is sampled", []string{"Interruption.Begin", "Interruption.End", "Sample.Clock", "Sample.Periodic", "Transaction.Begin", "Transaction.End", "Trigger", "Other"}},
+	{"Reason", "why a transaction stopped", []string{"EmergencyStop", "EVDisconnected", "HardReset", "Local", "Other", "PowerLoss", "Reboot", "Remote", "SoftReset", "UnlockCommand", "DeAuthorized"}},
+	{"RecurrencyKind", "period of a recurring charging schedule", []string{"Daily", "Weekly"}},
+	{"RegistrationStatus", "answer to a BootNotification", []string{"Accepted", "Pending", "Rejected"}},
+	{"RemoteStartStopStatus", "answer to a RemoteStartTransaction or RemoteStopTransaction", []string{"Accepted", "Rejected"}},
+	{"ReservationStatus", "answer to a ReserveNow", []string{"Accepted", "Faulted", "Occupied", "Rejected", "Unavailable"}},
+	{"ResetStatus", "answer to a Reset", []string{"Accepted", "Rejected"}},
+	{"ResetType", "kind of reset asked by a Reset", []string{"Hard", "Soft"}},
+	{"TriggerMessageStatus", "answer to a TriggerMessage", []string{"Accepted", "Rejected", "NotImplemented"}},
+	{"UnitOfMeasure", "unit of a sampled value, Amp, Volt and Celsius are OCPP 1.5 only", []string{"Wh", "kWh", "varh", "kvarh", "W", "kW", "VA", "kVA", "var", "kvar", "A", "V", "K", "Celcius", "Fahrenheit", "Percent", "Amp", "Volt", "Celsius"}},
+	{"UnlockStatus", "answer to an UnlockConnector, Accepted and Rejected are OCPP 1.5 only", []string{"Unlocked", "UnlockFailed", "NotSupported", "Accepted", "Rejected"}},
+	{"UpdateStatus", "answer to a SendLocalList, HashError is OCPP 1.5 only", []string{"Accepted", "Failed", "NotSupported", "VersionMismatch", "HashError"}},
+	{"UpdateType", "kind of update of a SendLocalList", []string{"Differential", "Full"}},
+	{"ValueFormat", "format of a sampled value", []string{"Raw", "SignedData"}},
+}
+
+// constName is the Go name of the value of the enumeration,
+// e.g. MeasurandEnergyActiveImportRegister
+func constName(enum, value string) string {
+	var name strings.Builder
+	name.WriteString(enum)
+	upper := true
+	for _, r := range value {
+		if r == '.' || r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	return name.String()
+}
+
+var enumsTemplate = template.Must(template.New("enums").Funcs(template.FuncMap{
+	"const": constName,
+}).Parse(`// Code generated by internal/gen/enums; DO NOT EDIT.
+
+package enums
+{{range .}}
+// {{.Name}} is the {{.Doc}}
+type {{.Name}} string
+
+const (
+{{- $enum := .Name}}
+{{- range .Values}}
+	{{const $enum .}} {{$enum}} = "{{.}}"
+{{- end}}
+)
+
+// IsValid tells whether it's one of the values of the enumeration
+func (v {{.Name}}) IsValid() bool {
+	switch v {
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{const $enum $v}}{{end}}:
+		return true
+	}
+	return false
+}
+
+func (v {{.Name}}) MarshalText() ([]byte, error) {
+	return marshalText(string(v), v.IsValid(), "{{.Name}}")
+}
+
+func (v *{{.Name}}) UnmarshalText(text []byte) error {
+	if err := unmarshalText(text, {{.Name}}(text).IsValid(), "{{.Name}}"); err != nil {
+		return err
+	}
+	*v = {{.Name}}(text)
+	return nil
+}
+{{end}}`))
+
+func main() {
+	output := flag.String("o", "enums_gen.go", "output file")
+	flag.Parse()
+
+	var buf bytes.Buffer
+	if err := enumsTemplate.Execute(&buf, enumerations); err != nil {
+		log.Fatal(err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("%v\n%s", err, buf.Bytes())
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,52 @@
+// Command schemas generates the OCPP 1.6 JSON schemas of ws/json
+// as Go strings, so they're built in the module
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("dir", "json", "directory of the schemas")
+	output := flag.String("o", "schemas_gen.go", "output file")
+	flag.Parse()
+
+	files, err := filepath.Glob(filepath.Join(*dir, "*.json"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by internal/gen/schemas; DO NOT EDIT.\n\n")
+	buf.WriteString("package ws\n\n")
+	buf.WriteString("// schemaSources are the OCPP 1.6 JSON schemas, by action\n")
+	buf.WriteString("// for the requests and by action + \"Response\" for the responses\n")
+	buf.WriteString("var schemaSources = map[string]string{\n")
+	for _, file := range files {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, raw); err != nil {
+			log.Fatalf("%s: %v", file, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		buf.WriteString(strconv.Quote(name) + ": " + strconv.Quote(compacted.String()) + ",\n")
+	}
+	buf.WriteString("}\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
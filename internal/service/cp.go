@@ -1,6 +1,10 @@
 package service
 
 import (
+	"context"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/messages"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
 	"github.com/michaelbironneau/go-ocpp/soap"
@@ -9,26 +13,25 @@ import (
 
 type ChargePoint interface {
 	Send(string, csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error)
+	// SendContext is like Send, but gives up waiting
+	// for the response when the context is done
+	SendContext(context.Context, string, csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error)
 }
 
 type ChargePointSOAP struct {
 	*SOAP
 }
 
-func NewChargePointSOAP(csURL string, options *soap.CallOptions) ChargePoint {
-	return &ChargePointSOAP{NewSOAP(csURL, options)}
+func NewChargePointSOAP(cpURL string, options *soap.CallOptions, timeout time.Duration) ChargePoint {
+	return &ChargePointSOAP{NewSOAP(cpURL, options, timeout)}
 }
 
 func (service *ChargePointSOAP) Send(chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
-	rawResp, err := service.SOAP.Send(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, ok := rawResp.(csresp.CentralSystemResponse)
-	if !ok {
-		return nil, csresp.ErrorNotCentralSystemResponse
-	}
-	return resp, nil
+	return toCentralSystemResponse(service.SOAP.Send(req))
+}
+
+func (service *ChargePointSOAP) SendContext(ctx context.Context, chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	return toCentralSystemResponse(service.SOAP.SendContext(ctx, req))
 }
 
 type ChargePointJSON struct {
@@ -40,7 +43,14 @@ func NewChargePointJSON(conn *ws.Conn) ChargePoint {
 }
 
 func (service *ChargePointJSON) Send(chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
-	rawResp, err := service.JSON.Send(chargerID, req)
+	return toCentralSystemResponse(service.JSON.Send(chargerID, req))
+}
+
+func (service *ChargePointJSON) SendContext(ctx context.Context, chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	return toCentralSystemResponse(service.JSON.SendContext(ctx, chargerID, req))
+}
+
+func toCentralSystemResponse(rawResp messages.Response, err error) (csresp.CentralSystemResponse, error) {
 	if err != nil {
 		return nil, err
 	}
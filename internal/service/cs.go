@@ -1,32 +1,37 @@
 package service
 
 import (
+	"context"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/messages"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/soap"
 	"github.com/michaelbironneau/go-ocpp/ws"
 )
 
 type CentralSystem interface {
-	Send(chargerID string, request csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error)
+	Send(chargerID string, request cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error)
+	// SendContext is like Send, but gives up waiting
+	// for the response when the context is done
+	SendContext(ctx context.Context, chargerID string, request cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error)
 }
 
 type CentralSystemSOAP struct {
 	*SOAP
 }
 
+func NewCentralSystemSOAP(csURL string, options *soap.CallOptions, timeout time.Duration) CentralSystem {
+	return &CentralSystemSOAP{NewSOAP(csURL, options, timeout)}
+}
 
 func (service *CentralSystemSOAP) Send(chargerID string, req cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error) {
-	rawResp, err := service.SOAP.Send(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, ok := rawResp.(cpresp.ChargePointResponse)
-	if !ok {
-		return nil, cpresp.ErrorNotChargePointResponse
-	}
-	return resp, nil
+	return toChargePointResponse(service.SOAP.Send(req))
+}
+
+func (service *CentralSystemSOAP) SendContext(ctx context.Context, chargerID string, req cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error) {
+	return toChargePointResponse(service.SOAP.SendContext(ctx, req))
 }
 
 type CentralSystemJSON struct {
@@ -37,12 +42,19 @@ func NewCentralSystemJSON(conn *ws.Conn) CentralSystem {
 	return &CentralSystemJSON{NewJSON(conn)}
 }
 
-func (service *CentralSystemJSON) Send(chargerID string, request csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
-	rawResp, err := service.JSON.Send(chargerID, request)
+func (service *CentralSystemJSON) Send(chargerID string, request cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error) {
+	return toChargePointResponse(service.JSON.Send(chargerID, request))
+}
+
+func (service *CentralSystemJSON) SendContext(ctx context.Context, chargerID string, request cpreq.ChargePointRequest) (cpresp.ChargePointResponse, error) {
+	return toChargePointResponse(service.JSON.SendContext(ctx, chargerID, request))
+}
+
+func toChargePointResponse(rawResp messages.Response, err error) (cpresp.ChargePointResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, ok := rawResp.(csresp.CentralSystemResponse)
+	resp, ok := rawResp.(cpresp.ChargePointResponse)
 	if !ok {
 		return nil, cpresp.ErrorNotChargePointResponse
 	}
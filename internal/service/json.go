@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+
 	"github.com/michaelbironneau/go-ocpp/messages"
 	"github.com/michaelbironneau/go-ocpp/ws"
 )
@@ -26,3 +28,7 @@ func NewJSON(conn *ws.Conn) *JSON {
 func (service *JSON) Send(chargerID string, req messages.Request) (messages.Response, error) {
 	return service.conn.SendRequest(chargerID, req)
 }
+
+func (service *JSON) SendContext(ctx context.Context, chargerID string, req messages.Request) (messages.Response, error) {
+	return service.conn.SendRequestContext(ctx, chargerID, req)
+}
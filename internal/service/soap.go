@@ -1,6 +1,9 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/michaelbironneau/go-ocpp/messages"
 	"github.com/michaelbironneau/go-ocpp/soap"
 )
@@ -8,19 +11,33 @@ import (
 type SOAP struct {
 	client  *soap.Client
 	options *soap.CallOptions
+	timeout time.Duration
 }
 
-func NewSOAP(URL string, options *soap.CallOptions) *SOAP {
+func NewSOAP(URL string, options *soap.CallOptions, timeout time.Duration) *SOAP {
 	client := soap.NewClient(URL)
 	return &SOAP{
 		client:  client,
 		options: options,
+		timeout: timeout,
 	}
 }
 
 func (service *SOAP) Send(req messages.Request) (messages.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), service.timeout)
+	defer cancel()
+	return service.SendContext(ctx, req)
+}
+
+// SendContext waits at most the timeout when the context has no deadline
+func (service *SOAP) SendContext(ctx context.Context, req messages.Request) (messages.Response, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, service.timeout)
+		defer cancel()
+	}
 	resp := req.GetResponse()
-	err := service.client.Call(req.Action(), req, resp, service.options)
+	err := service.client.CallContext(ctx, req.Action(), req, resp, service.options)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,455 @@
+// Package loadbalancing shares the capacity of a site, e.g. the grid
+// connection of a depot, between the transactions of its charge
+// points, by giving each a TxProfile through SetChargingProfile
+package loadbalancing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/internal/log"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/smartcharging"
+)
+
+const (
+	defaultInterval     = time.Minute
+	defaultMinCurrent   = 6
+	defaultMaxCurrent   = 32
+	defaultVoltage      = 230
+	defaultPhases       = 3
+	defaultProfileID    = 1000
+	defaultStackLevel   = 0
+	defaultUnusedMargin = 2.0
+	defaultSetTimeout   = 30 * time.Second
+	defaultStartDelay   = 5 * time.Second
+)
+
+// Session is an ongoing transaction of the site
+type Session struct {
+	ChargePointID string
+	ConnectorId   int
+	TransactionId int32
+	Start         time.Time
+	// Priority of the session for the Priority strategy, the higher first
+	Priority int
+	// MinCurrent the session needs to charge, in A per phase
+	MinCurrent float64
+	// Demand is the current the session can take, in A per phase: the
+	// maximum current of its connector, unless its meter values show
+	// it draws less than its limit, e.g. as its battery is full
+	Demand float64
+	// Current drawn by the session, from its latest meter values
+	Current float64
+	// Limit last given to the session, -1 before the first one
+	Limit float64
+}
+
+type sessionKey struct {
+	chargePointID string
+	connectorID   int
+}
+
+// Site is a group of charge points sharing a capacity
+type Site struct {
+	manager  *smartcharging.Manager
+	capacity float64
+	strategy Strategy
+	interval time.Duration
+	// currents in A per phase
+	minCurrent float64
+	maxCurrent float64
+	// voltage and phases convert the power of meter values to a current
+	voltage float64
+	phases  int
+	// profileID of the TxProfile of connector 1, the
+	// ones of the other connectors following it
+	profileID  int
+	stackLevel int
+	// setTimeout bounds how long a charge point takes to accept its TxProfile
+	setTimeout time.Duration
+	// startDelay is how long Run waits to balance the site after a start
+	startDelay time.Duration
+
+	mux          sync.Mutex
+	chargePoints map[string]bool
+	sessions     map[sessionKey]*Session
+	// changed is signalled when a session starts or stops,
+	// so that Run balances the site without waiting
+	changed chan struct{}
+}
+
+// Option configures the site
+type Option func(*Site)
+
+// WithStrategy sets how the capacity is shared, EqualShare by default
+func WithStrategy(strategy Strategy) Option {
+	return func(s *Site) {
+		s.strategy = strategy
+	}
+}
+
+// WithInterval sets how often Run balances the site, every minute by default
+func WithInterval(interval time.Duration) Option {
+	return func(s *Site) {
+		s.interval = interval
+	}
+}
+
+// WithCurrents sets the minimum current an EV needs to charge, 6 A by
+// default, and the maximum current of a connector, 32 A by default
+func WithCurrents(min, max float64) Option {
+	return func(s *Site) {
+		s.minCurrent = min
+		s.maxCurrent = max
+	}
+}
+
+// WithSupply sets the voltage, 230 V by default, and the number of
+// phases, 3 by default, converting the power in meter values to a current
+func WithSupply(voltage float64, phases int) Option {
+	return func(s *Site) {
+		s.voltage = voltage
+		s.phases = phases
+	}
+}
+
+// WithProfile sets the ID of the TxProfile given to connector 1, 1000 by
+// default, the other connectors following it, and its stack level, 0 by
+// default. They must not clash with the other profiles of the charge points.
+func WithProfile(profileID, stackLevel int) Option {
+	return func(s *Site) {
+		s.profileID = profileID
+		s.stackLevel = stackLevel
+	}
+}
+
+// WithSetTimeout sets how long Balance waits for a charge point
+// to accept its TxProfile, 30 seconds by default
+func WithSetTimeout(timeout time.Duration) Option {
+	return func(s *Site) {
+		s.setTimeout = timeout
+	}
+}
+
+// WithStartDelay sets how long Run waits to balance the site after a
+// transaction started, 5 seconds by default, so that the charge point got
+// the StartTransaction response, and knows the transaction of its TxProfile
+func WithStartDelay(delay time.Duration) Option {
+	return func(s *Site) {
+		s.startDelay = delay
+	}
+}
+
+// New site of the given capacity, in A per phase, which pushes
+// the TxProfiles of its sessions through the manager
+func New(manager *smartcharging.Manager, capacity float64, options ...Option) *Site {
+	s := &Site{
+		manager:      manager,
+		capacity:     capacity,
+		strategy:     EqualShare(),
+		interval:     defaultInterval,
+		minCurrent:   defaultMinCurrent,
+		maxCurrent:   defaultMaxCurrent,
+		voltage:      defaultVoltage,
+		phases:       defaultPhases,
+		profileID:    defaultProfileID,
+		stackLevel:   defaultStackLevel,
+		setTimeout:   defaultSetTimeout,
+		startDelay:   defaultStartDelay,
+		chargePoints: make(map[string]bool),
+		sessions:     make(map[sessionKey]*Session),
+		changed:      make(chan struct{}, 1),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// AddChargePoints to the site, the transactions of the
+// others being ignored
+func (s *Site) AddChargePoints(cpIDs ...string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, cpID := range cpIDs {
+		s.chargePoints[cpID] = true
+	}
+}
+
+// RemoveChargePoint from the site, along with its sessions
+func (s *Site) RemoveChargePoint(cpID string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.chargePoints, cpID)
+	for key := range s.sessions {
+		if key.chargePointID == cpID {
+			delete(s.sessions, key)
+		}
+	}
+	s.signal()
+}
+
+// StartTransaction adds the transaction, accepted by the
+// central system, to the sessions sharing the capacity
+func (s *Site) StartTransaction(cpID string, connectorID int, transactionID int32, start time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if !s.chargePoints[cpID] {
+		return
+	}
+	s.sessions[sessionKey{cpID, connectorID}] = &Session{
+		ChargePointID: cpID,
+		ConnectorId:   connectorID,
+		TransactionId: transactionID,
+		Start:         start,
+		MinCurrent:    s.minCurrent,
+		Demand:        s.maxCurrent,
+		Limit:         -1,
+	}
+	s.manager.StartTransaction(cpID, connectorID, transactionID, start)
+	time.AfterFunc(s.startDelay, s.signal)
+}
+
+// StopTransaction removes the transaction from the sessions
+func (s *Site) StopTransaction(cpID string, transactionID int32) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for key, session := range s.sessions {
+		if key.chargePointID == cpID && session.TransactionId == transactionID {
+			delete(s.sessions, key)
+			s.manager.StopTransaction(cpID, key.connectorID)
+			s.signal()
+		}
+	}
+}
+
+// SetPriority of the session of the connector
+func (s *Site) SetPriority(cpID string, connectorID int, priority int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if session, ok := s.sessions[sessionKey{cpID, connectorID}]; ok {
+		session.Priority = priority
+	}
+}
+
+// MeterValues updates the current drawn by the session of the
+// connector, from its latest Current.Import or Power.Active.Import
+func (s *Site) MeterValues(cpID string, req *cpreq.MeterValues) {
+	current, ok := s.current(req)
+	if !ok {
+		return
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	session, ok := s.sessions[sessionKey{cpID, req.ConnectorId}]
+	if !ok || (req.TransactionId != 0 && req.TransactionId != session.TransactionId) {
+		return
+	}
+	session.Current = current
+	// a session drawing clearly less than its limit can't take more,
+	// the rest of its share is better used by the others, until it
+	// draws close to its limit again
+	switch {
+	case session.Limit < 0:
+	case current < session.Limit-defaultUnusedMargin:
+		session.Demand = math.Min(current+defaultUnusedMargin, s.maxCurrent)
+	case current >= session.Limit-defaultUnusedMargin/2:
+		session.Demand = s.maxCurrent
+	}
+}
+
+// current is the highest current of the phases in
+// the latest meter value, in A per phase
+func (s *Site) current(req *cpreq.MeterValues) (float64, bool) {
+	var latest *cpreq.MeterValueItems
+	for _, meterValue := range req.MeterValue {
+		if meterValue != nil && (latest == nil || meterValue.Timestamp.After(latest.Timestamp)) {
+			latest = meterValue
+		}
+	}
+	if latest == nil {
+		return 0, false
+	}
+	current, power := -1.0, -1.0
+	for _, sample := range latest.SampledValues {
+		if sample == nil || sample.Location == enums.LocationEV || sample.Location == enums.LocationInlet {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(sample.Value), 64)
+		if err != nil {
+			continue
+		}
+		switch sample.Measurand {
+		case enums.MeasurandCurrentImport:
+			current = math.Max(current, value)
+		case enums.MeasurandPowerActiveImport:
+			if sample.Unit == enums.UnitOfMeasureKW {
+				value *= 1000
+			}
+			if sample.Phase == "" {
+				value /= float64(s.phases)
+			}
+			power = math.Max(power, value)
+		}
+	}
+	if current >= 0 {
+		return current, true
+	}
+	if power >= 0 {
+		return power / s.voltage, true
+	}
+	return 0, false
+}
+
+// Sessions of the site, by charge point and connector
+func (s *Site) Sessions() []Session {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.sortedSessions()
+}
+
+func (s *Site) sortedSessions() []Session {
+	sessions := make([]Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, *session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].ChargePointID != sessions[j].ChargePointID {
+			return sessions[i].ChargePointID < sessions[j].ChargePointID
+		}
+		return sessions[i].ConnectorId < sessions[j].ConnectorId
+	})
+	return sessions
+}
+
+// Balance allocates the capacity between the sessions, and sends
+// their TxProfile to the ones whose limit changed. The lowered limits
+// are sent first, then the raised ones, each to all the charge points
+// at once, so that one which doesn't answer doesn't hold the others.
+// The raised limits aren't sent when a lowered one failed, as the site
+// could exceed its capacity, unless it was the first limit of a new
+// session, e.g. whose charge point doesn't know the transaction yet.
+// It returns the first error.
+func (s *Site) Balance(ctx context.Context) error {
+	s.mux.Lock()
+	sessions := s.sortedSessions()
+	s.mux.Unlock()
+	if len(sessions) == 0 {
+		return nil
+	}
+	limits := s.strategy.Allocate(s.capacity, sessions)
+	if len(limits) != len(sessions) {
+		return fmt.Errorf("strategy gave %d limits to %d sessions", len(limits), len(sessions))
+	}
+	var lowering, raising []int
+	for i, session := range sessions {
+		switch {
+		case session.Limit >= 0 && math.Abs(limits[i]-session.Limit) < 0.05:
+		case lowered(session, limits[i]):
+			lowering = append(lowering, i)
+		default:
+			raising = append(raising, i)
+		}
+	}
+	var firstErr error
+	for n, err := range s.setLimits(ctx, sessions, limits, lowering) {
+		if err == nil {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if sessions[lowering[n]].Limit >= 0 {
+			// it may still draw more than its share
+			raising = nil
+		}
+	}
+	for _, err := range s.setLimits(ctx, sessions, limits, raising) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// setLimits sends the TxProfiles of the given sessions concurrently,
+// each within the set timeout, and returns their errors
+func (s *Site) setLimits(ctx context.Context, sessions []Session, limits []float64, indexes []int) []error {
+	errs := make([]error, len(indexes))
+	var wg sync.WaitGroup
+	for n, i := range indexes {
+		wg.Add(1)
+		go func(n int, session Session, limit float64) {
+			defer wg.Done()
+			setCtx, cancel := context.WithTimeout(ctx, s.setTimeout)
+			defer cancel()
+			err := s.manager.Set(setCtx, session.ChargePointID, session.ConnectorId, s.txProfile(session, limit))
+			if err != nil {
+				errs[n] = fmt.Errorf("limiting connector %d of %s: %w", session.ConnectorId, session.ChargePointID, err)
+				return
+			}
+			s.mux.Lock()
+			// unless it stopped meanwhile
+			if current, ok := s.sessions[sessionKey{session.ChargePointID, session.ConnectorId}]; ok && current.TransactionId == session.TransactionId {
+				current.Limit = limit
+			}
+			s.mux.Unlock()
+		}(n, sessions[i], limits[i])
+	}
+	wg.Wait()
+	return errs
+}
+
+// lowered tells whether the limit lowers the one of the session,
+// a session without limit yet drawing as much as it can
+func lowered(session Session, limit float64) bool {
+	return session.Limit < 0 || limit < session.Limit
+}
+
+func (s *Site) txProfile(session Session, limit float64) *csreq.ChargingProfile {
+	return &csreq.ChargingProfile{
+		ChargingProfileId:      s.profileID + session.ConnectorId - 1,
+		StackLevel:             s.stackLevel,
+		ChargingProfilePurpose: enums.ChargingProfilePurposeTxProfile,
+		ChargingProfileKind:    enums.ChargingProfileKindRelative,
+		TransactionId:          session.TransactionId,
+		ChargingSchedule: &csreq.ChargingSchedule{
+			ChargingRateUnit:       enums.ChargingRateUnitA,
+			ChargingSchedulePeriod: []*csreq.ChargingSchedulePeriodItems{{StartPeriod: 0, Limit: limit}},
+		},
+	}
+}
+
+func (s *Site) signal() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Run balances the site at every interval, and as soon as a session
+// starts or stops, until the context is done
+func (s *Site) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.changed:
+		}
+		if err := s.Balance(ctx); err != nil {
+			log.Error("Balancing the site: %v", err)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package loadbalancing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/internal/service"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/smartcharging"
+	"github.com/stretchr/testify/assert"
+)
+
+// simulatedChargePoint applies the TxProfiles it's sent to the
+// EVs on its connectors, which draw as much as they can. Like a
+// compliant charge point, it rejects the TxProfiles of the
+// transactions it doesn't know, i.e. wasn't answered yet.
+type simulatedChargePoint struct {
+	mux     sync.Mutex
+	offline bool
+	// silent charge points never answer
+	silent bool
+	// evs is the maximum current of the EV on each connector
+	evs    map[int]float64
+	limits map[int]float64
+	// transactions known on each connector
+	transactions map[int]int32
+	sent         int
+	rejected     int
+}
+
+func (cp *simulatedChargePoint) Send(chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	return cp.SendContext(context.Background(), chargerID, req)
+}
+
+func (cp *simulatedChargePoint) SendContext(ctx context.Context, chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	cp.mux.Lock()
+	if cp.silent {
+		cp.mux.Unlock()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	defer cp.mux.Unlock()
+	if cp.offline {
+		return nil, errors.New("offline")
+	}
+	switch req := req.(type) {
+	case *csreq.SetChargingProfile:
+		cp.sent++
+		if profile := req.CsChargingProfiles; profile.ChargingProfilePurpose == enums.ChargingProfilePurposeTxProfile && profile.TransactionId != cp.transactions[req.ConnectorId] {
+			cp.rejected++
+			return &csresp.SetChargingProfile{Status: enums.ChargingProfileStatusRejected}, nil
+		}
+		cp.limits[req.ConnectorId] = req.CsChargingProfiles.ChargingSchedule.ChargingSchedulePeriod[0].Limit
+		return &csresp.SetChargingProfile{Status: enums.ChargingProfileStatusAccepted}, nil
+	case *csreq.ClearChargingProfile:
+		return &csresp.ClearChargingProfile{Status: enums.ClearChargingProfileStatusAccepted}, nil
+	}
+	return nil, fmt.Errorf("unexpected %s", req.Action())
+}
+
+// draw is the current drawn on the connector
+func (cp *simulatedChargePoint) draw(connectorID int) float64 {
+	cp.mux.Lock()
+	defer cp.mux.Unlock()
+	limit, ok := cp.limits[connectorID]
+	if !ok || limit > cp.evs[connectorID] {
+		return cp.evs[connectorID]
+	}
+	return limit
+}
+
+// simulation of a depot of charge points of one connector
+type simulation struct {
+	t            *testing.T
+	site         *Site
+	chargePoints map[string]*simulatedChargePoint
+	clock        time.Time
+	transactions int32
+}
+
+func newSimulation(t *testing.T, capacity float64, chargePoints int, options ...Option) *simulation {
+	sim := &simulation{
+		t:            t,
+		chargePoints: make(map[string]*simulatedChargePoint),
+		clock:        time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC),
+	}
+	manager := smartcharging.NewManager(func(cpID string) (service.ChargePoint, error) {
+		cp, ok := sim.chargePoints[cpID]
+		if !ok {
+			return nil, errors.New("unknown charge point")
+		}
+		return cp, nil
+	})
+	sim.site = New(manager, capacity, options...)
+	for i := 1; i <= chargePoints; i++ {
+		cpID := fmt.Sprintf("CP%02d", i)
+		sim.chargePoints[cpID] = &simulatedChargePoint{evs: make(map[int]float64), limits: make(map[int]float64), transactions: make(map[int]int32)}
+		sim.site.AddChargePoints(cpID)
+	}
+	return sim
+}
+
+// plugIn an EV drawing at most the given current
+func (sim *simulation) plugIn(cpID string, current float64) int32 {
+	transactionID := sim.start(cpID, current)
+	sim.answer(cpID, transactionID)
+	return transactionID
+}
+
+// start the transaction of an EV drawing at most the given current,
+// before the StartTransaction response reaches the charge point
+func (sim *simulation) start(cpID string, current float64) int32 {
+	sim.transactions++
+	cp := sim.chargePoints[cpID]
+	cp.mux.Lock()
+	cp.evs[1] = current
+	delete(cp.limits, 1)
+	cp.mux.Unlock()
+	sim.clock = sim.clock.Add(time.Second)
+	sim.site.StartTransaction(cpID, 1, sim.transactions, sim.clock)
+	return sim.transactions
+}
+
+// answer the StartTransaction, the charge point knowing the transaction
+func (sim *simulation) answer(cpID string, transactionID int32) {
+	cp := sim.chargePoints[cpID]
+	cp.mux.Lock()
+	defer cp.mux.Unlock()
+	cp.transactions[1] = transactionID
+}
+
+func (sim *simulation) unplug(cpID string, transactionID int32) {
+	cp := sim.chargePoints[cpID]
+	cp.mux.Lock()
+	delete(cp.evs, 1)
+	delete(cp.limits, 1)
+	delete(cp.transactions, 1)
+	cp.mux.Unlock()
+	sim.site.StopTransaction(cpID, transactionID)
+}
+
+// step balances the site, then the charge points
+// send the meter values of what the EVs draw
+func (sim *simulation) step() {
+	if err := sim.site.Balance(context.Background()); err != nil {
+		sim.t.Errorf("balancing: %v", err)
+	}
+	sim.clock = sim.clock.Add(time.Minute)
+	for _, session := range sim.site.Sessions() {
+		draw := sim.chargePoints[session.ChargePointID].draw(session.ConnectorId)
+		sim.site.MeterValues(session.ChargePointID, meterValues(session, sim.clock, draw))
+	}
+	sim.checkCapacity()
+}
+
+func meterValues(session Session, at time.Time, current float64) *cpreq.MeterValues {
+	var samples []*cpreq.SampledValue
+	for _, phase := range []enums.Phase{enums.PhaseL1, enums.PhaseL2, enums.PhaseL3} {
+		samples = append(samples, &cpreq.SampledValue{
+			Measurand: enums.MeasurandCurrentImport,
+			Phase:     phase,
+			Unit:      enums.UnitOfMeasureA,
+			Value:     strconv.FormatFloat(current, 'f', 1, 64),
+		})
+	}
+	return &cpreq.MeterValues{
+		ConnectorId:   session.ConnectorId,
+		TransactionId: session.TransactionId,
+		MeterValue:    []*cpreq.MeterValueItems{{Timestamp: at, SampledValues: samples}},
+	}
+}
+
+// checkCapacity checks the limits given add up to the capacity at most
+func (sim *simulation) checkCapacity() {
+	total := 0.0
+	for _, cp := range sim.chargePoints {
+		cp.mux.Lock()
+		for connectorID := range cp.evs {
+			total += cp.limits[connectorID]
+		}
+		cp.mux.Unlock()
+	}
+	assert.True(sim.t, total <= sim.site.capacity+1e-9, "limits add up to %v over %v", total, sim.site.capacity)
+}
+
+func (sim *simulation) limit(cpID string) float64 {
+	cp := sim.chargePoints[cpID]
+	cp.mux.Lock()
+	defer cp.mux.Unlock()
+	return cp.limits[1]
+}
+
+func Test_SimulatedDepot(t *testing.T) {
+	// twenty chargers of 32 A sharing 200 A
+	sim := newSimulation(t, 200, 20)
+	transactions := make(map[string]int32)
+	for i := 1; i <= 10; i++ {
+		cpID := fmt.Sprintf("CP%02d", i)
+		transactions[cpID] = sim.plugIn(cpID, 32)
+	}
+	sim.step()
+	assert.Equal(t, 20.0, sim.limit("CP01"))
+	assert.Equal(t, 20.0, sim.limit("CP10"))
+
+	// half of the new EVs are nearly full and only take 6 A,
+	// which shows once they're given more, their share
+	// then going to the others
+	for i := 11; i <= 20; i++ {
+		cpID := fmt.Sprintf("CP%02d", i)
+		current := 32.0
+		if i%2 == 0 {
+			current = 6
+		}
+		transactions[cpID] = sim.plugIn(cpID, current)
+	}
+	sim.step()
+	assert.Equal(t, 10.0, sim.limit("CP12"))
+	assert.Equal(t, 10.0, sim.limit("CP13"))
+	for i := 0; i < 5; i++ {
+		sim.step()
+	}
+	// the five 6 A EVs keep 8 A, the fifteen others share 160 A
+	assert.Equal(t, 8.0, sim.limit("CP12"))
+	assert.Equal(t, 10.6, sim.limit("CP13"))
+	assert.Equal(t, 10.6, sim.limit("CP01"))
+
+	// half leave, the others get their 32 A back
+	for i := 1; i <= 20; i += 2 {
+		cpID := fmt.Sprintf("CP%02d", i)
+		sim.unplug(cpID, transactions[cpID])
+	}
+	for i := 0; i < 5; i++ {
+		sim.step()
+	}
+	assert.Len(t, sim.site.Sessions(), 10)
+	assert.Equal(t, 8.0, sim.limit("CP12"))
+	assert.Equal(t, 32.0, sim.limit("CP02"))
+
+	// limits are only sent when they change
+	sent := sim.chargePoints["CP02"].sent
+	sim.step()
+	assert.Equal(t, sent, sim.chargePoints["CP02"].sent)
+}
+
+func Test_SimulatedStrategies(t *testing.T) {
+	start := func(sim *simulation) {
+		for i := 1; i <= 4; i++ {
+			sim.plugIn(fmt.Sprintf("CP%02d", i), 32)
+		}
+		sim.site.SetPriority("CP04", 1, 1)
+		for i := 0; i < 3; i++ {
+			sim.step()
+		}
+	}
+
+	t.Run("FIFO", func(t *testing.T) {
+		sim := newSimulation(t, 80, 4, WithStrategy(FIFO()))
+		start(sim)
+		assert.Equal(t, []float64{32, 32, 10, 6}, []float64{sim.limit("CP01"), sim.limit("CP02"), sim.limit("CP03"), sim.limit("CP04")})
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		sim := newSimulation(t, 80, 4, WithStrategy(Priority()))
+		start(sim)
+		assert.Equal(t, []float64{32, 10, 6, 32}, []float64{sim.limit("CP01"), sim.limit("CP02"), sim.limit("CP03"), sim.limit("CP04")})
+	})
+
+	t.Run("equal share", func(t *testing.T) {
+		sim := newSimulation(t, 80, 4)
+		start(sim)
+		assert.Equal(t, []float64{20, 20, 20, 20}, []float64{sim.limit("CP01"), sim.limit("CP02"), sim.limit("CP03"), sim.limit("CP04")})
+	})
+}
+
+func Test_SiteOfflineChargePoint(t *testing.T) {
+	sim := newSimulation(t, 40, 2)
+	sim.plugIn("CP01", 32)
+	sim.plugIn("CP02", 32)
+	sim.chargePoints["CP02"].offline = true
+	err := sim.site.Balance(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 20.0, sim.limit("CP01"))
+
+	// the limit is sent again once it's back
+	sim.chargePoints["CP02"].offline = false
+	assert.NoError(t, sim.site.Balance(context.Background()))
+	assert.Equal(t, 20.0, sim.limit("CP02"))
+}
+
+func Test_SiteSilentChargePoint(t *testing.T) {
+	sim := newSimulation(t, 60, 3, WithSetTimeout(50*time.Millisecond))
+	sim.plugIn("CP01", 32)
+	sim.plugIn("CP02", 32)
+	sim.step()
+	assert.Equal(t, []float64{30, 30}, []float64{sim.limit("CP01"), sim.limit("CP02")})
+
+	// CP02 stops answering as a third EV arrives
+	sim.chargePoints["CP02"].mux.Lock()
+	sim.chargePoints["CP02"].silent = true
+	sim.chargePoints["CP02"].mux.Unlock()
+	sim.plugIn("CP03", 32)
+	begin := time.Now()
+	err := sim.site.Balance(context.Background())
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	assert.Less(t, int64(time.Since(begin)), int64(time.Second), "the charge points were waited for one after the other")
+	// the others are limited all the same
+	assert.Equal(t, []float64{20, 20}, []float64{sim.limit("CP01"), sim.limit("CP03")})
+}
+
+func Test_SiteIgnoresOtherChargePoints(t *testing.T) {
+	sim := newSimulation(t, 40, 1)
+	sim.site.StartTransaction("OTHER", 1, 1, time.Now())
+	assert.Empty(t, sim.site.Sessions())
+	sim.site.RemoveChargePoint("CP01")
+	sim.site.StartTransaction("CP01", 1, 2, time.Now())
+	assert.Empty(t, sim.site.Sessions())
+}
+
+func Test_SiteRun(t *testing.T) {
+	sim := newSimulation(t, 40, 2, WithInterval(time.Hour), WithStartDelay(50*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sim.site.Run(ctx)
+		close(done)
+	}()
+	// a new session is balanced without waiting for the interval,
+	// but once the charge point got the StartTransaction response
+	transactionID := sim.start("CP01", 32)
+	sim.answer("CP01", transactionID)
+	assert.Eventually(t, func() bool {
+		return sim.limit("CP01") == 32
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	assert.Equal(t, 0, sim.chargePoints["CP01"].rejected)
+}
+
+func Test_SiteUnknownTransaction(t *testing.T) {
+	sim := newSimulation(t, 60, 4)
+	tx1 := sim.plugIn("CP01", 32)
+	tx2 := sim.plugIn("CP02", 32)
+	sim.plugIn("CP03", 32)
+	sim.step()
+	assert.Equal(t, 20.0, sim.limit("CP03"))
+
+	// two leave as another arrives, balanced before it's answered
+	sim.unplug("CP01", tx1)
+	sim.unplug("CP02", tx2)
+	tx4 := sim.start("CP04", 32)
+	err := sim.site.Balance(context.Background())
+	assert.True(t, errors.Is(err, smartcharging.ErrProfileRejected), "unexpected error: %v", err)
+	assert.Equal(t, 1, sim.chargePoints["CP04"].rejected)
+	// the rejected first limit doesn't hold the raised ones
+	assert.Equal(t, 30.0, sim.limit("CP03"))
+
+	sim.answer("CP04", tx4)
+	assert.NoError(t, sim.site.Balance(context.Background()))
+	assert.Equal(t, 30.0, sim.limit("CP04"))
+}
+
+func Test_SiteMeterValuesInPower(t *testing.T) {
+	sim := newSimulation(t, 40, 1)
+	sim.site.StartTransaction("CP01", 1, 1, time.Now())
+	sim.site.MeterValues("CP01", &cpreq.MeterValues{ConnectorId: 1, MeterValue: []*cpreq.MeterValueItems{{
+		Timestamp: time.Now(),
+		SampledValues: []*cpreq.SampledValue{
+			{Measurand: enums.MeasurandEnergyActiveImportRegister, Unit: enums.UnitOfMeasureWh, Value: "12345"},
+			{Measurand: enums.MeasurandPowerActiveImport, Unit: enums.UnitOfMeasureKW, Value: "6.9"},
+		},
+	}}})
+	assert.Equal(t, 10.0, sim.site.Sessions()[0].Current)
+}
@@ -0,0 +1,134 @@
+package loadbalancing
+
+import (
+	"math"
+	"sort"
+)
+
+// Strategy allocates the capacity of the site, in A per phase, between
+// the sessions. The limits are in the order of the sessions, and each is
+// either 0, pausing the session, or at least its MinCurrent.
+type Strategy interface {
+	Allocate(capacity float64, sessions []Session) []float64
+}
+
+// StrategyFunc is a function allocating the capacity
+type StrategyFunc func(capacity float64, sessions []Session) []float64
+
+func (f StrategyFunc) Allocate(capacity float64, sessions []Session) []float64 {
+	return f(capacity, sessions)
+}
+
+// EqualShare shares the capacity equally between the sessions, the
+// capacity a session doesn't demand going to the others. When it can't
+// give every session its minimum, the first started ones are served.
+func EqualShare() Strategy {
+	return StrategyFunc(func(capacity float64, sessions []Session) []float64 {
+		limits := make([]float64, len(sessions))
+		served := reserve(capacity, sessions, byStart(sessions), limits)
+		remaining := capacity
+		for _, i := range served {
+			remaining -= limits[i]
+		}
+		// water-fill: the sessions demanding less than an equal share
+		// are given their demand, the others share what's left
+		for len(served) > 0 {
+			share := remaining / float64(len(served))
+			var hungry []int
+			for _, i := range served {
+				if demand(sessions[i])-limits[i] <= share {
+					remaining -= demand(sessions[i]) - limits[i]
+					limits[i] = demand(sessions[i])
+				} else {
+					hungry = append(hungry, i)
+				}
+			}
+			if len(hungry) == len(served) {
+				for _, i := range hungry {
+					limits[i] += share
+				}
+				break
+			}
+			served = hungry
+		}
+		return rounded(limits)
+	})
+}
+
+// Priority serves the sessions of the highest Priority first, the first
+// started first among equals: each is given its minimum while the capacity
+// allows it, then what's left goes to them in the same order.
+func Priority() Strategy {
+	return StrategyFunc(func(capacity float64, sessions []Session) []float64 {
+		order := byStart(sessions)
+		sort.SliceStable(order, func(i, j int) bool {
+			return sessions[order[i]].Priority > sessions[order[j]].Priority
+		})
+		return inOrder(capacity, sessions, order)
+	})
+}
+
+// FIFO serves the first started sessions first: each is given its
+// minimum while the capacity allows it, then what's left goes to
+// them in the same order.
+func FIFO() Strategy {
+	return StrategyFunc(func(capacity float64, sessions []Session) []float64 {
+		return inOrder(capacity, sessions, byStart(sessions))
+	})
+}
+
+func inOrder(capacity float64, sessions []Session, order []int) []float64 {
+	limits := make([]float64, len(sessions))
+	served := reserve(capacity, sessions, order, limits)
+	remaining := capacity
+	for _, i := range served {
+		remaining -= limits[i]
+	}
+	for _, i := range served {
+		extra := math.Min(demand(sessions[i])-limits[i], remaining)
+		limits[i] += extra
+		remaining -= extra
+	}
+	return rounded(limits)
+}
+
+// reserve gives their minimum to the sessions, in order, while
+// the capacity allows it, and returns the ones served
+func reserve(capacity float64, sessions []Session, order []int, limits []float64) []int {
+	var served []int
+	for _, i := range order {
+		if sessions[i].MinCurrent > capacity {
+			continue
+		}
+		limits[i] = sessions[i].MinCurrent
+		capacity -= sessions[i].MinCurrent
+		served = append(served, i)
+	}
+	return served
+}
+
+// demand is what the session can take, at least its minimum
+func demand(session Session) float64 {
+	return math.Max(session.Demand, session.MinCurrent)
+}
+
+// byStart are the indexes of the sessions, the first started first
+func byStart(sessions []Session) []int {
+	order := make([]int, len(sessions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return sessions[order[i]].Start.Before(sessions[order[j]].Start)
+	})
+	return order
+}
+
+// rounded rounds the limits down to 0.1, the
+// precision of the charging schedule periods
+func rounded(limits []float64) []float64 {
+	for i, limit := range limits {
+		limits[i] = math.Floor(limit*10+1e-9) / 10
+	}
+	return limits
+}
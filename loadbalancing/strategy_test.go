@@ -0,0 +1,69 @@
+package loadbalancing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sessions started a minute apart, in order
+func sessions(demands ...float64) []Session {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	var list []Session
+	for i, demand := range demands {
+		list = append(list, Session{
+			ConnectorId: i + 1,
+			Start:       start.Add(time.Duration(i) * time.Minute),
+			MinCurrent:  6,
+			Demand:      demand,
+		})
+	}
+	return list
+}
+
+func Test_Strategies(t *testing.T) {
+	prioritized := sessions(32, 32, 32)
+	prioritized[2].Priority = 1
+
+	reversed := sessions(32, 32, 32)
+	for i := range reversed {
+		reversed[i].Start = reversed[i].Start.Add(-2 * time.Duration(i) * time.Minute)
+	}
+
+	cases := []struct {
+		Name     string
+		Strategy Strategy
+		Capacity float64
+		Sessions []Session
+		Limits   []float64
+	}{
+		{"equal share", EqualShare(), 60, sessions(32, 32, 32), []float64{20, 20, 20}},
+		{"equal share up to the demand", EqualShare(), 100, sessions(32, 32, 32), []float64{32, 32, 32}},
+		{"equal share of what's not demanded", EqualShare(), 60, sessions(10, 32, 32), []float64{10, 25, 25}},
+		{"equal share of a minimum demand", EqualShare(), 60, sessions(2, 32, 32), []float64{6, 27, 27}},
+		{"equal share rounded down", EqualShare(), 50, sessions(32, 32, 32), []float64{16.6, 16.6, 16.6}},
+		{"equal share below the minimums", EqualShare(), 13, sessions(32, 32, 32), []float64{6.5, 6.5, 0}},
+		{"equal share to the first started", EqualShare(), 13, reversed, []float64{0, 6.5, 6.5}},
+		{"equal share without capacity", EqualShare(), 5, sessions(32, 32), []float64{0, 0}},
+		{"FIFO", FIFO(), 60, sessions(32, 32, 32), []float64{32, 22, 6}},
+		{"FIFO up to the demand", FIFO(), 60, sessions(10, 32, 32), []float64{10, 32, 18}},
+		{"FIFO below the minimums", FIFO(), 13, sessions(32, 32, 32), []float64{7, 6, 0}},
+		{"FIFO to the first started", FIFO(), 40, reversed, []float64{6, 6, 28}},
+		{"priority", Priority(), 60, prioritized, []float64{22, 6, 32}},
+		{"priority below the minimums", Priority(), 12, prioritized, []float64{6, 0, 6}},
+		{"priority of equals by start", Priority(), 60, sessions(32, 32, 32), []float64{32, 22, 6}},
+		{"no session", EqualShare(), 60, nil, []float64{}},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			limits := c.Strategy.Allocate(c.Capacity, c.Sessions)
+			assert.Equal(t, c.Limits, limits)
+			total := 0.0
+			for _, limit := range limits {
+				total += limit
+			}
+			assert.True(t, total <= c.Capacity, "%v over %v", total, c.Capacity)
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package messages
 
+import "fmt"
+
 type Request interface {
 	IsRequest()
 	Action() string
@@ -9,3 +11,14 @@ type Request interface {
 type Response interface {
 	IsResponse()
 }
+
+// UnexpectedResponseError is returned by the typed clients
+// when the response isn't the one of the request
+type UnexpectedResponseError struct {
+	Action   string
+	Response Response
+}
+
+func (err *UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("unexpected response %T to %s", err.Response, err.Action)
+}
@@ -1,74 +1,79 @@
 package req
 
 import (
+	"sort"
+
+	"github.com/michaelbironneau/go-ocpp"
 	"github.com/michaelbironneau/go-ocpp/messages"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
 )
 
+// actions of each side, with the request they send for it
+var actions = map[ocpp.Side]map[string]func() messages.Request{
+	ocpp.ChargePoint: {
+		"Authorize":                     func() messages.Request { return &cpreq.Authorize{} },
+		"BootNotification":              func() messages.Request { return &cpreq.BootNotification{} },
+		"DataTransfer":                  func() messages.Request { return &cpreq.DataTransfer{} },
+		"DiagnosticsStatusNotification": func() messages.Request { return &cpreq.DiagnosticsStatusNotification{} },
+		"FirmwareStatusNotification":    func() messages.Request { return &cpreq.FirmwareStatusNotification{} },
+		"Heartbeat":                     func() messages.Request { return &cpreq.Heartbeat{} },
+		"MeterValues":                   func() messages.Request { return &cpreq.MeterValues{} },
+		"StartTransaction":              func() messages.Request { return &cpreq.StartTransaction{} },
+		"StatusNotification":            func() messages.Request { return &cpreq.StatusNotification{} },
+		"StopTransaction":               func() messages.Request { return &cpreq.StopTransaction{} },
+	},
+	ocpp.CentralSystem: {
+		"CancelReservation":      func() messages.Request { return &csreq.CancelReservation{} },
+		"ChangeAvailability":     func() messages.Request { return &csreq.ChangeAvailability{} },
+		"ChangeConfiguration":    func() messages.Request { return &csreq.ChangeConfiguration{} },
+		"ClearCache":             func() messages.Request { return &csreq.ClearCache{} },
+		"ClearChargingProfile":   func() messages.Request { return &csreq.ClearChargingProfile{} },
+		"DataTransfer":           func() messages.Request { return &csreq.DataTransfer{} },
+		"GetCompositeSchedule":   func() messages.Request { return &csreq.GetCompositeSchedule{} },
+		"GetConfiguration":       func() messages.Request { return &csreq.GetConfiguration{} },
+		"GetDiagnostics":         func() messages.Request { return &csreq.GetDiagnostics{} },
+		"GetLocalListVersion":    func() messages.Request { return &csreq.GetLocalListVersion{} },
+		"RemoteStartTransaction": func() messages.Request { return &csreq.RemoteStartTransaction{} },
+		"RemoteStopTransaction":  func() messages.Request { return &csreq.RemoteStopTransaction{} },
+		"ReserveNow":             func() messages.Request { return &csreq.ReserveNow{} },
+		"Reset":                  func() messages.Request { return &csreq.Reset{} },
+		"SendLocalList":          func() messages.Request { return &csreq.SendLocalList{} },
+		"SetChargingProfile":     func() messages.Request { return &csreq.SetChargingProfile{} },
+		"TriggerMessage":         func() messages.Request { return &csreq.TriggerMessage{} },
+		"UnlockConnector":        func() messages.Request { return &csreq.UnlockConnector{} },
+		"UpdateFirmware":         func() messages.Request { return &csreq.UpdateFirmware{} },
+	},
+}
+
+// FromActionName returns the request of the action, nil if it's
+// unknown or if it's DataTransfer, which both sides send
 func FromActionName(action string) messages.Request {
-	switch action {
-	case "Authorize":
-		return &cpreq.Authorize{}
-	case "BootNotification":
-		return &cpreq.BootNotification{}
-	// TODO: DataTransfer comes from both the CS and CP
-	// case "DataTransfer":
-	// 	return &cpreq.DataTransfer{}
-	case "DiagnosticsStatusNotification":
-		return &cpreq.DiagnosticsStatusNotification{}
-	case "FirmwareStatusNotification":
-		return &cpreq.FirmwareStatusNotification{}
-	case "Heartbeat":
-		return &cpreq.Heartbeat{}
-	case "MeterValues":
-		return &cpreq.MeterValues{}
-	case "StartTransaction":
-		return &cpreq.StartTransaction{}
-	case "StatusNotification":
-		return &cpreq.StatusNotification{}
-	case "StopTransaction":
-		return &cpreq.StopTransaction{}
+	if action == "DataTransfer" {
+		return nil
+	}
+	if req := FromSender(ocpp.ChargePoint, action); req != nil {
+		return req
+	}
+	return FromSender(ocpp.CentralSystem, action)
+}
+
+// FromSender returns the request of the action sent by the given side,
+// nil if the action is unknown or if that side doesn't send it
+func FromSender(sender ocpp.Side, action string) messages.Request {
+	newRequest, ok := actions[sender][action]
+	if !ok {
+		return nil
+	}
+	return newRequest()
+}
 
-	case "CancelReservation":
-		return &csreq.CancelReservation{}
-	case "ChangeAvailability":
-		return &csreq.ChangeAvailability{}
-	case "ChangeConfiguration":
-		return &csreq.ChangeConfiguration{}
-	case "ClearCache":
-		return &csreq.ClearCache{}
-	case "ClearChargingProfile":
-		return &csreq.ClearChargingProfile{}
-	// TODO: DataTransfer comes from both the CS and CP
-	// case "DataTransfer":
-	// 	return &csreq.DataTransfer{}
-	case "GetCompositeSchedule":
-		return &csreq.GetCompositeSchedule{}
-	case "GetConfiguration":
-		return &csreq.GetConfiguration{}
-	case "GetDiagnostics":
-		return &csreq.GetDiagnostics{}
-	case "GetLocalListVersion":
-		return &csreq.GetLocalListVersion{}
-	case "RemoteStartTransaction":
-		return &csreq.RemoteStartTransaction{}
-	case "RemoteStopTransaction":
-		return &csreq.RemoteStopTransaction{}
-	case "ReserveNow":
-		return &csreq.ReserveNow{}
-	case "Reset":
-		return &csreq.Reset{}
-	case "SendLocalList":
-		return &csreq.SendLocalList{}
-	case "TriggerMessage":
-		return &csreq.TriggerMessage{}
-	case "UnlockConnector":
-		return &csreq.UnlockConnector{}
-	case "UpdateFirmware":
-		return &csreq.UpdateFirmware{}
-	case "SetChargingProfile":
-		return &csreq.SetChargingProfile{}
+// Actions sent by the given side, sorted
+func Actions(sender ocpp.Side) []string {
+	names := make([]string, 0, len(actions[sender]))
+	for action := range actions[sender] {
+		names = append(names, action)
 	}
-	return nil
+	sort.Strings(names)
+	return names
 }
@@ -1,74 +1,27 @@
 package res
 
 import (
+	"github.com/michaelbironneau/go-ocpp"
 	"github.com/michaelbironneau/go-ocpp/messages"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/messages/req"
 )
 
+// FromActionName returns the response of the action, nil if it's
+// unknown or if it's DataTransfer, which both sides send
 func FromActionName(action string) messages.Response {
-	switch action {
-	case "CancelReservation":
-		return &csresp.CancelReservation{}
-	case "ChangeAvailability":
-		return &csresp.ChangeAvailability{}
-	case "ChangeConfiguration":
-		return &csresp.ChangeConfiguration{}
-	case "ClearCache":
-		return &csresp.ClearCache{}
-	case "ClearChargingProfile":
-		return &csresp.ClearChargingProfile{}
-	// TODO: DataTransfer comes from both the CS and CP
-	// case "DataTransfer":
-	// 	return &csresp.DataTransfer{}
-	case "GetCompositeSchedule":
-		return &csresp.GetCompositeSchedule{}
-	case "GetConfiguration":
-		return &csresp.GetConfiguration{}
-	case "GetDiagnostics":
-		return &csresp.GetDiagnostics{}
-	case "GetLocalListVersion":
-		return &csresp.GetLocalListVersion{}
-	case "RemoteStartTransaction":
-		return &csresp.RemoteStartTransaction{}
-	case "RemoteStopTransaction":
-		return &csresp.RemoteStopTransaction{}
-	case "ReserveNow":
-		return &csresp.ReserveNow{}
-	case "Reset":
-		return &csresp.Reset{}
-	case "SendLocalList":
-		return &csresp.SendLocalList{}
-	case "TriggerMessage":
-		return &csresp.TriggerMessage{}
-	case "UnlockConnector":
-		return &csresp.UnlockConnector{}
-	case "UpdateFirmware":
-		return &csresp.UpdateFirmware{}
-	case "SetChargingProfile":
-		return &csresp.SetChargingProfile{}
+	request := req.FromActionName(action)
+	if request == nil {
+		return nil
+	}
+	return request.GetResponse()
+}
 
-	case "Authorize":
-		return &cpresp.Authorize{}
-	case "BootNotification":
-		return &cpresp.BootNotification{}
-	// TODO: DataTransfer comes from both the CS and CP
-	// case "DataTransfer":
-	// 	return &cpresp.DataTransfer{}
-	case "DiagnosticsStatusNotification":
-		return &cpresp.DiagnosticsStatusNotification{}
-	case "FirmwareStatusNotification":
-		return &cpresp.FirmwareStatusNotification{}
-	case "Heartbeat":
-		return &cpresp.Heartbeat{}
-	case "MeterValues":
-		return &cpresp.MeterValues{}
-	case "StartTransaction":
-		return &cpresp.StartTransaction{}
-	case "StatusNotification":
-		return &cpresp.StatusNotification{}
-	case "StopTransaction":
-		return &cpresp.StopTransaction{}
+// FromSender returns the response to the action requested by the given
+// side, nil if the action is unknown or if that side doesn't send it
+func FromSender(sender ocpp.Side, action string) messages.Response {
+	request := req.FromSender(sender, action)
+	if request == nil {
+		return nil
 	}
-	return nil
+	return request.GetResponse()
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/michaelbironneau/go-ocpp/cpstatus"
+	"github.com/michaelbironneau/go-ocpp/enums"
 	"github.com/michaelbironneau/go-ocpp/messages"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
 )
@@ -48,7 +49,7 @@ type DiagnosticsStatusNotification struct {
 	chargepointRequest
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cs/2012/06/ diagnosticsStatusNotificationRequest"`
 
-	Status string `json:"status" xml:"status,omitempty"`
+	Status enums.DiagnosticsStatus `json:"status" xml:"status,omitempty"`
 }
 
 // FirmwareStatusNotification
@@ -56,7 +57,7 @@ type FirmwareStatusNotification struct {
 	chargepointRequest
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cs/2012/06/ firmwareStatusNotificationRequest"`
 
-	Status string `json:"status" xml:"status,omitempty"`
+	Status enums.FirmwareStatus `json:"status" xml:"status,omitempty"`
 }
 
 // Heartbeat
@@ -85,13 +86,13 @@ type MeterValues struct {
 
 // SampledValue
 type SampledValue struct {
-	Context   string `json:"context,omitempty" xml:"context,attr,omitempty"`
-	Format    string `json:"format,omitempty" xml:"format,attr,omitempty"`
-	Location  string `json:"location,omitempty" xml:"location,attr,omitempty"`
-	Measurand string `json:"measurand,omitempty" xml:"measurand,attr,omitempty"`
+	Context   enums.ReadingContext `json:"context,omitempty" xml:"context,attr,omitempty"`
+	Format    enums.ValueFormat    `json:"format,omitempty" xml:"format,attr,omitempty"`
+	Location  enums.Location       `json:"location,omitempty" xml:"location,attr,omitempty"`
+	Measurand enums.Measurand      `json:"measurand,omitempty" xml:"measurand,attr,omitempty"`
 	// Phase not present in OCPP v1.5
-	Phase string `json:"phase,omitempty" xml:"-"`
-	Unit  string `json:"unit,omitempty" xml:"unit,attr,omitempty"`
+	Phase enums.Phase         `json:"phase,omitempty" xml:"-"`
+	Unit  enums.UnitOfMeasure `json:"unit,omitempty" xml:"unit,attr,omitempty"`
 
 	Value string `json:"value" xml:",chardata"`
 }
@@ -113,13 +114,13 @@ type StatusNotification struct {
 	chargepointRequest
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cs/2012/06/ statusNotificationRequest"`
 
-	ConnectorId     int                `json:"connectorId" xml:"connectorId,omitempty"`
-	ErrorCode       cpstatus.ErrorCode `json:"errorCode" xml:"errorCode,omitempty"`
-	Info            string             `json:"info,omitempty" xml:"info,omitempty"`
-	Status          string             `json:"status" xml:"status,omitempty"`
-	Timestamp       *time.Time         `json:"timestamp,omitempty" xml:"timestamp,omitempty"`
-	VendorErrorCode string             `json:"vendorErrorCode,omitempty" xml:"vendorErrorCode,omitempty"`
-	VendorId        string             `json:"vendorId,omitempty" xml:"vendorId,omitempty"`
+	ConnectorId     int                     `json:"connectorId" xml:"connectorId,omitempty"`
+	ErrorCode       cpstatus.ErrorCode      `json:"errorCode" xml:"errorCode,omitempty"`
+	Info            string                  `json:"info,omitempty" xml:"info,omitempty"`
+	Status          enums.ChargePointStatus `json:"status" xml:"status,omitempty"`
+	Timestamp       *time.Time              `json:"timestamp,omitempty" xml:"timestamp,omitempty"`
+	VendorErrorCode string                  `json:"vendorErrorCode,omitempty" xml:"vendorErrorCode,omitempty"`
+	VendorId        string                  `json:"vendorId,omitempty" xml:"vendorId,omitempty"`
 }
 
 // StopTransaction
@@ -129,7 +130,7 @@ type StopTransaction struct {
 
 	IdTag           string                  `json:"idTag,omitempty" xml:"idTag,omitempty"`
 	MeterStop       int                     `json:"meterStop" xml:"meterStop,omitempty"`
-	Reason          string                  `json:"reason,omitempty" xml:"reason,omitempty"`
+	Reason          enums.Reason            `json:"reason,omitempty" xml:"reason,omitempty"`
 	Timestamp       time.Time               `json:"timestamp" xml:"timestamp,omitempty"`
 	TransactionData []*TransactionDataItems `json:"transactionData,omitempty" xml:"transactionData,omitempty"`
 	TransactionId   int                     `json:"transactionId" xml:"transactionId,omitempty"`
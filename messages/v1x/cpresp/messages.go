@@ -3,6 +3,8 @@ package cpresp
 import (
 	"encoding/xml"
 	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
 )
 
 // Authorize
@@ -18,8 +20,8 @@ type BootNotification struct {
 	chargepointResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cs/2012/06/ bootNotificationResponse"`
 
-	Status   string  `json:"status" xml:"status,omitempty"`
-	CurrentTime time.Time `json:"currentTime" xml:"currentTime,omitempty"`
+	Status      enums.RegistrationStatus `json:"status" xml:"status,omitempty"`
+	CurrentTime time.Time                `json:"currentTime" xml:"currentTime,omitempty"`
 
 	// Interval in 1.5 is heartbeatInterval
 	Interval float64 `json:"interval" xml:"heartbeatInterval,omitempty"`
@@ -30,8 +32,8 @@ type DataTransfer struct {
 	chargepointResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cs/2012/06/ dataTransferResponse"`
 
-	Data   string `json:"data, xml:"data,omitempty"omitempty"`
-	Status string `json:"status" xml:"status,omitempty"`
+	Data   string                   `json:"data,omitempty" xml:"data,omitempty"`
+	Status enums.DataTransferStatus `json:"status" xml:"status,omitempty"`
 }
 
 // DiagnosticsStatusNotification
@@ -56,9 +58,9 @@ type Heartbeat struct {
 
 // IdTagInfo
 type IdTagInfo struct {
-	ExpiryDate  *time.Time `json:"expiryDate,omitempty" xml:"expiryDate,omitempty"`
-	ParentIdTag string     `json:"parentIdTag,omitempty" xml:"parentIdTag,omitempty"`
-	Status      string     `json:"status" xml:"status,omitempty"`
+	ExpiryDate  *time.Time                `json:"expiryDate,omitempty" xml:"expiryDate,omitempty"`
+	ParentIdTag string                    `json:"parentIdTag,omitempty" xml:"parentIdTag,omitempty"`
+	Status      enums.AuthorizationStatus `json:"status" xml:"status,omitempty"`
 }
 
 // MeterValues
@@ -87,5 +89,5 @@ type StopTransaction struct {
 	chargepointResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cs/2012/06/ stopTransactionResponse"`
 
-	IdTagInfo *IdTagInfo `json:"idTagInfo, xml:"idTagInfo,omitempty"omitempty"`
+	IdTagInfo *IdTagInfo `json:"idTagInfo,omitempty" xml:"idTagInfo,omitempty"`
 }
@@ -0,0 +1,148 @@
+package csreq
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+)
+
+// ErrInvalidChargingProfile is wrapped by the errors of
+// a charging profile breaking the rules of OCPP 1.6
+var ErrInvalidChargingProfile = errors.New("invalid charging profile")
+
+const (
+	day  = 24 * 60 * 60
+	week = 7 * day
+)
+
+// Validate checks the profile against the rules of OCPP 1.6 which
+// don't depend on the request holding it, e.g. that a Recurring
+// profile has a recurrencyKind
+func (m *ChargingProfile) Validate() error {
+	if m == nil {
+		return fmt.Errorf("%w: no profile", ErrInvalidChargingProfile)
+	}
+	if !m.ChargingProfileKind.IsValid() {
+		return fmt.Errorf("%w: chargingProfileKind %q", ErrInvalidChargingProfile, m.ChargingProfileKind)
+	}
+	if !m.ChargingProfilePurpose.IsValid() {
+		return fmt.Errorf("%w: chargingProfilePurpose %q", ErrInvalidChargingProfile, m.ChargingProfilePurpose)
+	}
+	if m.StackLevel < 0 {
+		return fmt.Errorf("%w: negative stackLevel", ErrInvalidChargingProfile)
+	}
+	if m.TransactionId != 0 && m.ChargingProfilePurpose != enums.ChargingProfilePurposeTxProfile {
+		return fmt.Errorf("%w: transactionId of a %s", ErrInvalidChargingProfile, m.ChargingProfilePurpose)
+	}
+	if m.ValidFrom != nil && m.ValidTo != nil && !m.ValidFrom.Before(*m.ValidTo) {
+		return fmt.Errorf("%w: validFrom isn't before validTo", ErrInvalidChargingProfile)
+	}
+	if err := m.ChargingSchedule.Validate(); err != nil {
+		return err
+	}
+	switch m.ChargingProfileKind {
+	case enums.ChargingProfileKindRecurring:
+		var period int
+		switch m.RecurrencyKind {
+		case enums.RecurrencyKindDaily:
+			period = day
+		case enums.RecurrencyKindWeekly:
+			period = week
+		default:
+			return fmt.Errorf("%w: Recurring profile without recurrencyKind", ErrInvalidChargingProfile)
+		}
+		if m.ChargingSchedule.StartSchedule == nil {
+			return fmt.Errorf("%w: Recurring profile without startSchedule", ErrInvalidChargingProfile)
+		}
+		if m.ChargingSchedule.Duration > period {
+			return fmt.Errorf("%w: duration longer than its %s recurrency", ErrInvalidChargingProfile, m.RecurrencyKind)
+		}
+	case enums.ChargingProfileKindRelative:
+		if m.ChargingSchedule.StartSchedule != nil {
+			return fmt.Errorf("%w: Relative profile with a startSchedule", ErrInvalidChargingProfile)
+		}
+	}
+	if m.RecurrencyKind != "" && m.ChargingProfileKind != enums.ChargingProfileKindRecurring {
+		return fmt.Errorf("%w: recurrencyKind of a %s profile", ErrInvalidChargingProfile, m.ChargingProfileKind)
+	}
+	return nil
+}
+
+// Validate checks the schedule against the rules of OCPP 1.6,
+// e.g. that its periods start at 0 and in order
+func (m *ChargingSchedule) Validate() error {
+	if m == nil {
+		return fmt.Errorf("%w: no chargingSchedule", ErrInvalidChargingProfile)
+	}
+	if !m.ChargingRateUnit.IsValid() {
+		return fmt.Errorf("%w: chargingRateUnit %q", ErrInvalidChargingProfile, m.ChargingRateUnit)
+	}
+	if m.Duration < 0 {
+		return fmt.Errorf("%w: negative duration", ErrInvalidChargingProfile)
+	}
+	if m.MinChargingRate < 0 {
+		return fmt.Errorf("%w: negative minChargingRate", ErrInvalidChargingProfile)
+	}
+	if len(m.ChargingSchedulePeriod) == 0 {
+		return fmt.Errorf("%w: no chargingSchedulePeriod", ErrInvalidChargingProfile)
+	}
+	for i, period := range m.ChargingSchedulePeriod {
+		switch {
+		case period == nil:
+			return fmt.Errorf("%w: no chargingSchedulePeriod[%d]", ErrInvalidChargingProfile, i)
+		case i == 0 && period.StartPeriod != 0:
+			return fmt.Errorf("%w: first startPeriod isn't 0", ErrInvalidChargingProfile)
+		case i > 0 && period.StartPeriod <= m.ChargingSchedulePeriod[i-1].StartPeriod:
+			return fmt.Errorf("%w: chargingSchedulePeriod[%d] doesn't start after the previous one", ErrInvalidChargingProfile, i)
+		case m.Duration > 0 && period.StartPeriod >= m.Duration:
+			return fmt.Errorf("%w: chargingSchedulePeriod[%d] starts after the duration", ErrInvalidChargingProfile, i)
+		case period.Limit < 0:
+			return fmt.Errorf("%w: negative limit", ErrInvalidChargingProfile)
+		case period.NumberPhases < 0 || period.NumberPhases > 3:
+			return fmt.Errorf("%w: numberPhases %d", ErrInvalidChargingProfile, period.NumberPhases)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the profile, if any, is a TxProfile
+// without transactionId, the transaction being yet to start
+func (m *RemoteStartTransaction) Validate() error {
+	if m.ChargingProfile == nil {
+		return nil
+	}
+	if m.ChargingProfile.ChargingProfilePurpose != enums.ChargingProfilePurposeTxProfile {
+		return fmt.Errorf("%w: %s of a RemoteStartTransaction", ErrInvalidChargingProfile, m.ChargingProfile.ChargingProfilePurpose)
+	}
+	if m.ChargingProfile.TransactionId != 0 {
+		return fmt.Errorf("%w: transactionId of a RemoteStartTransaction", ErrInvalidChargingProfile)
+	}
+	return m.ChargingProfile.Validate()
+}
+
+// Validate checks the profile and that it fits the connector: a
+// TxProfile is for the transaction of a connector and a
+// ChargePointMaxProfile is for the whole charge point
+func (m *SetChargingProfile) Validate() error {
+	if err := m.CsChargingProfiles.Validate(); err != nil {
+		return err
+	}
+	if m.ConnectorId < 0 {
+		return fmt.Errorf("%w: negative connectorId", ErrInvalidChargingProfile)
+	}
+	switch m.CsChargingProfiles.ChargingProfilePurpose {
+	case enums.ChargingProfilePurposeTxProfile:
+		if m.ConnectorId == 0 {
+			return fmt.Errorf("%w: TxProfile for connector 0", ErrInvalidChargingProfile)
+		}
+		if m.CsChargingProfiles.TransactionId == 0 {
+			return fmt.Errorf("%w: TxProfile without transactionId", ErrInvalidChargingProfile)
+		}
+	case enums.ChargingProfilePurposeChargePointMaxProfile:
+		if m.ConnectorId != 0 {
+			return fmt.Errorf("%w: ChargePointMaxProfile for connector %d", ErrInvalidChargingProfile, m.ConnectorId)
+		}
+	}
+	return nil
+}
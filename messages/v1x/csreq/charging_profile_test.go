@@ -0,0 +1,116 @@
+package csreq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func txProfile() *ChargingProfile {
+	return &ChargingProfile{
+		ChargingProfileId:      1,
+		ChargingProfileKind:    enums.ChargingProfileKindRelative,
+		ChargingProfilePurpose: enums.ChargingProfilePurposeTxProfile,
+		TransactionId:          42,
+		ChargingSchedule: &ChargingSchedule{
+			ChargingRateUnit: enums.ChargingRateUnitA,
+			ChargingSchedulePeriod: []*ChargingSchedulePeriodItems{
+				{StartPeriod: 0, Limit: 16, NumberPhases: 3},
+				{StartPeriod: 3600, Limit: 8},
+			},
+		},
+	}
+}
+
+func Test_SetChargingProfileValidate(t *testing.T) {
+	start := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	cases := []struct {
+		Name   string
+		Modify func(m *SetChargingProfile)
+		Valid  bool
+	}{
+		{"valid TxProfile", func(m *SetChargingProfile) {}, true},
+		{"TxProfile without transactionId", func(m *SetChargingProfile) { m.CsChargingProfiles.TransactionId = 0 }, false},
+		{"TxProfile for connector 0", func(m *SetChargingProfile) { m.ConnectorId = 0 }, false},
+		{"transactionId of a TxDefaultProfile", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingProfilePurpose = enums.ChargingProfilePurposeTxDefaultProfile
+		}, false},
+		{"ChargePointMaxProfile for a connector", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingProfilePurpose = enums.ChargingProfilePurposeChargePointMaxProfile
+			m.CsChargingProfiles.TransactionId = 0
+		}, false},
+		{"Recurring without recurrencyKind", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingProfileKind = enums.ChargingProfileKindRecurring
+			m.CsChargingProfiles.ChargingSchedule.StartSchedule = &start
+		}, false},
+		{"daily Recurring", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingProfileKind = enums.ChargingProfileKindRecurring
+			m.CsChargingProfiles.RecurrencyKind = enums.RecurrencyKindDaily
+			m.CsChargingProfiles.ChargingSchedule.StartSchedule = &start
+			m.CsChargingProfiles.ChargingSchedule.Duration = 86400
+		}, true},
+		{"daily Recurring longer than a day", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingProfileKind = enums.ChargingProfileKindRecurring
+			m.CsChargingProfiles.RecurrencyKind = enums.RecurrencyKindDaily
+			m.CsChargingProfiles.ChargingSchedule.StartSchedule = &start
+			m.CsChargingProfiles.ChargingSchedule.Duration = 86401
+		}, false},
+		{"recurrencyKind of an Absolute profile", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingProfileKind = enums.ChargingProfileKindAbsolute
+			m.CsChargingProfiles.RecurrencyKind = enums.RecurrencyKindWeekly
+		}, false},
+		{"Relative with a startSchedule", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingSchedule.StartSchedule = &start
+		}, false},
+		{"first period not at 0", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingSchedule.ChargingSchedulePeriod[0].StartPeriod = 60
+		}, false},
+		{"periods out of order", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingSchedule.ChargingSchedulePeriod[1].StartPeriod = 0
+		}, false},
+		{"too many phases", func(m *SetChargingProfile) {
+			m.CsChargingProfiles.ChargingSchedule.ChargingSchedulePeriod[0].NumberPhases = 4
+		}, false},
+		{"no schedule", func(m *SetChargingProfile) { m.CsChargingProfiles.ChargingSchedule = nil }, false},
+		{"no profile", func(m *SetChargingProfile) { m.CsChargingProfiles = nil }, false},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			m := &SetChargingProfile{ConnectorId: 1, CsChargingProfiles: txProfile()}
+			c.Modify(m)
+			err := m.Validate()
+			if c.Valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidChargingProfile), "unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func Test_RemoteStartTransactionValidate(t *testing.T) {
+	m := &RemoteStartTransaction{IdTag: "VIRTUAL"}
+	assert.NoError(t, m.Validate())
+	m.ChargingProfile = txProfile()
+	assert.True(t, errors.Is(m.Validate(), ErrInvalidChargingProfile), "transactionId of a transaction yet to start")
+	m.ChargingProfile.TransactionId = 0
+	assert.NoError(t, m.Validate())
+	m.ChargingProfile.ChargingProfilePurpose = enums.ChargingProfilePurposeTxDefaultProfile
+	assert.True(t, errors.Is(m.Validate(), ErrInvalidChargingProfile), "not a TxProfile")
+}
+
+func Test_ChargingProfileJSON(t *testing.T) {
+	m := &SetChargingProfile{ConnectorId: 1, CsChargingProfiles: txProfile()}
+	raw, err := json.Marshal(m)
+	assert.NoError(t, err)
+	var decoded SetChargingProfile
+	assert.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, m.CsChargingProfiles, decoded.CsChargingProfiles)
+	assert.Contains(t, string(raw), `"transactionId":42`)
+	assert.Contains(t, string(raw), `"numberPhases":3`)
+	assert.NotContains(t, string(raw), `validFrom`)
+}
@@ -198,121 +198,6 @@ func (m *ChangeConfiguration) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-/*func (m *ChargingProfile) MarshalJSON() ([]byte, error) {
-	buf := bytes.NewBuffer(make([]byte, 0))
-	buf.WriteString("{")
-	comma := false
-	// "ChargingProfileId" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "chargingProfileId" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingProfileId\": ")
-	if tmp, err := json.Marshal(m.ChargingProfileId); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "ChargingProfileKind" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "chargingProfileKind" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingProfileKind\": ")
-	if tmp, err := json.Marshal(m.ChargingProfileKind); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "ChargingProfilePurpose" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "chargingProfilePurpose" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingProfilePurpose\": ")
-	if tmp, err := json.Marshal(m.ChargingProfilePurpose); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "ChargingSchedule" field is required
-	if m.ChargingSchedule == nil {
-		return nil, errors.New("chargingSchedule is a required field")
-	}
-	// Marshal the "chargingSchedule" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingSchedule\": ")
-	if tmp, err := json.Marshal(m.ChargingSchedule); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "recurrencyKind" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"recurrencyKind\": ")
-	if tmp, err := json.Marshal(m.RecurrencyKind); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "StackLevel" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "stackLevel" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"stackLevel\": ")
-	if tmp, err := json.Marshal(m.StackLevel); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "transactionId" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"transactionId\": ")
-	if tmp, err := json.Marshal(m.TransactionId); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	if m.ValidFrom != nil {
-		buf.WriteString(",")
-		buf.WriteString("\"validFrom\": ")
-		if tmp, err := json.Marshal(m.ValidFrom.Format(time.RFC3339)); err != nil {
-			return nil, err
-		} else {
-			buf.Write(tmp)
-		}
-	}
-	if m.ValidTo != nil {
-		buf.WriteString(",")
-		buf.WriteString("\"validTo\": ")
-		if tmp, err := json.Marshal(m.ValidTo.Format(time.RFC3339)); err != nil {
-			return nil, err
-		} else {
-			buf.Write(tmp)
-		}
-	}
-	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
-}*/
-
 func (m *ChargingProfile) UnmarshalJSON(b []byte) error {
 	chargingProfileIdReceived := false
 	chargingProfileKindReceived := false
@@ -346,19 +231,19 @@ func (m *ChargingProfile) UnmarshalJSON(b []byte) error {
 				return err
 			}
 			chargingScheduleReceived = true
-		//case "recurrencyKind":
-		//	if err := json.Unmarshal([]byte(v), &m.RecurrencyKind); err != nil {
-		//		return err
-		//	}
+		case "recurrencyKind":
+			if err := json.Unmarshal([]byte(v), &m.RecurrencyKind); err != nil {
+				return err
+			}
 		case "stackLevel":
 			if err := json.Unmarshal([]byte(v), &m.StackLevel); err != nil {
 				return err
 			}
 			stackLevelReceived = true
-		//case "transactionId":
-		//	if err := json.Unmarshal([]byte(v), &m.TransactionId); err != nil {
-		//		return err
-		//	}
+		case "transactionId":
+			if err := json.Unmarshal([]byte(v), &m.TransactionId); err != nil {
+				return err
+			}
 		case "validFrom":
 			if err := json.Unmarshal([]byte(v), &m.ValidFrom); err != nil {
 				return err
@@ -821,43 +706,33 @@ func (m *GetLocalListVersion) UnmarshalJSON(b []byte) error {
 func (m *IdTagInfo) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
-	comma := false
-	// Marshal the "expiryDate" field
-	if comma {
+	if m.ExpiryDate != nil {
+		buf.WriteString("\"expiryDate\": ")
+		if tmp, err := json.Marshal(m.ExpiryDate.Format(time.RFC3339)); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
 		buf.WriteString(",")
 	}
-	buf.WriteString("\"expiryDate\": ")
-	if tmp, err := json.Marshal(m.ExpiryDate.Format(time.RFC3339)); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "parentIdTag" field
-	if comma {
+	if m.ParentIdTag != "" {
+		buf.WriteString("\"parentIdTag\": ")
+		if tmp, err := json.Marshal(m.ParentIdTag); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
 		buf.WriteString(",")
 	}
-	buf.WriteString("\"parentIdTag\": ")
-	if tmp, err := json.Marshal(m.ParentIdTag); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
 	// "Status" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "status" field
-	if comma {
-		buf.WriteString(",")
-	}
 	buf.WriteString("\"status\": ")
 	if tmp, err := json.Marshal(m.Status); err != nil {
 		return nil, err
 	} else {
 		buf.Write(tmp)
 	}
-	comma = true
-
 	buf.WriteString("}")
 	rv := buf.Bytes()
 	return rv, nil
@@ -872,6 +747,14 @@ func (m *IdTagInfo) UnmarshalJSON(b []byte) error {
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "expiryDate":
+			if err := json.Unmarshal([]byte(v), &m.ExpiryDate); err != nil {
+				return err
+			}
+		case "parentIdTag":
+			if err := json.Unmarshal([]byte(v), &m.ParentIdTag); err != nil {
+				return err
+			}
 		case "status":
 			if err := json.Unmarshal([]byte(v), &m.Status); err != nil {
 				return err
@@ -1276,71 +1159,6 @@ func (m *TriggerMessage) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-/*func (m *ChargingSchedule) MarshalJSON() ([]byte, error) {
-	buf := bytes.NewBuffer(make([]byte, 0))
-	buf.WriteString("{")
-	comma := false
-	// "ChargingRateUnit" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "chargingRateUnit" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingRateUnit\": ")
-	if tmp, err := json.Marshal(m.ChargingRateUnit); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "ChargingSchedulePeriod" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "chargingSchedulePeriod" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingSchedulePeriod\": ")
-	if tmp, err := json.Marshal(m.ChargingSchedulePeriod); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "duration" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"duration\": ")
-	if tmp, err := json.Marshal(m.Duration); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "minChargingRate" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"minChargingRate\": ")
-	if tmp, err := json.Marshal(m.MinChargingRate); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	if m.StartSchedule != nil {
-		buf.WriteString(",")
-		buf.WriteString("\"startSchedule\": ")
-		if tmp, err := json.Marshal(m.StartSchedule.Format(time.RFC3339)); err != nil {
-			return nil, err
-		} else {
-			buf.Write(tmp)
-		}
-	}
-	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
-}*/
-
 func (m *ChargingSchedule) UnmarshalJSON(b []byte) error {
 	chargingRateUnitReceived := false
 	chargingSchedulePeriodReceived := false
@@ -1361,14 +1179,14 @@ func (m *ChargingSchedule) UnmarshalJSON(b []byte) error {
 				return err
 			}
 			chargingSchedulePeriodReceived = true
-		//case "duration":
-		//	if err := json.Unmarshal([]byte(v), &m.Duration); err != nil {
-		//		return err
-		//	}
-		//case "minChargingRate":
-		//	if err := json.Unmarshal([]byte(v), &m.MinChargingRate); err != nil {
-		//		return err
-		//	}
+		case "duration":
+			if err := json.Unmarshal([]byte(v), &m.Duration); err != nil {
+				return err
+			}
+		case "minChargingRate":
+			if err := json.Unmarshal([]byte(v), &m.MinChargingRate); err != nil {
+				return err
+			}
 		case "startSchedule":
 			if err := json.Unmarshal([]byte(v), &m.StartSchedule); err != nil {
 				return err
@@ -1386,53 +1204,6 @@ func (m *ChargingSchedule) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-/*func (m *ChargingSchedulePeriodItems) MarshalJSON() ([]byte, error) {
-	buf := bytes.NewBuffer(make([]byte, 0))
-	buf.WriteString("{")
-	comma := false
-	// "Limit" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "limit" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"limit\": ")
-	if tmp, err := json.Marshal(m.Limit); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "numberPhases" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"numberPhases\": ")
-	if tmp, err := json.Marshal(m.NumberPhases); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "StartPeriod" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "startPeriod" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"startPeriod\": ")
-	if tmp, err := json.Marshal(m.StartPeriod); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-
-	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
-}*/
-
 func (m *ChargingSchedulePeriodItems) UnmarshalJSON(b []byte) error {
 	limitReceived := false
 	startPeriodReceived := false
@@ -1448,10 +1219,10 @@ func (m *ChargingSchedulePeriodItems) UnmarshalJSON(b []byte) error {
 				return err
 			}
 			limitReceived = true
-		//case "numberPhases":
-		//	if err := json.Unmarshal([]byte(v), &m.NumberPhases); err != nil {
-		//		return err
-		//	}
+		case "numberPhases":
+			if err := json.Unmarshal([]byte(v), &m.NumberPhases); err != nil {
+				return err
+			}
 		case "startPeriod":
 			if err := json.Unmarshal([]byte(v), &m.StartPeriod); err != nil {
 				return err
@@ -1470,206 +1241,6 @@ func (m *ChargingSchedulePeriodItems) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-func (m *CsChargingProfiles) MarshalJSON() ([]byte, error) {
-	buf := bytes.NewBuffer(make([]byte, 0))
-	buf.WriteString("{")
-	comma := false
-	// "ChargingProfileId" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "chargingProfileId" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingProfileId\": ")
-	if tmp, err := json.Marshal(m.ChargingProfileId); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "ChargingProfileKind" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "chargingProfileKind" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingProfileKind\": ")
-	if tmp, err := json.Marshal(m.ChargingProfileKind); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "ChargingProfilePurpose" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "chargingProfilePurpose" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingProfilePurpose\": ")
-	if tmp, err := json.Marshal(m.ChargingProfilePurpose); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "ChargingSchedule" field is required
-	if m.ChargingSchedule == nil {
-		return nil, errors.New("chargingSchedule is a required field")
-	}
-	// Marshal the "chargingSchedule" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"chargingSchedule\": ")
-	if tmp, err := json.Marshal(m.ChargingSchedule); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "recurrencyKind" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"recurrencyKind\": ")
-	if tmp, err := json.Marshal(m.RecurrencyKind); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// "StackLevel" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "stackLevel" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"stackLevel\": ")
-	if tmp, err := json.Marshal(m.StackLevel); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "transactionId" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"transactionId\": ")
-	if tmp, err := json.Marshal(m.TransactionId); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "validFrom" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"validFrom\": ")
-	if tmp, err := json.Marshal(m.ValidFrom.Format(time.RFC3339)); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "validTo" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"validTo\": ")
-	if tmp, err := json.Marshal(m.ValidTo.Format(time.RFC3339)); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-
-	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
-}
-
-func (m *CsChargingProfiles) UnmarshalJSON(b []byte) error {
-	chargingProfileIdReceived := false
-	chargingProfileKindReceived := false
-	chargingProfilePurposeReceived := false
-	chargingScheduleReceived := false
-	stackLevelReceived := false
-	var jsonMap map[string]json.RawMessage
-	if err := json.Unmarshal(b, &jsonMap); err != nil {
-		return err
-	}
-	// parse all the defined properties
-	for k, v := range jsonMap {
-		switch k {
-		case "chargingProfileId":
-			if err := json.Unmarshal([]byte(v), &m.ChargingProfileId); err != nil {
-				return err
-			}
-			chargingProfileIdReceived = true
-		case "chargingProfileKind":
-			if err := json.Unmarshal([]byte(v), &m.ChargingProfileKind); err != nil {
-				return err
-			}
-			chargingProfileKindReceived = true
-		case "chargingProfilePurpose":
-			if err := json.Unmarshal([]byte(v), &m.ChargingProfilePurpose); err != nil {
-				return err
-			}
-			chargingProfilePurposeReceived = true
-		case "chargingSchedule":
-			if err := json.Unmarshal([]byte(v), &m.ChargingSchedule); err != nil {
-				return err
-			}
-			chargingScheduleReceived = true
-		case "recurrencyKind":
-			if err := json.Unmarshal([]byte(v), &m.RecurrencyKind); err != nil {
-				return err
-			}
-		case "stackLevel":
-			if err := json.Unmarshal([]byte(v), &m.StackLevel); err != nil {
-				return err
-			}
-			stackLevelReceived = true
-		case "transactionId":
-			if err := json.Unmarshal([]byte(v), &m.TransactionId); err != nil {
-				return err
-			}
-		case "validFrom":
-			if err := json.Unmarshal([]byte(v), &m.ValidFrom); err != nil {
-				return err
-			}
-		case "validTo":
-			if err := json.Unmarshal([]byte(v), &m.ValidTo); err != nil {
-				return err
-			}
-		}
-	}
-	// check if chargingProfileId (a required property) was received
-	if !chargingProfileIdReceived {
-		return errors.New("\"chargingProfileId\" is required but was not present")
-	}
-	// check if chargingProfileKind (a required property) was received
-	if !chargingProfileKindReceived {
-		return errors.New("\"chargingProfileKind\" is required but was not present")
-	}
-	// check if chargingProfilePurpose (a required property) was received
-	if !chargingProfilePurposeReceived {
-		return errors.New("\"chargingProfilePurpose\" is required but was not present")
-	}
-	// check if chargingSchedule (a required property) was received
-	if !chargingScheduleReceived {
-		return errors.New("\"chargingSchedule\" is required but was not present")
-	}
-	// check if stackLevel (a required property) was received
-	if !stackLevelReceived {
-		return errors.New("\"stackLevel\" is required but was not present")
-	}
-	return nil
-}
-
 func (m *SetChargingProfile) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
@@ -0,0 +1,25 @@
+package csreq
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IdTagInfoJSON(t *testing.T) {
+	expiry := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	cases := []*IdTagInfo{
+		{Status: enums.AuthorizationStatusAccepted},
+		{Status: enums.AuthorizationStatusAccepted, ExpiryDate: &expiry, ParentIdTag: "FLEET"},
+	}
+	for _, info := range cases {
+		b, err := json.Marshal(info)
+		assert.NoError(t, err)
+		decoded := &IdTagInfo{}
+		assert.NoError(t, json.Unmarshal(b, decoded))
+		assert.Equal(t, info, decoded)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/xml"
 	"time"
 
+	"github.com/michaelbironneau/go-ocpp/enums"
+
 	"github.com/michaelbironneau/go-ocpp/messages"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
 )
@@ -21,8 +23,8 @@ type ChangeAvailability struct {
 	centralSystemRequest
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ changeAvailabilityRequest"`
 
-	ConnectorId int    `json:"connectorId" xml:"connectorId"`
-	Type        string `json:"type" xml:"type"`
+	ConnectorId int                    `json:"connectorId" xml:"connectorId"`
+	Type        enums.AvailabilityType `json:"type" xml:"type"`
 }
 
 // ChangeConfiguration
@@ -34,32 +36,33 @@ type ChangeConfiguration struct {
 	Value string `json:"value" xml:"value"`
 }
 
-// ChargingProfile
+// ChargingProfile is the charging profile of the RemoteStartTransaction
+// and SetChargingProfile requests
 type ChargingProfile struct {
-	ChargingProfileId      int               `json:"chargingProfileId" xml:"chargingProfileId"`
-	ChargingProfileKind    string            `json:"chargingProfileKind" xml:"chargingProfileKind"`
-	ChargingProfilePurpose string            `json:"chargingProfilePurpose" xml:"chargingProfilePurpose"`
-	ChargingSchedule       *ChargingSchedule `json:"chargingSchedule" xml:"chargingSchedule"`
-	//RecurrencyKind         string            `json:"-"`//`json:"recurrencyKind,omitempty" xml:"recurrencyKind,omitempty"`
-	StackLevel             int               `json:"stackLevel" xml:"stackLevel"`
-	//TransactionId          int               `json:"transactionId,omitempty" xml:"transactionId,omitempty"`
-	ValidFrom              *time.Time        `json:"validFrom,omitempty" xml:"validFrom,omitempty"`
-	ValidTo                *time.Time        `json:"validTo,omitempty" xml:"validTo,omitempty"`
+	ChargingProfileId      int                          `json:"chargingProfileId" xml:"chargingProfileId"`
+	ChargingProfileKind    enums.ChargingProfileKind    `json:"chargingProfileKind" xml:"chargingProfileKind"`
+	ChargingProfilePurpose enums.ChargingProfilePurpose `json:"chargingProfilePurpose" xml:"chargingProfilePurpose"`
+	ChargingSchedule       *ChargingSchedule            `json:"chargingSchedule" xml:"chargingSchedule"`
+	RecurrencyKind         enums.RecurrencyKind         `json:"recurrencyKind,omitempty" xml:"recurrencyKind,omitempty"`
+	StackLevel             int                          `json:"stackLevel" xml:"stackLevel"`
+	TransactionId          int32                        `json:"transactionId,omitempty" xml:"transactionId,omitempty"`
+	ValidFrom              *time.Time                   `json:"validFrom,omitempty" xml:"validFrom,omitempty"`
+	ValidTo                *time.Time                   `json:"validTo,omitempty" xml:"validTo,omitempty"`
 }
 
 // ChargingSchedule
 type ChargingSchedule struct {
-	ChargingRateUnit       string                         `json:"chargingRateUnit" xml:"chargingRateUnit"`
+	ChargingRateUnit       enums.ChargingRateUnit         `json:"chargingRateUnit" xml:"chargingRateUnit"`
 	ChargingSchedulePeriod []*ChargingSchedulePeriodItems `json:"chargingSchedulePeriod" xml:"chargingSchedulePeriod"`
-	//Duration               int                            `json:"-"` //`json:"duration,omitempty" xml:"duration,omitempty"`
-	//MinChargingRate        float64                        `json:"-"` //`json:"minChargingRate,omitempty" xml:"minChargingRate,omitempty"`
+	Duration               int                            `json:"duration,omitempty" xml:"duration,omitempty"`
+	MinChargingRate        float64                        `json:"minChargingRate,omitempty" xml:"minChargingRate,omitempty"`
 	StartSchedule          *time.Time                     `json:"startSchedule,omitempty" xml:"startSchedule,omitempty"`
 }
 
 // ChargingSchedulePeriodItems
 type ChargingSchedulePeriodItems struct {
 	Limit        float64 `json:"limit" xml:"limit"`
-	//NumberPhases int     `json:"-"` //`json:"numberPhases,omitempty" xml:"numberPhases,omitempty"`
+	NumberPhases int     `json:"numberPhases,omitempty" xml:"numberPhases,omitempty"`
 	StartPeriod  int     `json:"startPeriod" xml:"startPeriod"`
 }
 
@@ -74,10 +77,10 @@ type ClearChargingProfile struct {
 	centralSystemRequest
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ clearChargingProfileRequest"`
 
-	ChargingProfilePurpose string `json:"chargingProfilePurpose,omitempty" xml:"chargingProfilePurpose,omitempty"`
-	ConnectorId            int    `json:"connectorId,omitempty" xml:"connectorId,omitempty"`
-	Id                     int    `json:"id,omitempty" xml:"id,omitempty"`
-	StackLevel             int    `json:"stackLevel,omitempty" xml:"stackLevel,omitempty"`
+	ChargingProfilePurpose enums.ChargingProfilePurpose `json:"chargingProfilePurpose,omitempty" xml:"chargingProfilePurpose,omitempty"`
+	ConnectorId            int                          `json:"connectorId,omitempty" xml:"connectorId,omitempty"`
+	Id                     int                          `json:"id,omitempty" xml:"id,omitempty"`
+	StackLevel             int                          `json:"stackLevel,omitempty" xml:"stackLevel,omitempty"`
 }
 
 // DataTransfer
@@ -95,9 +98,9 @@ type GetCompositeSchedule struct {
 	centralSystemRequest
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ getCompositeScheduleRequest"`
 
-	ChargingRateUnit string `json:"chargingRateUnit,omitempty" xml:"chargingRateUnit,omitempty"`
-	ConnectorId      int    `json:"connectorId" xml:"connectorId"`
-	Duration         int    `json:"duration" xml:"duration"`
+	ChargingRateUnit enums.ChargingRateUnit `json:"chargingRateUnit,omitempty" xml:"chargingRateUnit,omitempty"`
+	ConnectorId      int                    `json:"connectorId" xml:"connectorId"`
+	Duration         int                    `json:"duration" xml:"duration"`
 }
 
 // GetConfiguration
@@ -128,9 +131,9 @@ type GetLocalListVersion struct {
 
 // IdTagInfo
 type IdTagInfo struct {
-	ExpiryDate  *time.Time `json:"expiryDate,omitempty" xml:"expiryDate,omitempty"`
-	ParentIdTag string     `json:"parentIdTag,omitempty" xml:"parentIdTag,omitempty"`
-	Status      string     `json:"status" xml:"status"`
+	ExpiryDate  *time.Time                `json:"expiryDate,omitempty" xml:"expiryDate,omitempty"`
+	ParentIdTag string                    `json:"parentIdTag,omitempty" xml:"parentIdTag,omitempty"`
+	Status      enums.AuthorizationStatus `json:"status" xml:"status"`
 }
 
 // LocalAuthorizationListItems
@@ -174,7 +177,7 @@ type Reset struct {
 	centralSystemRequest
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ resetRequest"`
 
-	Type string `json:"type" xml:"type"`
+	Type enums.ResetType `json:"type" xml:"type"`
 }
 
 // SendLocalList
@@ -184,7 +187,7 @@ type SendLocalList struct {
 
 	ListVersion            int                            `json:"listVersion" xml:"listVersion"`
 	LocalAuthorizationList []*LocalAuthorizationListItems `json:"localAuthorizationList,omitempty" xml:"localAuthorizationList,omitempty"`
-	UpdateType             string                         `json:"updateType" xml:"updateType"`
+	UpdateType             enums.UpdateType               `json:"updateType" xml:"updateType"`
 }
 
 // TriggerMessage
@@ -192,8 +195,8 @@ type TriggerMessage struct {
 	centralSystemRequest
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ triggerMessageRequest"`
 
-	ConnectorId      int    `json:"connectorId,omitempty" xml:"connectorId,omitempty"`
-	RequestedMessage string `json:"requestedMessage" xml:"requestedMessage"`
+	ConnectorId      int                  `json:"connectorId,omitempty" xml:"connectorId,omitempty"`
+	RequestedMessage enums.MessageTrigger `json:"requestedMessage" xml:"requestedMessage"`
 }
 
 // UnlockConnector
@@ -215,26 +218,18 @@ type UpdateFirmware struct {
 	RetryInterval float64   `json:"retryInterval,omitempty" xml:"retryInterval,omitempty"`
 }
 
-// CsChargingProfiles
-type CsChargingProfiles struct {
-	ChargingProfileId      int               `json:"chargingProfileId" xml:"chargingProfileId"`
-	ChargingProfileKind    string            `json:"chargingProfileKind" xml:"chargingProfileKind"`
-	ChargingProfilePurpose string            `json:"chargingProfilePurpose" xml:"chargingProfilePurpose"`
-	ChargingSchedule       *ChargingSchedule `json:"chargingSchedule" xml:"chargingSchedule"`
-	RecurrencyKind         string            `json:"recurrencyKind,omitempty" xml:"recurrencyKind,omitempty"`
-	StackLevel             int               `json:"stackLevel" xml:"stackLevel"`
-	TransactionId          int               `json:"transactionId,omitempty" xml:"transactionId,omitempty"`
-	ValidFrom              time.Time         `json:"validFrom,omitempty" xml:"validFrom,omitempty"`
-	ValidTo                time.Time         `json:"validTo,omitempty" xml:"validTo,omitempty"`
-}
+// CsChargingProfiles is the former name of the ChargingProfile of SetChargingProfile
+//
+// Deprecated: use ChargingProfile
+type CsChargingProfiles = ChargingProfile
 
 // SetChargingProfile
 type SetChargingProfile struct {
 	centralSystemRequest
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ setChargingProfileRequest"`
 
-	ConnectorId        int                 `json:"connectorId" xml:"connectorId"`
-	CsChargingProfiles *CsChargingProfiles `json:"csChargingProfiles" xml:"csChargingProfiles"`
+	ConnectorId        int              `json:"connectorId" xml:"connectorId"`
+	CsChargingProfiles *ChargingProfile `json:"csChargingProfiles" xml:"csChargingProfiles"`
 }
 
 func (m *CancelReservation) Action() string      { return "CancelReservation" }
@@ -3,6 +3,8 @@ package csresp
 import (
 	"encoding/xml"
 	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
 )
 
 // CancelReservation
@@ -10,7 +12,7 @@ type CancelReservation struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ cancelReservationResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.CancelReservationStatus `json:"status" xml:"status"`
 }
 
 // ChangeAvailability
@@ -18,7 +20,7 @@ type ChangeAvailability struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ changeAvailabilityResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.AvailabilityStatus `json:"status" xml:"status"`
 }
 
 // ChangeConfiguration
@@ -26,12 +28,12 @@ type ChangeConfiguration struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ changeConfigurationResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.ConfigurationStatus `json:"status" xml:"status"`
 }
 
 // ChargingSchedule
 type ChargingSchedule struct {
-	ChargingRateUnit       string                         `json:"chargingRateUnit" xml:"chargingRateUnit"`
+	ChargingRateUnit       enums.ChargingRateUnit         `json:"chargingRateUnit" xml:"chargingRateUnit"`
 	ChargingSchedulePeriod []*ChargingSchedulePeriodItems `json:"chargingSchedulePeriod" xml:"chargingSchedulePeriod"`
 	Duration               int                            `json:"duration,omitempty" xml:"duration,omitempty"`
 	MinChargingRate        float64                        `json:"minChargingRate,omitempty" xml:"minChargingRate,omitempty"`
@@ -50,7 +52,7 @@ type ClearCache struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ clearCacheResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.ClearCacheStatus `json:"status" xml:"status"`
 }
 
 // ClearChargingProfile
@@ -58,7 +60,7 @@ type ClearChargingProfile struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ clearChargingProfileResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.ClearChargingProfileStatus `json:"status" xml:"status"`
 }
 
 // ConfigurationKeyItems
@@ -73,8 +75,8 @@ type DataTransfer struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ dataTransferResponse"`
 
-	Data   string `json:"data,omitempty" xml:"data,omitempty"`
-	Status string `json:"status" xml:"status"`
+	Data   string                   `json:"data,omitempty" xml:"data,omitempty"`
+	Status enums.DataTransferStatus `json:"status" xml:"status"`
 }
 
 // GetCompositeSchedule
@@ -82,10 +84,10 @@ type GetCompositeSchedule struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ getCompositeScheduleResponse"`
 
-	ChargingSchedule *ChargingSchedule `json:"chargingSchedule,omitempty" xml:"chargingSchedule,omitempty"`
-	ConnectorId      int               `json:"connectorId,omitempty" xml:"connectorId,omitempty"`
-	ScheduleStart    *time.Time        `json:"scheduleStart,omitempty" xml:"scheduleStart,omitempty"`
-	Status           string            `json:"status" xml:"status"`
+	ChargingSchedule *ChargingSchedule                `json:"chargingSchedule,omitempty" xml:"chargingSchedule,omitempty"`
+	ConnectorId      int                              `json:"connectorId,omitempty" xml:"connectorId,omitempty"`
+	ScheduleStart    *time.Time                       `json:"scheduleStart,omitempty" xml:"scheduleStart,omitempty"`
+	Status           enums.GetCompositeScheduleStatus `json:"status" xml:"status"`
 }
 
 // GetConfiguration
@@ -118,7 +120,7 @@ type RemoteStartTransaction struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ remoteStartTransactionResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.RemoteStartStopStatus `json:"status" xml:"status"`
 }
 
 // RemoteStopTransaction
@@ -126,7 +128,7 @@ type RemoteStopTransaction struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ remoteStopTransactionResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.RemoteStartStopStatus `json:"status" xml:"status"`
 }
 
 // ReserveNow
@@ -134,7 +136,7 @@ type ReserveNow struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ reserveNowResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.ReservationStatus `json:"status" xml:"status"`
 }
 
 // Reset
@@ -142,7 +144,7 @@ type Reset struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ resetResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.ResetStatus `json:"status" xml:"status"`
 }
 
 // SendLocalList
@@ -150,7 +152,7 @@ type SendLocalList struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ sendLocalListResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.UpdateStatus `json:"status" xml:"status"`
 }
 
 // TriggerMessage
@@ -158,7 +160,7 @@ type TriggerMessage struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ triggerMessageResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.TriggerMessageStatus `json:"status" xml:"status"`
 }
 
 // UnlockConnector
@@ -166,7 +168,7 @@ type UnlockConnector struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ unlockConnectorResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.UnlockStatus `json:"status" xml:"status"`
 }
 
 // UpdateFirmware
@@ -180,5 +182,5 @@ type SetChargingProfile struct {
 	centralSystemResponse
 	XMLName xml.Name `json:"-" xml:"urn://Ocpp/Cp/2012/06/ setChargingProfileResponse"`
 
-	Status string `json:"status" xml:"status"`
+	Status enums.ChargingProfileStatus `json:"status" xml:"status"`
 }
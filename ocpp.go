@@ -21,5 +21,14 @@ const (
 	JSON Transport = "json"
 )
 
+// Side of an OCPP connection, which tells apart
+// the actions both sides send, e.g. DataTransfer
+type Side string
+
+const (
+	CentralSystem Side = "cs"
+	ChargePoint   Side = "cp"
+)
+
 func SetErrorLogger(logger log.Logger) { log.SetErrorLogger(logger) }
 func SetDebugLogger(logger log.Logger) { log.SetDebugLogger(logger) }
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/http/httptest"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/michaelbironneau/go-ocpp"
 	"github.com/michaelbironneau/go-ocpp/cp"
@@ -15,6 +19,7 @@ import (
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
 	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/ws"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,7 +28,6 @@ func Test_Connection(t *testing.T) {
 	cpointConnected := make(chan string)
 	cpointDisconnected := make(chan string)
 
-	csysPort := ":5050"
 	csys := cs.New()
 	csys.SetChargePointConnectionListener(func(cpID string) {
 		// t.Log("cpoint connected: ", cpID)
@@ -34,15 +38,17 @@ func Test_Connection(t *testing.T) {
 		cpointDisconnected <- cpID
 	})
 
-	go csys.Run(csysPort, func(req cpreq.ChargePointRequest, cpID string) (cpresp.ChargePointResponse, error) {
+	csys.SetChargePointMessageHandler(func(req cpreq.ChargePointRequest, metadata cs.ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
 		switch req.(type) {
 		case *cpreq.Heartbeat:
 			return &cpresp.Heartbeat{}, nil
 		}
 		return nil, errors.New("not supported")
 	})
+	server := httptest.NewServer(csys)
+	defer server.Close()
 
-	csysURL := "ws://localhost" + csysPort
+	csysURL := "ws" + strings.TrimPrefix(server.URL, "http")
 	t.Run("one chargepoint", func(t *testing.T) {
 		cpID := "123"
 		t.Run("single time", func(t *testing.T) {
@@ -104,18 +110,18 @@ func Test_Connection(t *testing.T) {
 		})
 	})
 	shouldSendCommandToChargePoint := func(cpID string) {
-		svc, err := csys.GetServiceOf(cpID, ocpp.V16, "")
+		svc, err := csys.GetServiceOf(cpID)
 		assert.NoError(t, err)
-		_, err = svc.Send(&csreq.GetConfiguration{})
+		_, err = svc.Send(cpID, &csreq.GetConfiguration{})
 		assert.NoError(t, err)
 	}
 	shouldNotSendCommandToChargePoint := func(cpID string) {
-		svc, err := csys.GetServiceOf(cpID, ocpp.V16, "")
+		svc, err := csys.GetServiceOf(cpID)
 		if svc == nil {
 			assert.Error(t, err)
 			return
 		}
-		_, err = svc.Send(&csreq.GetConfiguration{})
+		_, err = svc.Send(cpID, &csreq.GetConfiguration{})
 		assert.Error(t, err)
 	}
 	t.Run("double connect before disconnecting", func(t *testing.T) {
@@ -128,8 +134,115 @@ func Test_Connection(t *testing.T) {
 		shouldNotSendCommandToChargePoint("123")
 	})
 
-	// this test is still not passing every time
-	// it seems that we have a data race somewhere
+	t.Run("concurrent commands on one connection", func(t *testing.T) {
+		cpoint, disconnect := testConnectionDisconnection(t, "concurrent", csysURL, cpointConnected, cpointDisconnected)
+		defer disconnect()
+		svc, err := csys.GetServiceOf("concurrent")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		senders, calls := 50, 20
+		var wg sync.WaitGroup
+		for i := 0; i < senders; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < calls; j++ {
+					resp, err := svc.Send("concurrent", &csreq.GetConfiguration{})
+					assert.NoError(t, err)
+					assert.IsType(t, &csresp.GetConfiguration{}, resp)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < calls; j++ {
+					resp, err := cpoint.Send("concurrent", &cpreq.Heartbeat{})
+					assert.NoError(t, err)
+					assert.IsType(t, &cpresp.Heartbeat{}, resp)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("in-flight commands fail when the connection drops", func(t *testing.T) {
+		cpoint, disconnect := testConnectionDisconnection(t, "dropped", csysURL, cpointConnected, cpointDisconnected)
+		svc, err := csys.GetServiceOf("dropped")
+		if !assert.NoError(t, err) {
+			return
+		}
+		csysDisconnected := csys.WaitDisconnect("dropped")
+
+		inFlight := 10
+		errs := make(chan error, inFlight)
+		for i := 0; i < inFlight; i++ {
+			go func() {
+				// the charge point doesn't answer resets until released
+				_, err := svc.SendContext(context.Background(), "dropped", &csreq.Reset{Type: "Soft"})
+				errs <- err
+			}()
+		}
+		// give the calls some time to be sent
+		time.Sleep(50 * time.Millisecond)
+		cpoint.Connection().Close()
+		<-csysDisconnected
+
+		for i := 0; i < inFlight; i++ {
+			select {
+			case err := <-errs:
+				assert.True(t, errors.Is(err, ws.ErrConnectionClosed), "unexpected error: %v", err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("in-flight call was not failed")
+			}
+		}
+		close(releaseReset)
+
+		// the charge point reconnects after the drop, the first
+		// disconnection was already caught by testConnectionDisconnection
+		assert.Equal(t, "dropped", <-cpointConnected)
+		disconnect()
+		assert.Equal(t, "dropped", <-cpointDisconnected)
+	})
+
+	t.Run("SOAP chargepoint", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cpointPort := "127.0.0.1:0"
+		cpoint, err := cp.New(ctx, "soapCP", server.URL, ocpp.V15, ocpp.SOAP, &cpointPort, nil, func(req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+			switch req.(type) {
+			case *csreq.GetConfiguration:
+				return &csresp.GetConfiguration{}, nil
+			}
+			return nil, errors.New("not supported")
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		_, err = cp.NewCentralSystemClient(cpoint).Heartbeat(ctx, &cpreq.Heartbeat{})
+		assert.NoError(t, err)
+
+		// the central system learned the endpoint from the heartbeat
+		svc, err := csys.GetServiceOf("soapCP")
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = svc.Send("soapCP", &csreq.GetConfiguration{})
+		assert.NoError(t, err)
+
+		// e.g. the charge point is behind a NAT
+		unreachable := httptest.NewServer(nil)
+		unreachable.Close()
+		csys.SetChargePointEndpoint("soapCP", unreachable.URL)
+		svc, err = csys.GetServiceOf("soapCP")
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = svc.Send("soapCP", &csreq.GetConfiguration{})
+		assert.Error(t, err)
+	})
+
 	t.Run("anomalous connection", func(t *testing.T) {
 		cpoint, _ := testConnectionDisconnection(t, "123", csysURL, cpointConnected, cpointDisconnected)
 
@@ -141,13 +254,20 @@ func Test_Connection(t *testing.T) {
 		shouldSendCommandToChargePoint("123")
 
 		for i := 0; i < 1000; i++ {
-			// BEGIN
+			// the charge point reconnects right away, so keep
+			// hold of the service of the connection being closed
+			svc, err := csys.GetServiceOf("123")
+			assert.NoError(t, err)
+			cpointDisconnected := cpoint.WaitDisconnect()
+			csysDisconnected := csys.WaitDisconnect("123")
+
 			cpoint.Connection().Close()
 
-			<-cpoint.WaitDisconnect()
-			<-csys.WaitDisconnect("123")
+			<-cpointDisconnected
+			<-csysDisconnected
 
-			shouldNotSendCommandToChargePoint("123")
+			_, err = svc.Send("123", &csreq.GetConfiguration{})
+			assert.True(t, errors.Is(err, ws.ErrConnectionClosed))
 
 			<-cpoint.WaitConnect()
 			<-csys.WaitConnect("123")
@@ -156,12 +276,18 @@ func Test_Connection(t *testing.T) {
 	})
 }
 
+// releaseReset is closed to let charge points answer Reset requests
+var releaseReset = make(chan struct{})
+
 func testConnectionDisconnection(t *testing.T, cpID, csysURL string, cpointConnected, cpointDisconnected chan string) (cpoint cp.ChargePoint, disconnect func()) {
 	cpctx, killCp := context.WithCancel(context.Background())
-	cpoint, err := cp.New(cpctx, cpID, csysURL, ocpp.V16, ocpp.JSON, nil, func(req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	cpoint, err := cp.New(cpctx, cpID, csysURL, ocpp.V16, ocpp.JSON, nil, nil, func(req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
 		switch req.(type) {
 		case *csreq.GetConfiguration:
 			return &csresp.GetConfiguration{}, nil
+		case *csreq.Reset:
+			<-releaseReset
+			return &csresp.Reset{Status: "Accepted"}, nil
 		}
 		return nil, errors.New("not supported")
 	})
@@ -175,7 +301,7 @@ func testConnectionDisconnection(t *testing.T, cpID, csysURL string, cpointConne
 		cpointConnected <- connectedCpID
 	}
 
-	_, err = cpoint.Send(&cpreq.Heartbeat{})
+	_, err = cpoint.Send(cpID, &cpreq.Heartbeat{})
 	assert.NoError(t, err)
 
 	done := make(chan struct{})
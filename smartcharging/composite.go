@@ -0,0 +1,293 @@
+// Package smartcharging computes what a charge point does with
+// the charging profiles of OCPP 1.6 smart charging
+package smartcharging
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+)
+
+const (
+	defaultVoltage = 230
+	defaultPhases  = 3
+	day            = 24 * time.Hour
+	week           = 7 * day
+)
+
+// ErrNoSchedule is returned when no profile limits
+// the connector at the start of the schedule
+var ErrNoSchedule = errors.New("no charging profile applies")
+
+// Connector is a connector and the charging profiles it's subject to
+type Connector struct {
+	// Profiles installed on the connector, along with the
+	// ChargePointMaxProfile and TxDefaultProfiles of connector 0
+	Profiles []*csreq.ChargingProfile
+	// TransactionId and TransactionStart are the ongoing transaction,
+	// TransactionStart being nil when there's none. The TxProfiles only
+	// apply to it, and the Relative profiles start with it.
+	TransactionId    int32
+	TransactionStart *time.Time
+	// Voltage, 230 V by default, and Phases, 3 by default,
+	// convert the limits between A and W
+	Voltage float64
+	Phases  int
+	// MaxCurrent is the limit in A where no profile applies. Without
+	// it, the composite schedule stops where the profiles stop.
+	MaxCurrent float64
+}
+
+// CompositeSchedule computes the schedule the connector follows for the
+// duration in seconds from start: at any time, the limit is the lowest
+// of the ChargePointMaxProfile and of the TxProfile or, lacking one, the
+// TxDefaultProfile, each purpose being led by the valid profile of the
+// highest stack level. The limits are rounded down to 0.1.
+func (c *Connector) CompositeSchedule(start time.Time, duration int, unit enums.ChargingRateUnit) (*csresp.ChargingSchedule, error) {
+	if !unit.IsValid() {
+		return nil, fmt.Errorf("unknown charging rate unit %q", unit)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %d", duration)
+	}
+	profiles, err := c.applicable()
+	if err != nil {
+		return nil, err
+	}
+	origin := start
+	if c.TransactionStart != nil {
+		origin = *c.TransactionStart
+	}
+	end := start.Add(time.Duration(duration) * time.Second)
+	schedule := &csresp.ChargingSchedule{
+		ChargingRateUnit: unit,
+		StartSchedule:    &start,
+	}
+	var last *csresp.ChargingSchedulePeriodItems
+	for t := start; t.Before(end); {
+		period, ok := c.periodAt(profiles, origin, t, unit)
+		if !ok {
+			if t.Equal(start) {
+				return nil, ErrNoSchedule
+			}
+			end = t
+			break
+		}
+		if last == nil || last.Limit != period.Limit || last.NumberPhases != period.NumberPhases {
+			period.StartPeriod = seconds(t.Sub(start))
+			schedule.ChargingSchedulePeriod = append(schedule.ChargingSchedulePeriod, period)
+			last = period
+		}
+		next := nextChange(profiles, origin, t)
+		if next.IsZero() {
+			break
+		}
+		t = next
+	}
+	schedule.Duration = seconds(end.Sub(start))
+	return schedule, nil
+}
+
+// GetCompositeSchedule answers the request with the composite schedule
+// starting now, in A unless the request asks for another unit
+func (c *Connector) GetCompositeSchedule(req *csreq.GetCompositeSchedule, now time.Time) *csresp.GetCompositeSchedule {
+	unit := req.ChargingRateUnit
+	if unit == "" {
+		unit = enums.ChargingRateUnitA
+	}
+	schedule, err := c.CompositeSchedule(now, req.Duration, unit)
+	if err != nil {
+		return &csresp.GetCompositeSchedule{Status: enums.GetCompositeScheduleStatusRejected}
+	}
+	return &csresp.GetCompositeSchedule{
+		Status:           enums.GetCompositeScheduleStatusAccepted,
+		ConnectorId:      req.ConnectorId,
+		ScheduleStart:    &now,
+		ChargingSchedule: schedule,
+	}
+}
+
+// applicable are the profiles applying to the connector by
+// purpose, from the highest stack level to the lowest
+func (c *Connector) applicable() (map[enums.ChargingProfilePurpose][]*csreq.ChargingProfile, error) {
+	profiles := make(map[enums.ChargingProfilePurpose][]*csreq.ChargingProfile)
+	for _, profile := range c.Profiles {
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("profile %d: %w", profile.ChargingProfileId, err)
+		}
+		if profile.ChargingProfilePurpose == enums.ChargingProfilePurposeTxProfile {
+			if c.TransactionStart == nil {
+				continue
+			}
+			if profile.TransactionId != 0 && profile.TransactionId != c.TransactionId {
+				continue
+			}
+		}
+		profiles[profile.ChargingProfilePurpose] = append(profiles[profile.ChargingProfilePurpose], profile)
+	}
+	for _, list := range profiles {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].StackLevel > list[j].StackLevel
+		})
+	}
+	return profiles, nil
+}
+
+// periodAt is the composite period at t, without its start
+func (c *Connector) periodAt(profiles map[enums.ChargingProfilePurpose][]*csreq.ChargingProfile, origin, t time.Time, unit enums.ChargingRateUnit) (*csresp.ChargingSchedulePeriodItems, bool) {
+	var result *csresp.ChargingSchedulePeriodItems
+	limit := func(schedule *csreq.ChargingSchedule, period *csreq.ChargingSchedulePeriodItems) {
+		value := c.convert(period.Limit, schedule.ChargingRateUnit, unit, period.NumberPhases)
+		if result == nil || value < result.Limit {
+			result = &csresp.ChargingSchedulePeriodItems{Limit: value, NumberPhases: period.NumberPhases}
+		}
+	}
+	if profile, period := prevailing(profiles[enums.ChargingProfilePurposeChargePointMaxProfile], origin, t); period != nil {
+		limit(profile.ChargingSchedule, period)
+	}
+	profile, period := prevailing(profiles[enums.ChargingProfilePurposeTxProfile], origin, t)
+	if period == nil {
+		profile, period = prevailing(profiles[enums.ChargingProfilePurposeTxDefaultProfile], origin, t)
+	}
+	if period != nil {
+		limit(profile.ChargingSchedule, period)
+	}
+	if result == nil {
+		if c.MaxCurrent <= 0 {
+			return nil, false
+		}
+		result = &csresp.ChargingSchedulePeriodItems{Limit: c.convert(c.MaxCurrent, enums.ChargingRateUnitA, unit, 0)}
+	}
+	result.Limit = math.Floor(result.Limit*10+1e-9) / 10
+	return result, true
+}
+
+// convert the limit between units, over the given
+// number of phases or the connector's by default
+func (c *Connector) convert(limit float64, from, to enums.ChargingRateUnit, phases int) float64 {
+	if from == to {
+		return limit
+	}
+	voltage := c.Voltage
+	if voltage <= 0 {
+		voltage = defaultVoltage
+	}
+	if phases <= 0 {
+		phases = c.Phases
+	}
+	if phases <= 0 {
+		phases = defaultPhases
+	}
+	if to == enums.ChargingRateUnitW {
+		return limit * voltage * float64(phases)
+	}
+	return limit / (voltage * float64(phases))
+}
+
+// prevailing is the profile of the highest stack level
+// limiting the connector at t, and its period at t
+func prevailing(profiles []*csreq.ChargingProfile, origin, t time.Time) (*csreq.ChargingProfile, *csreq.ChargingSchedulePeriodItems) {
+	for _, profile := range profiles {
+		if period := periodAt(profile, origin, t); period != nil {
+			return profile, period
+		}
+	}
+	return nil, nil
+}
+
+// periodAt is the period of the profile at t, if it limits the connector then
+func periodAt(profile *csreq.ChargingProfile, origin, t time.Time) *csreq.ChargingSchedulePeriodItems {
+	if profile.ValidFrom != nil && t.Before(*profile.ValidFrom) {
+		return nil
+	}
+	if profile.ValidTo != nil && !t.Before(*profile.ValidTo) {
+		return nil
+	}
+	start := scheduleStart(profile, origin, t)
+	if t.Before(start) {
+		return nil
+	}
+	schedule := profile.ChargingSchedule
+	offset := seconds(t.Sub(start))
+	if schedule.Duration > 0 && offset >= schedule.Duration {
+		return nil
+	}
+	var current *csreq.ChargingSchedulePeriodItems
+	for _, period := range schedule.ChargingSchedulePeriod {
+		if period.StartPeriod > offset {
+			break
+		}
+		current = period
+	}
+	return current
+}
+
+// scheduleStart is when the schedule of the profile started
+// before t, or when it'll start if it hasn't yet
+func scheduleStart(profile *csreq.ChargingProfile, origin, t time.Time) time.Time {
+	schedule := profile.ChargingSchedule
+	switch profile.ChargingProfileKind {
+	case enums.ChargingProfileKindAbsolute:
+		if schedule.StartSchedule != nil {
+			return *schedule.StartSchedule
+		}
+	case enums.ChargingProfileKindRecurring:
+		start := *schedule.StartSchedule
+		if t.Before(start) {
+			return start
+		}
+		recurrency := recurrency(profile)
+		return start.Add(t.Sub(start) / recurrency * recurrency)
+	}
+	return origin
+}
+
+func recurrency(profile *csreq.ChargingProfile) time.Duration {
+	if profile.RecurrencyKind == enums.RecurrencyKindWeekly {
+		return week
+	}
+	return day
+}
+
+// nextChange is the first time after t at which any of the
+// profiles may change the limit, zero if there's none
+func nextChange(profiles map[enums.ChargingProfilePurpose][]*csreq.ChargingProfile, origin, t time.Time) time.Time {
+	var next time.Time
+	candidate := func(c time.Time) {
+		if c.After(t) && (next.IsZero() || c.Before(next)) {
+			next = c
+		}
+	}
+	for _, list := range profiles {
+		for _, profile := range list {
+			if profile.ValidFrom != nil {
+				candidate(*profile.ValidFrom)
+			}
+			if profile.ValidTo != nil {
+				candidate(*profile.ValidTo)
+			}
+			start := scheduleStart(profile, origin, t)
+			schedule := profile.ChargingSchedule
+			for _, period := range schedule.ChargingSchedulePeriod {
+				candidate(start.Add(time.Duration(period.StartPeriod) * time.Second))
+			}
+			if schedule.Duration > 0 {
+				candidate(start.Add(time.Duration(schedule.Duration) * time.Second))
+			}
+			if profile.ChargingProfileKind == enums.ChargingProfileKindRecurring {
+				candidate(start.Add(recurrency(profile)))
+			}
+		}
+	}
+	return next
+}
+
+func seconds(d time.Duration) int {
+	return int(d / time.Second)
+}
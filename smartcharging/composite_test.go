@@ -0,0 +1,382 @@
+package smartcharging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func at(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+type period struct {
+	start  int
+	limit  float64
+	phases int
+}
+
+func schedule(unit enums.ChargingRateUnit, periods ...period) *csreq.ChargingSchedule {
+	s := &csreq.ChargingSchedule{ChargingRateUnit: unit}
+	for _, p := range periods {
+		s.ChargingSchedulePeriod = append(s.ChargingSchedulePeriod, &csreq.ChargingSchedulePeriodItems{StartPeriod: p.start, Limit: p.limit, NumberPhases: p.phases})
+	}
+	return s
+}
+
+// dailyMaxProfile is the example of the OCPP 1.6 specification
+// (3.13.4): 11 kW from 20:00 to 08:00 and 6 kW from 08:00 to 20:00
+func dailyMaxProfile() *csreq.ChargingProfile {
+	s := schedule(enums.ChargingRateUnitW, period{0, 11000, 3}, period{28800, 6000, 3}, period{72000, 11000, 3})
+	s.Duration = 86400
+	s.StartSchedule = at("2013-01-01T00:00:00Z")
+	return &csreq.ChargingProfile{
+		ChargingProfileId:      100,
+		StackLevel:             0,
+		ChargingProfilePurpose: enums.ChargingProfilePurposeChargePointMaxProfile,
+		ChargingProfileKind:    enums.ChargingProfileKindRecurring,
+		RecurrencyKind:         enums.RecurrencyKindDaily,
+		ChargingSchedule:       s,
+	}
+}
+
+func absolute(id, stackLevel int, purpose enums.ChargingProfilePurpose, s *csreq.ChargingSchedule) *csreq.ChargingProfile {
+	return &csreq.ChargingProfile{
+		ChargingProfileId:      id,
+		StackLevel:             stackLevel,
+		ChargingProfilePurpose: purpose,
+		ChargingProfileKind:    enums.ChargingProfileKindAbsolute,
+		ChargingSchedule:       s,
+	}
+}
+
+func relative(id, stackLevel int, purpose enums.ChargingProfilePurpose, s *csreq.ChargingSchedule) *csreq.ChargingProfile {
+	p := absolute(id, stackLevel, purpose, s)
+	p.ChargingProfileKind = enums.ChargingProfileKindRelative
+	return p
+}
+
+func Test_CompositeSchedule(t *testing.T) {
+	txDefault := enums.ChargingProfilePurposeTxDefaultProfile
+	txProfile := enums.ChargingProfilePurposeTxProfile
+	cpMax := enums.ChargingProfilePurposeChargePointMaxProfile
+	amps, watts := enums.ChargingRateUnitA, enums.ChargingRateUnitW
+
+	cases := []struct {
+		Name      string
+		Connector func() *Connector
+		Start     string
+		Duration  int
+		Unit      enums.ChargingRateUnit
+		Periods   []period
+		// ScheduleDuration is the Duration when it's not the requested one
+		ScheduleDuration int
+		Err              error
+	}{
+		{
+			Name:      "spec daily ChargePointMaxProfile over a day",
+			Connector: func() *Connector { return &Connector{Profiles: []*csreq.ChargingProfile{dailyMaxProfile()}} },
+			Start:     "2020-06-01T06:00:00Z",
+			Duration:  86400,
+			Unit:      watts,
+			Periods:   []period{{0, 11000, 3}, {7200, 6000, 3}, {50400, 11000, 3}},
+		},
+		{
+			Name:      "spec daily ChargePointMaxProfile in A",
+			Connector: func() *Connector { return &Connector{Profiles: []*csreq.ChargingProfile{dailyMaxProfile()}} },
+			Start:     "2020-06-01T07:00:00Z",
+			Duration:  7200,
+			Unit:      amps,
+			Periods:   []period{{0, 15.9, 3}, {3600, 8.6, 3}},
+		},
+		{
+			Name:      "spec daily ChargePointMaxProfile over midnight",
+			Connector: func() *Connector { return &Connector{Profiles: []*csreq.ChargingProfile{dailyMaxProfile()}} },
+			Start:     "2020-06-01T19:00:00Z",
+			Duration:  15 * 3600,
+			Unit:      watts,
+			Periods:   []period{{0, 6000, 3}, {3600, 11000, 3}, {13 * 3600, 6000, 3}},
+		},
+		{
+			Name: "weekly recurrence",
+			Connector: func() *Connector {
+				s := schedule(amps, period{0, 10, 0}, period{86400, 32, 0})
+				s.StartSchedule = at("2020-06-01T00:00:00Z") // a Monday
+				p := absolute(1, 0, txDefault, s)
+				p.ChargingProfileKind = enums.ChargingProfileKindRecurring
+				p.RecurrencyKind = enums.RecurrencyKindWeekly
+				return &Connector{Profiles: []*csreq.ChargingProfile{p}}
+			},
+			Start:    "2020-06-14T12:00:00Z",
+			Duration: 2 * 86400,
+			Unit:     amps,
+			Periods:  []period{{0, 32, 0}, {43200, 10, 0}, {43200 + 86400, 32, 0}},
+		},
+		{
+			Name: "higher stack level within its validity",
+			Connector: func() *Connector {
+				night := absolute(2, 1, txDefault, schedule(amps, period{0, 10, 0}))
+				night.ValidFrom = at("2020-06-01T01:00:00Z")
+				night.ValidTo = at("2020-06-01T02:00:00Z")
+				return &Connector{Profiles: []*csreq.ChargingProfile{
+					absolute(1, 0, txDefault, schedule(amps, period{0, 16, 0})),
+					night,
+				}}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 3 * 3600,
+			Unit:     amps,
+			Periods:  []period{{0, 16, 0}, {3600, 10, 0}, {7200, 16, 0}},
+		},
+		{
+			Name: "higher stack level until its duration",
+			Connector: func() *Connector {
+				s := schedule(amps, period{0, 6, 0})
+				s.Duration = 1800
+				return &Connector{Profiles: []*csreq.ChargingProfile{
+					relative(1, 0, txDefault, schedule(amps, period{0, 16, 0})),
+					relative(2, 1, txDefault, s),
+				}}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 3600,
+			Unit:     amps,
+			Periods:  []period{{0, 6, 0}, {1800, 16, 0}},
+		},
+		{
+			Name: "TxProfile overrides the TxDefaultProfile",
+			Connector: func() *Connector {
+				tx := relative(2, 0, txProfile, schedule(amps, period{0, 8, 0}, period{600, 20, 0}))
+				tx.TransactionId = 42
+				return &Connector{
+					Profiles: []*csreq.ChargingProfile{
+						relative(1, 5, txDefault, schedule(amps, period{0, 16, 0})),
+						tx,
+					},
+					TransactionId:    42,
+					TransactionStart: at("2020-06-01T00:00:00Z"),
+				}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 1200,
+			Unit:     amps,
+			Periods:  []period{{0, 8, 0}, {600, 20, 0}},
+		},
+		{
+			Name: "ChargePointMaxProfile caps the TxProfile",
+			Connector: func() *Connector {
+				return &Connector{
+					Profiles: []*csreq.ChargingProfile{
+						absolute(1, 0, cpMax, schedule(amps, period{0, 16, 0})),
+						relative(2, 0, txProfile, schedule(amps, period{0, 8, 0}, period{600, 20, 0})),
+					},
+					TransactionId:    42,
+					TransactionStart: at("2020-06-01T00:00:00Z"),
+				}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 1200,
+			Unit:     amps,
+			Periods:  []period{{0, 8, 0}, {600, 16, 0}},
+		},
+		{
+			Name: "TxProfile of another transaction",
+			Connector: func() *Connector {
+				tx := relative(2, 0, txProfile, schedule(amps, period{0, 8, 0}))
+				tx.TransactionId = 41
+				return &Connector{
+					Profiles:         []*csreq.ChargingProfile{relative(1, 0, txDefault, schedule(amps, period{0, 16, 0})), tx},
+					TransactionId:    42,
+					TransactionStart: at("2020-06-01T00:00:00Z"),
+				}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 600,
+			Unit:     amps,
+			Periods:  []period{{0, 16, 0}},
+		},
+		{
+			Name: "TxProfile without transaction",
+			Connector: func() *Connector {
+				return &Connector{Profiles: []*csreq.ChargingProfile{
+					relative(1, 0, txDefault, schedule(amps, period{0, 16, 0})),
+					relative(2, 0, txProfile, schedule(amps, period{0, 8, 0})),
+				}}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 600,
+			Unit:     amps,
+			Periods:  []period{{0, 16, 0}},
+		},
+		{
+			Name: "Relative profile from the start of the transaction",
+			Connector: func() *Connector {
+				return &Connector{
+					Profiles:         []*csreq.ChargingProfile{relative(1, 0, txDefault, schedule(amps, period{0, 32, 0}, period{1800, 16, 0}))},
+					TransactionStart: at("2020-06-01T00:00:00Z"),
+				}
+			},
+			Start:    "2020-06-01T00:20:00Z",
+			Duration: 1800,
+			Unit:     amps,
+			Periods:  []period{{0, 32, 0}, {600, 16, 0}},
+		},
+		{
+			Name: "Absolute profile without startSchedule",
+			Connector: func() *Connector {
+				return &Connector{Profiles: []*csreq.ChargingProfile{absolute(1, 0, txDefault, schedule(amps, period{0, 32, 0}, period{60, 16, 0}))}}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 120,
+			Unit:     amps,
+			Periods:  []period{{0, 32, 0}, {60, 16, 0}},
+		},
+		{
+			Name: "A to W over the phases of the period",
+			Connector: func() *Connector {
+				return &Connector{Profiles: []*csreq.ChargingProfile{relative(1, 0, txDefault, schedule(amps, period{0, 16, 1}, period{60, 16, 0}))}}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 120,
+			Unit:     watts,
+			Periods:  []period{{0, 3680, 1}, {60, 11040, 0}},
+		},
+		{
+			Name: "W to A with a voltage",
+			Connector: func() *Connector {
+				return &Connector{
+					Profiles: []*csreq.ChargingProfile{relative(1, 0, txDefault, schedule(watts, period{0, 7200, 0}))},
+					Voltage:  240,
+					Phases:   1,
+				}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 60,
+			Unit:     amps,
+			Periods:  []period{{0, 30, 0}},
+		},
+		{
+			Name: "merges equal limits",
+			Connector: func() *Connector {
+				return &Connector{Profiles: []*csreq.ChargingProfile{
+					absolute(1, 0, cpMax, schedule(amps, period{0, 16, 0}, period{60, 10, 0})),
+					relative(2, 0, txDefault, schedule(amps, period{0, 10, 0}, period{120, 20, 0})),
+				}}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 180,
+			Unit:     amps,
+			Periods:  []period{{0, 10, 0}},
+		},
+		{
+			Name: "stops where the profiles stop",
+			Connector: func() *Connector {
+				p := absolute(1, 0, txDefault, schedule(amps, period{0, 16, 0}))
+				p.ValidTo = at("2020-06-01T00:10:00Z")
+				return &Connector{Profiles: []*csreq.ChargingProfile{p}}
+			},
+			Start:            "2020-06-01T00:00:00Z",
+			Duration:         3600,
+			Unit:             amps,
+			Periods:          []period{{0, 16, 0}},
+			ScheduleDuration: 600,
+		},
+		{
+			Name: "max current where the profiles stop",
+			Connector: func() *Connector {
+				p := absolute(1, 0, txDefault, schedule(amps, period{0, 16, 0}))
+				p.ValidTo = at("2020-06-01T00:10:00Z")
+				return &Connector{Profiles: []*csreq.ChargingProfile{p}, MaxCurrent: 32}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 3600,
+			Unit:     amps,
+			Periods:  []period{{0, 16, 0}, {600, 32, 0}},
+		},
+		{
+			Name: "profile not yet valid",
+			Connector: func() *Connector {
+				p := absolute(1, 0, txDefault, schedule(amps, period{0, 16, 0}))
+				p.ValidFrom = at("2020-06-01T00:10:00Z")
+				return &Connector{Profiles: []*csreq.ChargingProfile{p}}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 3600,
+			Unit:     amps,
+			Err:      ErrNoSchedule,
+		},
+		{
+			Name:      "no profile",
+			Connector: func() *Connector { return &Connector{} },
+			Start:     "2020-06-01T00:00:00Z",
+			Duration:  3600,
+			Unit:      amps,
+			Err:       ErrNoSchedule,
+		},
+		{
+			Name: "invalid profile",
+			Connector: func() *Connector {
+				p := dailyMaxProfile()
+				p.RecurrencyKind = ""
+				return &Connector{Profiles: []*csreq.ChargingProfile{p}}
+			},
+			Start:    "2020-06-01T00:00:00Z",
+			Duration: 3600,
+			Unit:     amps,
+			Err:      csreq.ErrInvalidChargingProfile,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			start := at(c.Start)
+			s, err := c.Connector().CompositeSchedule(*start, c.Duration, c.Unit)
+			if c.Err != nil {
+				assert.True(t, errors.Is(err, c.Err), "unexpected error: %v", err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			duration := c.ScheduleDuration
+			if duration == 0 {
+				duration = c.Duration
+			}
+			assert.Equal(t, duration, s.Duration)
+			assert.Equal(t, c.Unit, s.ChargingRateUnit)
+			assert.Equal(t, *start, *s.StartSchedule)
+			var periods []period
+			for _, p := range s.ChargingSchedulePeriod {
+				periods = append(periods, period{p.StartPeriod, p.Limit, p.NumberPhases})
+			}
+			assert.Equal(t, c.Periods, periods)
+		})
+	}
+}
+
+func Test_GetCompositeSchedule(t *testing.T) {
+	now := *at("2020-06-01T07:00:00Z")
+	connector := &Connector{Profiles: []*csreq.ChargingProfile{dailyMaxProfile()}}
+
+	resp := connector.GetCompositeSchedule(&csreq.GetCompositeSchedule{ConnectorId: 1, Duration: 7200}, now)
+	assert.Equal(t, enums.GetCompositeScheduleStatusAccepted, resp.Status)
+	assert.Equal(t, 1, resp.ConnectorId)
+	assert.Equal(t, now, *resp.ScheduleStart)
+	assert.Equal(t, enums.ChargingRateUnitA, resp.ChargingSchedule.ChargingRateUnit)
+	assert.Equal(t, []*csresp.ChargingSchedulePeriodItems{
+		{StartPeriod: 0, Limit: 15.9, NumberPhases: 3},
+		{StartPeriod: 3600, Limit: 8.6, NumberPhases: 3},
+	}, resp.ChargingSchedule.ChargingSchedulePeriod)
+
+	resp = (&Connector{}).GetCompositeSchedule(&csreq.GetCompositeSchedule{ConnectorId: 1, Duration: 7200}, now)
+	assert.Equal(t, enums.GetCompositeScheduleStatusRejected, resp.Status)
+	assert.Nil(t, resp.ChargingSchedule)
+}
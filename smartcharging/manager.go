@@ -0,0 +1,414 @@
+package smartcharging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/internal/log"
+	"github.com/michaelbironneau/go-ocpp/internal/service"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+)
+
+// DefaultReconcileTimeout bounds the reconciliation
+// of a charge point after its BootNotification
+const DefaultReconcileTimeout = 2 * time.Minute
+
+// ErrProfileRejected is returned when the charge point
+// doesn't accept a SetChargingProfile
+var ErrProfileRejected = errors.New("charging profile rejected")
+
+// Profile is a charging profile of a connector,
+// 0 being the whole charge point
+type Profile struct {
+	ConnectorId int
+	*csreq.ChargingProfile
+}
+
+// Drift is a composite schedule reported by the charge point
+// which isn't the one its profiles should give
+type Drift struct {
+	ChargePointID string
+	ConnectorId   int
+	// Expected is nil when no profile should apply, and
+	// Actual when the charge point rejected the request
+	Expected *csresp.ChargingSchedule
+	Actual   *csresp.ChargingSchedule
+}
+
+// DriftListener is called with every drift Verify finds
+type DriftListener func(drift Drift)
+
+// Manager keeps the profiles the central system wants on each charge
+// point, and the ones the charge points accepted. It pushes the former
+// through SetChargingProfile and ClearChargingProfile, and reconciles
+// the two after a reboot of the charge point, see Reconcile.
+type Manager struct {
+	serviceOf     func(cpID string) (service.ChargePoint, error)
+	driftListener DriftListener
+	// reconcileTimeout bounds the reconciliations after a boot
+	reconcileTimeout time.Duration
+
+	mux      sync.Mutex
+	chargers map[string]*chargerProfiles
+}
+
+type chargerProfiles struct {
+	// desired and installed profiles by ID
+	desired   map[int]Profile
+	installed map[int]Profile
+	// transactions by connector
+	transactions map[int]transaction
+}
+
+type transaction struct {
+	id    int32
+	start time.Time
+}
+
+// ManagerOption configures the manager
+type ManagerOption func(*Manager)
+
+// WithDriftListener calls the listener with the drifts found by Verify
+func WithDriftListener(listener DriftListener) ManagerOption {
+	return func(m *Manager) {
+		m.driftListener = listener
+	}
+}
+
+// WithReconcileTimeout bounds the reconciliation of a charge point
+// after its BootNotification, DefaultReconcileTimeout by default
+func WithReconcileTimeout(timeout time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.reconcileTimeout = timeout
+	}
+}
+
+// NewManager sends the requests through the service
+// of the charge points, e.g. cs.CentralSystem's GetServiceOf
+func NewManager(serviceOf func(cpID string) (service.ChargePoint, error), options ...ManagerOption) *Manager {
+	m := &Manager{
+		serviceOf:        serviceOf,
+		driftListener:    func(Drift) {},
+		reconcileTimeout: DefaultReconcileTimeout,
+		chargers:         make(map[string]*chargerProfiles),
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+func (m *Manager) charger(cpID string) *chargerProfiles {
+	charger, ok := m.chargers[cpID]
+	if !ok {
+		charger = &chargerProfiles{
+			desired:      make(map[int]Profile),
+			installed:    make(map[int]Profile),
+			transactions: make(map[int]transaction),
+		}
+		m.chargers[cpID] = charger
+	}
+	return charger
+}
+
+func (m *Manager) client(cpID string) (*cs.ChargePointClient, error) {
+	svc, err := m.serviceOf(cpID)
+	if err != nil {
+		return nil, err
+	}
+	return cs.NewChargePointClient(cpID, svc), nil
+}
+
+// Set makes the profile desired on the connector and pushes it. It
+// stays desired when it couldn't be pushed, Reconcile retrying it.
+func (m *Manager) Set(ctx context.Context, cpID string, connectorID int, profile *csreq.ChargingProfile) error {
+	req := &csreq.SetChargingProfile{ConnectorId: connectorID, CsChargingProfiles: profile}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	m.mux.Lock()
+	m.charger(cpID).desired[profile.ChargingProfileId] = Profile{ConnectorId: connectorID, ChargingProfile: profile}
+	m.mux.Unlock()
+	return m.push(ctx, cpID, Profile{ConnectorId: connectorID, ChargingProfile: profile})
+}
+
+func (m *Manager) push(ctx context.Context, cpID string, profile Profile) error {
+	client, err := m.client(cpID)
+	if err != nil {
+		return err
+	}
+	resp, err := client.SetChargingProfile(ctx, &csreq.SetChargingProfile{ConnectorId: profile.ConnectorId, CsChargingProfiles: profile.ChargingProfile})
+	if err != nil {
+		return err
+	}
+	if resp.Status != enums.ChargingProfileStatusAccepted {
+		return fmt.Errorf("%w: %s", ErrProfileRejected, resp.Status)
+	}
+	m.mux.Lock()
+	m.charger(cpID).installed[profile.ChargingProfileId] = profile
+	m.mux.Unlock()
+	return nil
+}
+
+// Clear makes the profile no longer desired and clears it. It stays
+// installed when it couldn't be cleared, Reconcile retrying it.
+func (m *Manager) Clear(ctx context.Context, cpID string, profileID int) error {
+	m.mux.Lock()
+	delete(m.charger(cpID).desired, profileID)
+	m.mux.Unlock()
+	return m.clear(ctx, cpID, profileID)
+}
+
+func (m *Manager) clear(ctx context.Context, cpID string, profileID int) error {
+	client, err := m.client(cpID)
+	if err != nil {
+		return err
+	}
+	// Unknown means the charge point doesn't have it
+	// anymore, which is as good as cleared
+	if _, err := client.ClearChargingProfile(ctx, &csreq.ClearChargingProfile{Id: profileID}); err != nil {
+		return err
+	}
+	m.mux.Lock()
+	delete(m.charger(cpID).installed, profileID)
+	m.mux.Unlock()
+	return nil
+}
+
+// Desired are the profiles the charge point should have, by connector and ID
+func (m *Manager) Desired(cpID string) []Profile {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return sortedProfiles(m.charger(cpID).desired)
+}
+
+// Installed are the profiles the charge point accepted and
+// weren't cleared since, by connector and ID
+func (m *Manager) Installed(cpID string) []Profile {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return sortedProfiles(m.charger(cpID).installed)
+}
+
+func sortedProfiles(profiles map[int]Profile) []Profile {
+	sorted := make([]Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		sorted = append(sorted, profile)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].ConnectorId != sorted[j].ConnectorId {
+			return sorted[i].ConnectorId < sorted[j].ConnectorId
+		}
+		return sorted[i].ChargingProfileId < sorted[j].ChargingProfileId
+	})
+	return sorted
+}
+
+// StartTransaction tells the manager about the transaction of the
+// connector, for its TxProfiles and the expectations of Verify
+func (m *Manager) StartTransaction(cpID string, connectorID int, transactionID int32, start time.Time) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.charger(cpID).transactions[connectorID] = transaction{id: transactionID, start: start}
+}
+
+// StopTransaction forgets the transaction of the connector and
+// its TxProfiles, which the charge point deletes with it
+func (m *Manager) StopTransaction(cpID string, connectorID int) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	charger := m.charger(cpID)
+	delete(charger.transactions, connectorID)
+	for _, profiles := range []map[int]Profile{charger.desired, charger.installed} {
+		for id, profile := range profiles {
+			if profile.ConnectorId == connectorID && profile.ChargingProfilePurpose == enums.ChargingProfilePurposeTxProfile {
+				delete(profiles, id)
+			}
+		}
+	}
+}
+
+// Reconcile brings the charge point back to the desired profiles,
+// e.g. after its BootNotification: its transactions and their
+// TxProfiles are gone, the profiles which couldn't be cleared
+// are cleared and the desired ones are pushed again. It goes
+// on after an error, and returns the first one.
+func (m *Manager) Reconcile(ctx context.Context, cpID string) error {
+	m.mux.Lock()
+	charger := m.charger(cpID)
+	charger.transactions = make(map[int]transaction)
+	var stale []int
+	for id, profile := range charger.installed {
+		if profile.ChargingProfilePurpose == enums.ChargingProfilePurposeTxProfile {
+			delete(charger.installed, id)
+			continue
+		}
+		if _, ok := charger.desired[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+	for id, profile := range charger.desired {
+		if profile.ChargingProfilePurpose == enums.ChargingProfilePurposeTxProfile {
+			delete(charger.desired, id)
+		}
+	}
+	desired := sortedProfiles(charger.desired)
+	m.mux.Unlock()
+
+	sort.Ints(stale)
+	var firstErr error
+	for _, id := range stale {
+		if err := m.clear(ctx, cpID, id); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("clearing profile %d: %w", id, err)
+		}
+	}
+	for _, profile := range desired {
+		if err := m.push(ctx, cpID, profile); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("setting profile %d: %w", profile.ChargingProfileId, err)
+		}
+	}
+	return firstErr
+}
+
+// HandleBootNotification wraps the handler of the BootNotification
+// requests, reconciling the charge point once it's accepted, e.g.
+//
+//	router.OnBootNotification(manager.HandleBootNotification(handleBoot))
+//
+// Reconcile runs in its own goroutine once the response was sent, see
+// cs.ChargePointRequestMetadata's AfterResponse, so that the charge point
+// knows it's accepted before its profiles are set. It must not be called
+// from a handler: the central system reads the responses of the charge
+// point in between its requests, so the SetChargingProfile and
+// ClearChargingProfile of Reconcile would time out. It gives up after the
+// reconcile timeout, see WithReconcileTimeout, so a charge point which
+// doesn't answer doesn't hold its calls forever.
+func (m *Manager) HandleBootNotification(handler func(ctx context.Context, req *cpreq.BootNotification, metadata cs.ChargePointRequestMetadata) (*cpresp.BootNotification, error)) func(ctx context.Context, req *cpreq.BootNotification, metadata cs.ChargePointRequestMetadata) (*cpresp.BootNotification, error) {
+	return func(ctx context.Context, req *cpreq.BootNotification, metadata cs.ChargePointRequestMetadata) (*cpresp.BootNotification, error) {
+		resp, err := handler(ctx, req, metadata)
+		if err != nil || resp == nil || resp.Status != enums.RegistrationStatusAccepted {
+			return resp, err
+		}
+		cpID := metadata.ChargePointID
+		metadata.AfterResponse(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), m.reconcileTimeout)
+			defer cancel()
+			if err := m.Reconcile(ctx, cpID); err != nil {
+				log.Error("Reconciling the charging profiles of %s: %v", cpID, err)
+			}
+		})
+		return resp, nil
+	}
+}
+
+// Expected is the composite schedule the connector should follow
+// for the duration from start, given the desired profiles
+func (m *Manager) Expected(cpID string, connectorID int, start time.Time, duration int, unit enums.ChargingRateUnit) (*csresp.ChargingSchedule, error) {
+	return m.connector(cpID, connectorID).CompositeSchedule(start, duration, unit)
+}
+
+// connector has the desired profiles of the connector, and
+// those of connector 0, the connector's first to win a tie
+func (m *Manager) connector(cpID string, connectorID int) *Connector {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	charger := m.charger(cpID)
+	connector := &Connector{}
+	for _, profile := range sortedProfiles(charger.desired) {
+		if profile.ConnectorId == connectorID {
+			connector.Profiles = append(connector.Profiles, profile.ChargingProfile)
+		}
+	}
+	if connectorID != 0 {
+		for _, profile := range sortedProfiles(charger.desired) {
+			if profile.ConnectorId == 0 && profile.ChargingProfilePurpose != enums.ChargingProfilePurposeTxProfile {
+				connector.Profiles = append(connector.Profiles, profile.ChargingProfile)
+			}
+		}
+	}
+	if tx, ok := charger.transactions[connectorID]; ok {
+		connector.TransactionId = tx.id
+		connector.TransactionStart = &tx.start
+	}
+	return connector
+}
+
+// Verify asks the charge point for the composite schedule of the
+// connector, and compares it with the expected one. The drift, if
+// any, is returned and given to the drift listener.
+func (m *Manager) Verify(ctx context.Context, cpID string, connectorID int, duration int, unit enums.ChargingRateUnit) (*Drift, error) {
+	client, err := m.client(cpID)
+	if err != nil {
+		return nil, err
+	}
+	requested := time.Now()
+	resp, err := client.GetCompositeSchedule(ctx, &csreq.GetCompositeSchedule{ConnectorId: connectorID, Duration: duration, ChargingRateUnit: unit})
+	if err != nil {
+		return nil, err
+	}
+	start := requested
+	if resp.ScheduleStart != nil {
+		start = *resp.ScheduleStart
+	} else if resp.ChargingSchedule != nil && resp.ChargingSchedule.StartSchedule != nil {
+		start = *resp.ChargingSchedule.StartSchedule
+	}
+	expected, err := m.Expected(cpID, connectorID, start, duration, unit)
+	if err != nil && !errors.Is(err, ErrNoSchedule) {
+		return nil, err
+	}
+	var actual *csresp.ChargingSchedule
+	if resp.Status == enums.GetCompositeScheduleStatusAccepted {
+		actual = resp.ChargingSchedule
+	}
+	if sameSchedule(expected, actual) {
+		return nil, nil
+	}
+	drift := &Drift{ChargePointID: cpID, ConnectorId: connectorID, Expected: expected, Actual: actual}
+	m.driftListener(*drift)
+	return drift, nil
+}
+
+// sameSchedule compares the limits of the schedules, up to a rounding
+func sameSchedule(expected, actual *csresp.ChargingSchedule) bool {
+	if expected == nil || actual == nil {
+		return expected == nil && (actual == nil || len(actual.ChargingSchedulePeriod) == 0)
+	}
+	if expected.ChargingRateUnit != actual.ChargingRateUnit {
+		return false
+	}
+	e, a := mergedPeriods(expected.ChargingSchedulePeriod), mergedPeriods(actual.ChargingSchedulePeriod)
+	if len(e) != len(a) {
+		return false
+	}
+	for i := range e {
+		if e[i].StartPeriod != a[i].StartPeriod || math.Abs(e[i].Limit-a[i].Limit) > 0.1+1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+// mergedPeriods drops the periods which don't change the limit
+func mergedPeriods(periods []*csresp.ChargingSchedulePeriodItems) []*csresp.ChargingSchedulePeriodItems {
+	var merged []*csresp.ChargingSchedulePeriodItems
+	for _, period := range periods {
+		if period == nil {
+			continue
+		}
+		if len(merged) > 0 && merged[len(merged)-1].Limit == period.Limit {
+			continue
+		}
+		merged = append(merged, period)
+	}
+	return merged
+}
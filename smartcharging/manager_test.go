@@ -0,0 +1,356 @@
+package smartcharging
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/cp"
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/internal/service"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeChargePoint keeps the profiles it's sent, like a charge point
+type fakeChargePoint struct {
+	mux     sync.Mutex
+	offline bool
+	reject  bool
+	// silent charge points never answer, the
+	// error of the call is sent once it gives up
+	silent   bool
+	gaveUp   chan error
+	profiles map[int]*csreq.SetChargingProfile
+	requests []string
+	// composite answered to GetCompositeSchedule
+	composite *csresp.GetCompositeSchedule
+}
+
+func newFakeChargePoint() *fakeChargePoint {
+	return &fakeChargePoint{profiles: make(map[int]*csreq.SetChargingProfile)}
+}
+
+func (cp *fakeChargePoint) Send(chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	return cp.SendContext(context.Background(), chargerID, req)
+}
+
+func (cp *fakeChargePoint) SendContext(ctx context.Context, chargerID string, req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+	cp.mux.Lock()
+	if cp.silent {
+		cp.mux.Unlock()
+		<-ctx.Done()
+		select {
+		case cp.gaveUp <- ctx.Err():
+		default:
+		}
+		return nil, ctx.Err()
+	}
+	defer cp.mux.Unlock()
+	if cp.offline {
+		return nil, errors.New("offline")
+	}
+	cp.requests = append(cp.requests, req.Action())
+	switch req := req.(type) {
+	case *csreq.SetChargingProfile:
+		if cp.reject {
+			return &csresp.SetChargingProfile{Status: enums.ChargingProfileStatusRejected}, nil
+		}
+		cp.profiles[req.CsChargingProfiles.ChargingProfileId] = req
+		return &csresp.SetChargingProfile{Status: enums.ChargingProfileStatusAccepted}, nil
+	case *csreq.ClearChargingProfile:
+		if _, ok := cp.profiles[req.Id]; !ok {
+			return &csresp.ClearChargingProfile{Status: enums.ClearChargingProfileStatusUnknown}, nil
+		}
+		delete(cp.profiles, req.Id)
+		return &csresp.ClearChargingProfile{Status: enums.ClearChargingProfileStatusAccepted}, nil
+	case *csreq.GetCompositeSchedule:
+		return cp.composite, nil
+	}
+	return nil, errors.New("unexpected request")
+}
+
+func (cp *fakeChargePoint) ids() []int {
+	cp.mux.Lock()
+	defer cp.mux.Unlock()
+	var ids []int
+	for id := range cp.profiles {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func newManager(cp *fakeChargePoint, options ...ManagerOption) *Manager {
+	return NewManager(func(cpID string) (service.ChargePoint, error) {
+		return cp, nil
+	}, options...)
+}
+
+func profileIDs(profiles []Profile) []int {
+	var ids []int
+	for _, profile := range profiles {
+		ids = append(ids, profile.ChargingProfileId)
+	}
+	return ids
+}
+
+func Test_ManagerSetAndClear(t *testing.T) {
+	ctx := context.Background()
+	cp := newFakeChargePoint()
+	m := newManager(cp)
+
+	assert.NoError(t, m.Set(ctx, "CP1", 0, dailyMaxProfile()))
+	assert.NoError(t, m.Set(ctx, "CP1", 1, relative(2, 0, enums.ChargingProfilePurposeTxDefaultProfile, schedule(enums.ChargingRateUnitA, period{0, 16, 0}))))
+	assert.ElementsMatch(t, []int{100, 2}, cp.ids())
+	assert.Equal(t, []int{100, 2}, profileIDs(m.Desired("CP1")))
+	assert.Equal(t, []int{100, 2}, profileIDs(m.Installed("CP1")))
+
+	assert.NoError(t, m.Clear(ctx, "CP1", 2))
+	assert.Equal(t, []int{100}, cp.ids())
+	assert.Equal(t, []int{100}, profileIDs(m.Desired("CP1")))
+	assert.Equal(t, []int{100}, profileIDs(m.Installed("CP1")))
+
+	t.Run("rejected", func(t *testing.T) {
+		cp.reject = true
+		defer func() { cp.reject = false }()
+		err := m.Set(ctx, "CP1", 1, relative(3, 0, enums.ChargingProfilePurposeTxDefaultProfile, schedule(enums.ChargingRateUnitA, period{0, 16, 0})))
+		assert.True(t, errors.Is(err, ErrProfileRejected), "unexpected error: %v", err)
+		assert.Equal(t, []int{100, 3}, profileIDs(m.Desired("CP1")))
+		assert.Equal(t, []int{100}, profileIDs(m.Installed("CP1")))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := m.Set(ctx, "CP1", 1, relative(4, 0, enums.ChargingProfilePurposeTxProfile, schedule(enums.ChargingRateUnitA, period{0, 16, 0})))
+		assert.True(t, errors.Is(err, csreq.ErrInvalidChargingProfile), "unexpected error: %v", err)
+		assert.Equal(t, []int{100, 3}, profileIDs(m.Desired("CP1")))
+	})
+}
+
+func Test_ManagerReconcile(t *testing.T) {
+	ctx := context.Background()
+	cp := newFakeChargePoint()
+	m := newManager(cp)
+	txDefault := relative(2, 0, enums.ChargingProfilePurposeTxDefaultProfile, schedule(enums.ChargingRateUnitA, period{0, 16, 0}))
+	stale := relative(3, 1, enums.ChargingProfilePurposeTxDefaultProfile, schedule(enums.ChargingRateUnitA, period{0, 6, 0}))
+	tx := relative(4, 0, enums.ChargingProfilePurposeTxProfile, schedule(enums.ChargingRateUnitA, period{0, 10, 0}))
+	tx.TransactionId = 42
+
+	assert.NoError(t, m.Set(ctx, "CP1", 0, dailyMaxProfile()))
+	assert.NoError(t, m.Set(ctx, "CP1", 1, stale))
+	m.StartTransaction("CP1", 1, 42, time.Now())
+	assert.NoError(t, m.Set(ctx, "CP1", 1, tx))
+
+	// the charge point is offline while the profiles change
+	cp.offline = true
+	assert.Error(t, m.Set(ctx, "CP1", 1, txDefault))
+	assert.Error(t, m.Clear(ctx, "CP1", 3))
+	assert.Equal(t, []int{100, 2, 4}, profileIDs(m.Desired("CP1")))
+	assert.Equal(t, []int{100, 3, 4}, profileIDs(m.Installed("CP1")))
+
+	// and reboots, dropping its transaction
+	cp.offline = false
+	cp.mux.Lock()
+	delete(cp.profiles, 4)
+	cp.requests = nil
+	cp.mux.Unlock()
+	assert.NoError(t, m.Reconcile(ctx, "CP1"))
+	assert.Equal(t, []string{"ClearChargingProfile", "SetChargingProfile", "SetChargingProfile"}, cp.requests)
+	assert.ElementsMatch(t, []int{100, 2}, cp.ids())
+	assert.Equal(t, []int{100, 2}, profileIDs(m.Desired("CP1")))
+	assert.Equal(t, []int{100, 2}, profileIDs(m.Installed("CP1")))
+
+	t.Run("offline", func(t *testing.T) {
+		cp.offline = true
+		defer func() { cp.offline = false }()
+		assert.Error(t, m.Reconcile(ctx, "CP1"))
+	})
+}
+
+func Test_ManagerBootNotification(t *testing.T) {
+	ctx := context.Background()
+	csys := cs.New()
+	m := NewManager(csys.GetServiceOf)
+	router := cs.NewRouter()
+	router.OnBootNotification(m.HandleBootNotification(func(ctx context.Context, req *cpreq.BootNotification, metadata cs.ChargePointRequestMetadata) (*cpresp.BootNotification, error) {
+		return &cpresp.BootNotification{Status: enums.RegistrationStatusAccepted, CurrentTime: time.Now(), Interval: 60}, nil
+	}))
+	csys.SetChargePointMessageHandler(router.Handle)
+	server := httptest.NewServer(csys)
+	defer server.Close()
+
+	// the profile is desired before the charge point connects
+	assert.Error(t, m.Set(ctx, "CP1", 0, dailyMaxProfile()))
+
+	profiles := make(chan *csreq.SetChargingProfile, 1)
+	cpctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cpoint, err := cp.New(cpctx, "CP1", "ws"+strings.TrimPrefix(server.URL, "http"), ocpp.V16, ocpp.JSON, nil, nil, func(req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		if req, ok := req.(*csreq.SetChargingProfile); ok {
+			profiles <- req
+			return &csresp.SetChargingProfile{Status: enums.ChargingProfileStatusAccepted}, nil
+		}
+		return nil, errors.New("not supported")
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	<-csys.WaitConnect("CP1")
+	resp, err := cpoint.Send("CP1", &cpreq.BootNotification{ChargePointVendor: "ACME", ChargePointModel: "Model 1"})
+	assert.NoError(t, err)
+	assert.IsType(t, &cpresp.BootNotification{}, resp)
+
+	select {
+	case req := <-profiles:
+		assert.Equal(t, 100, req.CsChargingProfiles.ChargingProfileId)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the profile wasn't pushed after the BootNotification")
+	}
+	// the response is handled once the charge point answered
+	for i := 0; i < 100 && len(m.Installed("CP1")) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []int{100}, profileIDs(m.Installed("CP1")))
+}
+
+func Test_ManagerBootNotificationOrder(t *testing.T) {
+	csys := cs.New()
+	m := NewManager(csys.GetServiceOf, WithReconcileTimeout(100*time.Millisecond))
+	router := cs.NewRouter()
+	router.OnBootNotification(m.HandleBootNotification(func(ctx context.Context, req *cpreq.BootNotification, metadata cs.ChargePointRequestMetadata) (*cpresp.BootNotification, error) {
+		return &cpresp.BootNotification{Status: enums.RegistrationStatusAccepted, CurrentTime: time.Now(), Interval: 60}, nil
+	}))
+	csys.SetChargePointMessageHandler(router.Handle)
+	server := httptest.NewServer(csys)
+	defer server.Close()
+	assert.Error(t, m.Set(context.Background(), "CP1", 0, dailyMaxProfile()))
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp1.6"}}
+	socket, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/CP1", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer socket.Close()
+	assert.NoError(t, socket.WriteMessage(websocket.TextMessage, []byte(`[2,"1","BootNotification",{"chargePointVendor":"ACME","chargePointModel":"Model 1"}]`)))
+
+	// the charge point is told it's accepted before its profiles are set
+	socket.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var frames []string
+	for i := 0; i < 2; i++ {
+		_, raw, err := socket.ReadMessage()
+		if !assert.NoError(t, err) {
+			return
+		}
+		frames = append(frames, string(raw))
+	}
+	assert.True(t, strings.HasPrefix(frames[0], `[3,"1",`), "unexpected frame: %s", frames[0])
+	assert.Contains(t, frames[1], `"SetChargingProfile"`)
+}
+
+func Test_ManagerBootNotificationTimeout(t *testing.T) {
+	cp := newFakeChargePoint()
+	m := newManager(cp, WithReconcileTimeout(50*time.Millisecond))
+	assert.NoError(t, m.Set(context.Background(), "CP1", 0, dailyMaxProfile()))
+
+	// the charge point reboots and ignores the profiles
+	cp.mux.Lock()
+	cp.silent = true
+	cp.gaveUp = make(chan error, 1)
+	cp.mux.Unlock()
+	handle := m.HandleBootNotification(func(ctx context.Context, req *cpreq.BootNotification, metadata cs.ChargePointRequestMetadata) (*cpresp.BootNotification, error) {
+		return &cpresp.BootNotification{Status: enums.RegistrationStatusAccepted, CurrentTime: time.Now(), Interval: 60}, nil
+	})
+	_, err := handle(context.Background(), &cpreq.BootNotification{}, cs.ChargePointRequestMetadata{ChargePointID: "CP1"})
+	assert.NoError(t, err)
+
+	select {
+	case err := <-cp.gaveUp:
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the reconciliation didn't give up")
+	}
+}
+
+func Test_ManagerStopTransaction(t *testing.T) {
+	ctx := context.Background()
+	cp := newFakeChargePoint()
+	m := newManager(cp)
+	tx := relative(4, 0, enums.ChargingProfilePurposeTxProfile, schedule(enums.ChargingRateUnitA, period{0, 10, 0}))
+	tx.TransactionId = 42
+	m.StartTransaction("CP1", 1, 42, time.Now())
+	assert.NoError(t, m.Set(ctx, "CP1", 1, tx))
+	m.StopTransaction("CP1", 1)
+	assert.Empty(t, m.Desired("CP1"))
+	assert.Empty(t, m.Installed("CP1"))
+}
+
+func Test_ManagerVerify(t *testing.T) {
+	ctx := context.Background()
+	start := *at("2020-06-01T07:00:00Z")
+	cp := newFakeChargePoint()
+	var drifts []Drift
+	m := newManager(cp, WithDriftListener(func(drift Drift) {
+		drifts = append(drifts, drift)
+	}))
+	assert.NoError(t, m.Set(ctx, "CP1", 0, dailyMaxProfile()))
+
+	composite := func(periods ...*csresp.ChargingSchedulePeriodItems) *csresp.GetCompositeSchedule {
+		return &csresp.GetCompositeSchedule{
+			Status:        enums.GetCompositeScheduleStatusAccepted,
+			ConnectorId:   1,
+			ScheduleStart: &start,
+			ChargingSchedule: &csresp.ChargingSchedule{
+				ChargingRateUnit:       enums.ChargingRateUnitW,
+				ChargingSchedulePeriod: periods,
+			},
+		}
+	}
+
+	t.Run("as expected", func(t *testing.T) {
+		cp.composite = composite(
+			&csresp.ChargingSchedulePeriodItems{StartPeriod: 0, Limit: 11000},
+			&csresp.ChargingSchedulePeriodItems{StartPeriod: 1800, Limit: 11000},
+			&csresp.ChargingSchedulePeriodItems{StartPeriod: 3600, Limit: 6000},
+		)
+		drift, err := m.Verify(ctx, "CP1", 1, 7200, enums.ChargingRateUnitW)
+		assert.NoError(t, err)
+		assert.Nil(t, drift)
+		assert.Empty(t, drifts)
+	})
+
+	t.Run("drifted", func(t *testing.T) {
+		cp.composite = composite(&csresp.ChargingSchedulePeriodItems{StartPeriod: 0, Limit: 11000})
+		drift, err := m.Verify(ctx, "CP1", 1, 7200, enums.ChargingRateUnitW)
+		assert.NoError(t, err)
+		if assert.NotNil(t, drift) && assert.Len(t, drifts, 1) {
+			assert.Equal(t, *drift, drifts[0])
+			assert.Equal(t, "CP1", drift.ChargePointID)
+			assert.Equal(t, 1, drift.ConnectorId)
+			assert.Len(t, drift.Expected.ChargingSchedulePeriod, 2)
+			assert.Len(t, drift.Actual.ChargingSchedulePeriod, 1)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		drifts = nil
+		cp.composite = &csresp.GetCompositeSchedule{Status: enums.GetCompositeScheduleStatusRejected}
+		drift, err := m.Verify(ctx, "CP1", 1, 7200, enums.ChargingRateUnitW)
+		assert.NoError(t, err)
+		if assert.NotNil(t, drift) {
+			assert.Nil(t, drift.Actual)
+		}
+		drift, err = m.Verify(ctx, "CP2", 1, 7200, enums.ChargingRateUnitW)
+		assert.NoError(t, err)
+		assert.Nil(t, drift, "nothing expected")
+		assert.Len(t, drifts, 1)
+	})
+}
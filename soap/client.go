@@ -2,6 +2,7 @@ package soap
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"errors"
 	"io/ioutil"
@@ -34,8 +35,17 @@ type CallOptions struct {
 	ChargeBoxIdentity string
 }
 
-// Call performs HTTP POST request
+// Call performs HTTP POST request, waiting
+// at most the default request timeout
 func (s *Client) Call(soapAction string, request messages.Request, response messages.Response, options *CallOptions) error {
+	ctx, cancel := context.WithTimeout(context.Background(), internal.DefaultRequestTimeout)
+	defer cancel()
+	return s.CallContext(ctx, soapAction, request, response, options)
+}
+
+// CallContext performs HTTP POST request, which
+// is aborted when the context is done
+func (s *Client) CallContext(ctx context.Context, soapAction string, request messages.Request, response messages.Response, options *CallOptions) error {
 	if len(s.url) == 0 {
 		return errors.New("no URL to request")
 	}
@@ -72,7 +82,7 @@ func (s *Client) Call(soapAction string, request messages.Request, response mess
 	log.Debug("Sending request with %d bytes", len(rawReq))
 	log.Debug("Sending raw request: %s", string(rawReq))
 
-	req, err := http.NewRequest("POST", s.url, buffer)
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, buffer)
 	if err != nil {
 		return err
 	}
@@ -88,7 +98,7 @@ func (s *Client) Call(soapAction string, request messages.Request, response mess
 		},
 	}
 
-	client := &http.Client{Transport: tr, Timeout: internal.DefaultRequestTimeout}
+	client := &http.Client{Transport: tr}
 	res, err := client.Do(req)
 	if err != nil {
 		return err
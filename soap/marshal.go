@@ -3,11 +3,12 @@ package soap
 import (
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/messages/req"
 )
 
 func Unmarshal(data []byte) (receivedEnvelope, error) {
@@ -29,6 +30,36 @@ func Marshal(data interface{}, err error, NS string) ([]byte, error) {
 	return xml.Marshal(respEnv)
 }
 
+// bodyTypes of the messages the body can hold, by element name,
+// built from the actions of both sides
+var bodyTypes = func() map[xml.Name]reflect.Type {
+	types := make(map[xml.Name]reflect.Type)
+	add := func(msg interface{}) {
+		t := reflect.TypeOf(msg).Elem()
+		field, ok := t.FieldByName("XMLName")
+		if !ok {
+			panic(fmt.Sprintf("no XMLName in %s", t))
+		}
+		// the tag is "namespace local"
+		tag := strings.Fields(field.Tag.Get("xml"))
+		types[xml.Name{Space: tag[0], Local: tag[1]}] = t
+	}
+	for _, side := range []ocpp.Side{ocpp.ChargePoint, ocpp.CentralSystem} {
+		for _, action := range req.Actions(side) {
+			request := req.FromSender(side, action)
+			add(request)
+			add(request.GetResponse())
+		}
+	}
+	return types
+}()
+
+// versions of OCPP by the namespace of their messages
+var versions = map[string]ocpp.Version{
+	"urn://Ocpp/Cs/2012/06/": ocpp.V15,
+	"urn://Ocpp/Cp/2012/06/": ocpp.V15,
+}
+
 func (b *receivedBody) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	// decode inner elements
 	for {
@@ -36,132 +67,28 @@ func (b *receivedBody) UnmarshalXML(d *xml.Decoder, start xml.StartElement) erro
 		if err != nil {
 			return err
 		}
-		var msg interface{}
 		switch tt := t.(type) {
 		case xml.StartElement:
-			switch tt.Name.Local {
-			case "Fault":
+			if tt.Name.Local == "Fault" {
 				fault := receivedFault{}
 				err := d.DecodeElement(&fault, &tt)
 				if err != nil {
 					return err
 				}
 				b.Fault = &fault
-			// requests coming from the charger
-			case "bootNotificationRequest":
-				msg = new(cpreq.BootNotification)
-			case "heartbeatRequest":
-				msg = new(cpreq.Heartbeat)
-			case "statusNotificationRequest":
-				msg = new(cpreq.StatusNotification)
-			case "meterValuesRequest":
-				msg = new(cpreq.MeterValues)
-			case "authorizeRequest":
-				msg = new(cpreq.Authorize)
-			case "startTransactionRequest":
-				msg = new(cpreq.StartTransaction)
-			case "stopTransactionRequest":
-				msg = new(cpreq.StopTransaction)
-			case "firmwareStatusNotificationRequest":
-				msg = new(cpreq.FirmwareStatusNotification)
-			case "diagnosticsStatusNotificationRequest":
-				msg = new(cpreq.DiagnosticsStatusNotification)
-
-			// responses coming from the central system
-			case "bootNotificationResponse":
-				msg = new(cpresp.BootNotification)
-			case "heartbeatResponse":
-				msg = new(cpresp.Heartbeat)
-			case "statusNotificationResponse":
-				msg = new(cpresp.StatusNotification)
-			case "meterValuesResponse":
-				msg = new(cpresp.MeterValues)
-			case "authorizeResponse":
-				msg = new(cpresp.Authorize)
-			case "startTransactionResponse":
-				msg = new(cpresp.StartTransaction)
-			case "stopTransactionResponse":
-				msg = new(cpresp.StopTransaction)
-			case "firmwareStatusNotificationResponse":
-				msg = new(cpresp.FirmwareStatusNotification)
-			case "diagnosticsStatusNotificationResponse":
-				msg = new(cpresp.DiagnosticsStatusNotification)
-
-			// requests coming from the central system
-			case "unlockConnectorRequest":
-				msg = new(csreq.UnlockConnector)
-			case "resetRequest":
-				msg = new(csreq.Reset)
-			case "changeAvailabilityRequest":
-				msg = new(csreq.ChangeAvailability)
-			case "getDiagnosticsRequest":
-				msg = new(csreq.GetDiagnostics)
-			case "clearCacheRequest":
-				msg = new(csreq.ClearCache)
-			case "updateFirmwareRequest":
-				msg = new(csreq.UpdateFirmware)
-			case "changeConfigurationRequest":
-				msg = new(csreq.ChangeConfiguration)
-			case "remoteStartTransactionRequest":
-				msg = new(csreq.RemoteStartTransaction)
-			case "remoteStopTransactionRequest":
-				msg = new(csreq.RemoteStopTransaction)
-			case "cancelReservationRequest":
-				msg = new(csreq.CancelReservation)
-			case "dataTransferRequest":
-				msg = new(csreq.DataTransfer)
-			case "getConfigurationRequest":
-				msg = new(csreq.GetConfiguration)
-			case "getLocalListVersionRequest":
-				msg = new(csreq.GetLocalListVersion)
-			case "reserveNowRequest":
-				msg = new(csreq.ReserveNow)
-			case "sendLocalListRequest":
-				msg = new(csreq.SendLocalList)
-
-			// responses coming from the charger
-			case "unlockConnectorResponse":
-				msg = new(csresp.UnlockConnector)
-			case "resetResponse":
-				msg = new(csresp.Reset)
-			case "changeAvailabilityResponse":
-				msg = new(csresp.ChangeAvailability)
-			case "getDiagnosticsResponse":
-				msg = new(csresp.GetDiagnostics)
-			case "clearCacheResponse":
-				msg = new(csresp.ClearCache)
-			case "updateFirmwareResponse":
-				msg = new(csresp.UpdateFirmware)
-			case "changeConfigurationResponse":
-				msg = new(csresp.ChangeConfiguration)
-			case "remoteStartTransactionResponse":
-				msg = new(csresp.RemoteStartTransaction)
-			case "remoteStopTransactionResponse":
-				msg = new(csresp.RemoteStopTransaction)
-			case "cancelReservationResponse":
-				msg = new(csresp.CancelReservation)
-			case "dataTransferResponse":
-				msg = new(csresp.DataTransfer)
-			case "getConfigurationResponse":
-				msg = new(csresp.GetConfiguration)
-			case "getLocalListVersionResponse":
-				msg = new(csresp.GetLocalListVersion)
-			case "reserveNowResponse":
-				msg = new(csresp.ReserveNow)
-			case "sendLocalListResponse":
-				msg = new(csresp.SendLocalList)
-			default:
+				continue
+			}
+			msgType, ok := bodyTypes[tt.Name]
+			if !ok {
 				return errors.New("not implemented unmarshal for this OCPP message:" + tt.Name.Local)
 			}
-			// known child element found, decode it
-			if msg != nil {
-				err = d.DecodeElement(msg, &tt)
-				if err != nil {
-					return err
-				}
-				b.Content = msg
-				msg = nil
+			msg := reflect.New(msgType).Interface()
+			err = d.DecodeElement(msg, &tt)
+			if err != nil {
+				return err
 			}
+			b.Content = msg
+			b.Namespace = tt.Name.Space
 		case xml.EndElement:
 			if tt == start.End() {
 				return nil
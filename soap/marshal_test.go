@@ -1,8 +1,17 @@
 package soap
 
 import (
-	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"encoding/xml"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/messages/req"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
 )
 
 const bootNotification = `
@@ -38,6 +47,9 @@ func TestSOAPUnmarshal(t *testing.T) {
 	if !ok {
 		t.Fatal("Could not assert message type")
 	}
+	if version := versions[env.Body.Namespace]; version != ocpp.V15 {
+		t.Fatalf("Expected version %s, got %q", ocpp.V15, version)
+	}
 }
 
 const meterValues = `
@@ -83,3 +95,159 @@ func TestMeterValues(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// roundTrip marshals the message in an envelope and unmarshals
+// it back, without the XMLName set by the decoding
+func roundTrip(t *testing.T, msg interface{}) interface{} {
+	raw, err := Marshal(msg, nil, "http://www.w3.org/2003/05/soap-envelope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := Unmarshal(raw)
+	if err != nil {
+		t.Fatalf("%v in %s", err, raw)
+	}
+	if env.Body.Content == nil {
+		t.Fatalf("no content in %s", raw)
+	}
+	reflect.ValueOf(env.Body.Content).Elem().FieldByName("XMLName").Set(reflect.ValueOf(xml.Name{}))
+	return env.Body.Content
+}
+
+func TestRoundTripAllActions(t *testing.T) {
+	for _, side := range []ocpp.Side{ocpp.ChargePoint, ocpp.CentralSystem} {
+		for _, action := range req.Actions(side) {
+			request := req.FromSender(side, action)
+			for _, msg := range []interface{}{request, request.GetResponse()} {
+				decoded := roundTrip(t, msg)
+				if reflect.TypeOf(decoded) != reflect.TypeOf(msg) {
+					t.Fatalf("%s of %s: decoded %T instead of %T", action, side, decoded, msg)
+				}
+			}
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	now := time.Date(2019, 4, 5, 21, 45, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	messages := []interface{}{
+		// requests coming from the charger
+		&cpreq.Authorize{IdTag: "TAG"},
+		&cpreq.BootNotification{ChargeBoxSerialNumber: "1", ChargePointModel: "MODEL-1000", ChargePointSerialNumber: "2", ChargePointVendor: "VENDOR", FirmwareVersion: "1.7.0", Iccid: "3", Imsi: "4", MeterSerialNumber: "5", MeterType: "AC"},
+		&cpreq.DataTransfer{VendorId: "com.acme", MessageId: "Tariff", Data: "0.25"},
+		&cpreq.DiagnosticsStatusNotification{Status: "Uploaded"},
+		&cpreq.FirmwareStatusNotification{Status: "Installed"},
+		&cpreq.Heartbeat{},
+		&cpreq.MeterValues{ConnectorId: 1, TransactionId: 42, MeterValue: []*cpreq.MeterValueItems{{
+			Timestamp:     now,
+			SampledValues: []*cpreq.SampledValue{{Value: "1337", Context: "Sample.Periodic", Format: "Raw", Location: "Outlet", Measurand: "Energy.Active.Import.Register", Unit: "Wh"}},
+		}}},
+		&cpreq.StartTransaction{ConnectorId: 1, IdTag: "TAG", MeterStart: 10, ReservationId: 7, Timestamp: now},
+		&cpreq.StatusNotification{ConnectorId: 1, ErrorCode: "NoError", Info: "fine", Status: "Available", Timestamp: &now, VendorErrorCode: "0", VendorId: "com.acme"},
+		&cpreq.StopTransaction{IdTag: "TAG", MeterStop: 20, Reason: "Local", Timestamp: later, TransactionId: 42, TransactionData: []*cpreq.TransactionDataItems{{
+			Timestamp:     later,
+			SampledValues: []*cpreq.SampledValue{{Value: "20", Unit: "Wh"}},
+		}}},
+
+		// responses coming from the central system
+		&cpresp.Authorize{IdTagInfo: &cpresp.IdTagInfo{Status: "Accepted", ExpiryDate: &later, ParentIdTag: "PARENT"}},
+		&cpresp.BootNotification{Status: "Accepted", CurrentTime: now, Interval: 60},
+		&cpresp.DataTransfer{Status: "Accepted", Data: "ok"},
+		&cpresp.DiagnosticsStatusNotification{},
+		&cpresp.FirmwareStatusNotification{},
+		&cpresp.Heartbeat{CurrentTime: now},
+		&cpresp.MeterValues{},
+		&cpresp.StartTransaction{IdTagInfo: &cpresp.IdTagInfo{Status: "Accepted"}, TransactionId: 42},
+		&cpresp.StatusNotification{},
+		&cpresp.StopTransaction{IdTagInfo: &cpresp.IdTagInfo{Status: "Accepted"}},
+
+		// requests coming from the central system
+		&csreq.CancelReservation{ReservationId: 7},
+		&csreq.ChangeAvailability{ConnectorId: 1, Type: "Inoperative"},
+		&csreq.ChangeConfiguration{Key: "HeartbeatInterval", Value: "60"},
+		&csreq.ClearCache{},
+		&csreq.ClearChargingProfile{ChargingProfilePurpose: "TxProfile", ConnectorId: 1, Id: 3, StackLevel: 2},
+		&csreq.DataTransfer{VendorId: "com.acme", MessageId: "Tariff", Data: "0.25"},
+		&csreq.GetCompositeSchedule{ChargingRateUnit: "A", ConnectorId: 1, Duration: 3600},
+		&csreq.GetConfiguration{Key: []string{"HeartbeatInterval", "MeterValueSampleInterval"}},
+		&csreq.GetDiagnostics{Location: "ftp://example.com/", Retries: 3, RetryInterval: 60, StartTime: &now, StopTime: &later},
+		&csreq.GetLocalListVersion{},
+		&csreq.RemoteStartTransaction{IdTag: "TAG", ConnectorId: 1, ChargingProfile: &csreq.ChargingProfile{
+			ChargingProfileId:      3,
+			ChargingProfileKind:    "Absolute",
+			ChargingProfilePurpose: "TxProfile",
+			StackLevel:             2,
+			ValidFrom:              &now,
+			ValidTo:                &later,
+			ChargingSchedule: &csreq.ChargingSchedule{
+				ChargingRateUnit:       "A",
+				StartSchedule:          &now,
+				ChargingSchedulePeriod: []*csreq.ChargingSchedulePeriodItems{{StartPeriod: 0, Limit: 16}, {StartPeriod: 1800, Limit: 8.5}},
+			},
+		}},
+		&csreq.RemoteStopTransaction{TransactionId: 42},
+		&csreq.ReserveNow{ConnectorId: 1, ExpiryDate: later, IdTag: "TAG", ParentIdTag: "PARENT", ReservationId: 7},
+		&csreq.Reset{Type: "Soft"},
+		&csreq.SendLocalList{ListVersion: 2, UpdateType: "Full", LocalAuthorizationList: []*csreq.LocalAuthorizationListItems{
+			{IdTag: "TAG", IdTagInfo: &csreq.IdTagInfo{Status: "Accepted", ExpiryDate: &later, ParentIdTag: "PARENT"}},
+			{IdTag: "OTHER"},
+		}},
+		&csreq.TriggerMessage{ConnectorId: 1, RequestedMessage: "StatusNotification"},
+		&csreq.UnlockConnector{ConnectorId: 1},
+		&csreq.UpdateFirmware{Location: "ftp://example.com/fw.bin", Retries: 3, RetrieveDate: later, RetryInterval: 60},
+		&csreq.SetChargingProfile{ConnectorId: 1, CsChargingProfiles: &csreq.ChargingProfile{
+			ChargingProfileId:      3,
+			ChargingProfileKind:    "Recurring",
+			ChargingProfilePurpose: "TxProfile",
+			RecurrencyKind:         "Daily",
+			StackLevel:             1,
+			TransactionId:          42,
+			ValidFrom:              &now,
+			ValidTo:                &later,
+			ChargingSchedule: &csreq.ChargingSchedule{
+				ChargingRateUnit:       "W",
+				ChargingSchedulePeriod: []*csreq.ChargingSchedulePeriodItems{{StartPeriod: 0, Limit: 11000, NumberPhases: 3}},
+				Duration:               3600,
+				MinChargingRate:        1400,
+				StartSchedule:          &now,
+			},
+		}},
+
+		// responses coming from the charger
+		&csresp.CancelReservation{Status: "Accepted"},
+		&csresp.ChangeAvailability{Status: "Scheduled"},
+		&csresp.ChangeConfiguration{Status: "RebootRequired"},
+		&csresp.ClearCache{Status: "Accepted"},
+		&csresp.ClearChargingProfile{Status: "Unknown"},
+		&csresp.DataTransfer{Status: "Accepted", Data: "ok"},
+		&csresp.GetCompositeSchedule{Status: "Accepted", ConnectorId: 1, ScheduleStart: &now, ChargingSchedule: &csresp.ChargingSchedule{
+			ChargingRateUnit:       "A",
+			Duration:               3600,
+			MinChargingRate:        6,
+			StartSchedule:          &now,
+			ChargingSchedulePeriod: []*csresp.ChargingSchedulePeriodItems{{StartPeriod: 0, Limit: 16, NumberPhases: 3}},
+		}},
+		&csresp.GetConfiguration{
+			ConfigurationKey: []*csresp.ConfigurationKeyItems{{Key: "HeartbeatInterval", Value: "60"}, {Key: "NumberOfConnectors", Readonly: true, Value: "2"}},
+			UnknownKey:       []string{"Unknown"},
+		},
+		&csresp.GetDiagnostics{FileName: "diagnostics.log"},
+		&csresp.GetLocalListVersion{ListVersion: 2},
+		&csresp.RemoteStartTransaction{Status: "Accepted"},
+		&csresp.RemoteStopTransaction{Status: "Rejected"},
+		&csresp.ReserveNow{Status: "Occupied"},
+		&csresp.Reset{Status: "Accepted"},
+		&csresp.SendLocalList{Status: "VersionMismatch"},
+		&csresp.TriggerMessage{Status: "NotImplemented"},
+		&csresp.UnlockConnector{Status: "Unlocked"},
+		&csresp.UpdateFirmware{},
+		&csresp.SetChargingProfile{Status: "Accepted"},
+	}
+	for _, msg := range messages {
+		decoded := roundTrip(t, msg)
+		if !reflect.DeepEqual(msg, decoded) {
+			t.Errorf("%T didn't round-trip:\n%+v\n%+v", msg, msg, decoded)
+		}
+	}
+}
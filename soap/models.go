@@ -2,6 +2,8 @@ package soap
 
 import (
 	"encoding/xml"
+
+	"github.com/michaelbironneau/go-ocpp"
 )
 
 type Envelope receivedEnvelope
@@ -27,13 +29,17 @@ type receivedHeader struct {
 	MessageID         string `xml:"MessageID"`
 	From              receivedFrom
 	ChargeBoxIdentity string `xml:"chargeBoxIdentity"`
+	// Version of OCPP of the request, from the namespace of the body
+	Version ocpp.Version `xml:"-"`
 }
 
 type receivedBody struct {
 	XMLName xml.Name `xml:"Body"`
 
 	Content interface{}
-	Fault   *receivedFault
+	// Namespace of the content
+	Namespace string
+	Fault     *receivedFault
 }
 
 type receivedFault struct {
@@ -14,6 +14,18 @@ import (
 )
 
 func Handle(w http.ResponseWriter, r *http.Request, handle ocpp.MessageHandler) error {
+	return HandleEnvelope(w, r, func(request messages.Request, header Header) (messages.Response, error) {
+		return handle(request, header.ChargeBoxIdentity)
+	})
+}
+
+// EnvelopeHandler handles a request along with the
+// WS-Addressing header of the envelope it came in
+type EnvelopeHandler func(request messages.Request, header Header) (messages.Response, error)
+
+// HandleEnvelope is like Handle, but gives the whole header
+// to the handler, e.g. to learn where the sender is reached
+func HandleEnvelope(w http.ResponseWriter, r *http.Request, handle EnvelopeHandler) error {
 	defer r.Body.Close()
 
 	rawReq, _ := ioutil.ReadAll(r.Body)
@@ -30,7 +42,12 @@ func Handle(w http.ResponseWriter, r *http.Request, handle ocpp.MessageHandler)
 		return errors.New("received message is not a request")
 	}
 
-	resp, err := handle(req, reqEnv.Header.ChargeBoxIdentity)
+	var header Header
+	if reqEnv.Header != nil {
+		header = Header(*reqEnv.Header)
+	}
+	header.Version = versions[reqEnv.Body.Namespace]
+	resp, err := handle(req, header)
 	if err != nil {
 		log.Error("couldn't handle request: %w", err)
 	}
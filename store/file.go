@@ -0,0 +1,101 @@
+package store
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File keeps the state in JSON files of a directory, one per record,
+// e.g. chargepoints/CP01.json, which it reads once it's opened
+type File struct {
+	*Memory
+	dir string
+}
+
+// NewFile keeps the state in the directory, created if it doesn't
+// exist, reading what's already there. Only one File may use it.
+func NewFile(dir string) (*File, error) {
+	f := &File{Memory: NewMemory(), dir: dir}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	kinds, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, kind := range kinds {
+		if !kind.IsDir() {
+			continue
+		}
+		if err := f.load(kind.Name()); err != nil {
+			return nil, err
+		}
+	}
+	f.Memory.persist = f.write
+	return f, nil
+}
+
+func (f *File) load(kind string) error {
+	files, err := ioutil.ReadDir(filepath.Join(f.dir, kind))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		// the temporary files of the writes that didn't complete start with a dot
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		id, err := url.PathUnescape(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return fmt.Errorf("%s of %s: %w", name, kind, err)
+		}
+		record, err := ioutil.ReadFile(filepath.Join(f.dir, kind, name))
+		if err != nil {
+			return err
+		}
+		f.Memory.set(kind, id, record)
+	}
+	return nil
+}
+
+// write the record to a temporary file, renamed
+// once it's written so it's never half written
+func (f *File) write(kind, id string, record []byte) error {
+	dir := filepath.Join(f.dir, kind)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := fileName(id)
+	tmp, err := ioutil.TempFile(dir, "."+name+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(record); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+// fileName of the record, its ID being escaped
+// so it can't be a path, nor a hidden file
+func fileName(id string) string {
+	name := url.PathEscape(id)
+	if strings.HasPrefix(name, ".") {
+		name = "%2E" + name[1:]
+	}
+	return name + ".json"
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/transactions"
+)
+
+// kinds of records
+const (
+	chargePointRecords        = "chargepoints"
+	transactionRecords        = "transactions"
+	authorizationListRecords  = "authorization"
+	configurationRecords      = "configuration"
+	sequenceRecords           = "sequence"
+	lastTransactionIDRecordID = "transaction"
+)
+
+// Memory keeps the state in memory, it's lost on restart. The records
+// are kept encoded, so they're not changed by what's saved or got.
+type Memory struct {
+	mux     sync.Mutex
+	records map[string]map[string][]byte
+	// lastTransactionID is the last one allocated or stored
+	lastTransactionID int32
+	// persist, if set, persists each record saved, which
+	// isn't saved when it fails
+	persist func(kind, id string, record []byte) error
+}
+
+// NewMemory keeps the state in memory
+func NewMemory() *Memory {
+	return &Memory{records: make(map[string]map[string][]byte)}
+}
+
+func (m *Memory) save(kind, id string, value interface{}) error {
+	record, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding %s %s: %w", kind, id, err)
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.saveRecord(kind, id, record)
+}
+
+func (m *Memory) saveRecord(kind, id string, record []byte) error {
+	if m.persist != nil {
+		if err := m.persist(kind, id, record); err != nil {
+			return err
+		}
+	}
+	m.set(kind, id, record)
+	return nil
+}
+
+func (m *Memory) set(kind, id string, record []byte) {
+	if m.records[kind] == nil {
+		m.records[kind] = make(map[string][]byte)
+	}
+	m.records[kind][id] = record
+	var transactionID int
+	switch kind {
+	case transactionRecords:
+		transactionID, _ = strconv.Atoi(id)
+	case sequenceRecords:
+		transactionID, _ = strconv.Atoi(string(record))
+	}
+	if int32(transactionID) > m.lastTransactionID {
+		m.lastTransactionID = int32(transactionID)
+	}
+}
+
+// get decodes the record into value, telling whether there's one
+func (m *Memory) get(kind, id string, value interface{}) (bool, error) {
+	m.mux.Lock()
+	record, ok := m.records[kind][id]
+	m.mux.Unlock()
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(record, value); err != nil {
+		return false, fmt.Errorf("decoding %s %s: %w", kind, id, err)
+	}
+	return true, nil
+}
+
+// all decodes the records of the kind, newValue
+// giving the value each is decoded into
+func (m *Memory) all(kind string, newValue func() interface{}) error {
+	m.mux.Lock()
+	records := make(map[string][]byte, len(m.records[kind]))
+	for id, record := range m.records[kind] {
+		records[id] = record
+	}
+	m.mux.Unlock()
+	for id, record := range records {
+		if err := json.Unmarshal(record, newValue()); err != nil {
+			return fmt.Errorf("decoding %s %s: %w", kind, id, err)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) SaveChargePoint(info *cs.ChargePointInfo) error {
+	return m.save(chargePointRecords, info.ID, info)
+}
+
+func (m *Memory) ChargePoint(cpID string) (*cs.ChargePointInfo, error) {
+	info := &cs.ChargePointInfo{}
+	if ok, err := m.get(chargePointRecords, cpID, info); !ok || err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ChargePoints by ID
+func (m *Memory) ChargePoints() ([]*cs.ChargePointInfo, error) {
+	var infos []*cs.ChargePointInfo
+	err := m.all(chargePointRecords, func() interface{} {
+		infos = append(infos, &cs.ChargePointInfo{})
+		return infos[len(infos)-1]
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos, nil
+}
+
+func (m *Memory) SaveTransaction(tx *transactions.Transaction) error {
+	return m.save(transactionRecords, strconv.Itoa(int(tx.ID)), tx)
+}
+
+func (m *Memory) Transaction(id int32) (*transactions.Transaction, error) {
+	tx := &transactions.Transaction{}
+	if ok, err := m.get(transactionRecords, strconv.Itoa(int(id)), tx); !ok || err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (m *Memory) Transactions(cpID string) ([]*transactions.Transaction, error) {
+	var list []*transactions.Transaction
+	err := m.all(transactionRecords, func() interface{} {
+		list = append(list, &transactions.Transaction{})
+		return list[len(list)-1]
+	})
+	if err != nil {
+		return nil, err
+	}
+	filtered := list[:0]
+	for _, tx := range list {
+		if cpID == "" || tx.ChargePointID == cpID {
+			filtered = append(filtered, tx)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].ID < filtered[j].ID
+	})
+	return filtered, nil
+}
+
+// NextTransactionID follows the last one allocated, and
+// the transactions stored, e.g. by an earlier allocator
+func (m *Memory) NextTransactionID() (int32, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	next := m.lastTransactionID + 1
+	if err := m.saveRecord(sequenceRecords, lastTransactionIDRecordID, []byte(strconv.Itoa(int(next)))); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+func (m *Memory) SaveAuthorizationList(list *AuthorizationList) error {
+	return m.save(authorizationListRecords, list.Name, list)
+}
+
+func (m *Memory) AuthorizationList(name string) (*AuthorizationList, error) {
+	list := &AuthorizationList{}
+	if ok, err := m.get(authorizationListRecords, name, list); !ok || err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (m *Memory) SaveConfiguration(snapshot *ConfigurationSnapshot) error {
+	return m.save(configurationRecords, snapshot.ChargePointID, snapshot)
+}
+
+func (m *Memory) Configuration(cpID string) (*ConfigurationSnapshot, error) {
+	snapshot := &ConfigurationSnapshot{}
+	if ok, err := m.get(configurationRecords, cpID, snapshot); !ok || err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
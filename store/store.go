@@ -0,0 +1,80 @@
+// Package store keeps the state of a central system: its charge points,
+// transactions, authorization lists and configuration snapshots, in
+// memory or in JSON files so it survives restarts
+package store
+
+import (
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/transactions"
+)
+
+// Store keeps the state of a central system, the getters returning nil
+// when there's nothing stored. It's both a cs.Store and a transactions.Store,
+// allocating the transaction IDs, e.g.
+//
+//	csys := cs.New(cs.WithStore(st))
+//	tracker := transactions.NewTracker(transactions.WithStore(st), transactions.WithIDAllocator(st))
+type Store interface {
+	cs.Store
+	transactions.Store
+	transactions.IDAllocator
+
+	// Transactions completed on the charge point, every one
+	// of them when cpID is empty, by ID
+	Transactions(cpID string) ([]*transactions.Transaction, error)
+
+	SaveAuthorizationList(list *AuthorizationList) error
+	AuthorizationList(name string) (*AuthorizationList, error)
+
+	SaveConfiguration(snapshot *ConfigurationSnapshot) error
+	// Configuration is the last snapshot of the charge point configuration
+	Configuration(cpID string) (*ConfigurationSnapshot, error)
+}
+
+// AuthorizationList is a list of id tags, e.g.
+// the local authorization list of charge points
+type AuthorizationList struct {
+	// Name of the list, e.g. the charge points it's sent to
+	Name string `json:"name"`
+	// Version of the list, as sent in SendLocalList
+	Version int                                  `json:"version"`
+	IdTags  []*csreq.LocalAuthorizationListItems `json:"idTags,omitempty"`
+	Updated time.Time                            `json:"updated"`
+}
+
+// IdTagInfo of the id tag, nil when it's not in the list
+func (list *AuthorizationList) IdTagInfo(idTag string) *csreq.IdTagInfo {
+	for _, item := range list.IdTags {
+		if item != nil && item.IdTag == idTag {
+			return item.IdTagInfo
+		}
+	}
+	return nil
+}
+
+// ConfigurationSnapshot is the configuration of a
+// charge point, as given by GetConfiguration
+type ConfigurationSnapshot struct {
+	ChargePointID string                          `json:"chargePointId"`
+	Keys          []*csresp.ConfigurationKeyItems `json:"keys,omitempty"`
+	Time          time.Time                       `json:"time"`
+}
+
+// NewConfigurationSnapshot takes the snapshot of the GetConfiguration response
+func NewConfigurationSnapshot(cpID string, resp *csresp.GetConfiguration, at time.Time) *ConfigurationSnapshot {
+	return &ConfigurationSnapshot{ChargePointID: cpID, Keys: resp.ConfigurationKey, Time: at}
+}
+
+// Value of the configuration key, if it's in the snapshot
+func (snapshot *ConfigurationSnapshot) Value(key string) (string, bool) {
+	for _, item := range snapshot.Keys {
+		if item != nil && item.Key == key {
+			return item.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/michaelbironneau/go-ocpp/transactions"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+
+func chargePoint(cpID string) *cs.ChargePointInfo {
+	return &cs.ChargePointInfo{
+		ID:        cpID,
+		Transport: ocpp.JSON,
+		Version:   ocpp.V16,
+		Connected: true,
+		LastSeen:  now,
+		Boot: &cs.BootInfo{
+			Notification: &cpreq.BootNotification{ChargePointVendor: "ACME", ChargePointModel: "Model 1"},
+			Status:       enums.RegistrationStatusAccepted,
+			Time:         now,
+		},
+		Connectors: map[int]*cs.ConnectorStatus{
+			1: {Notification: &cpreq.StatusNotification{ConnectorId: 1, ErrorCode: "NoError", Status: enums.ChargePointStatusCharging}, Time: now},
+		},
+	}
+}
+
+func transaction(id int32, cpID string) *transactions.Transaction {
+	stopped := now.Add(time.Hour)
+	return &transactions.Transaction{
+		ID:            id,
+		ChargePointID: cpID,
+		ConnectorId:   1,
+		IdTag:         "TAG1",
+		MeterStart:    1000,
+		Started:       now,
+		MeterValues: []*cpreq.MeterValueItems{{Timestamp: now.Add(30 * time.Minute), SampledValues: []*cpreq.SampledValue{
+			{Measurand: enums.MeasurandEnergyActiveImportRegister, Unit: enums.UnitOfMeasureWh, Value: "2500"},
+		}}},
+		MeterStop: 4000,
+		Stopped:   &stopped,
+		Reason:    enums.ReasonLocal,
+	}
+}
+
+// testStore checks the store is empty, then fills it
+func testStore(t *testing.T, store Store) {
+	info, err := store.ChargePoint("CP01")
+	assert.NoError(t, err)
+	assert.Nil(t, info)
+
+	for _, cpID := range []string{"CP02", "CP01", "../CP03"} {
+		assert.NoError(t, store.SaveChargePoint(chargePoint(cpID)))
+	}
+	info, err = store.ChargePoint("CP01")
+	assert.NoError(t, err)
+	assert.Equal(t, chargePoint("CP01"), info)
+	// what's got is a copy
+	info.Connectors[2] = &cs.ConnectorStatus{}
+	info, _ = store.ChargePoint("CP01")
+	assert.Len(t, info.Connectors, 1)
+	infos, err := store.ChargePoints()
+	assert.NoError(t, err)
+	if assert.Len(t, infos, 3) {
+		assert.Equal(t, []string{"../CP03", "CP01", "CP02"}, []string{infos[0].ID, infos[1].ID, infos[2].ID})
+	}
+
+	id, err := store.NextTransactionID()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), id)
+	assert.NoError(t, store.SaveTransaction(transaction(1, "CP01")))
+	// e.g. the transactions of another allocator
+	assert.NoError(t, store.SaveTransaction(transaction(7, "CP02")))
+	id, _ = store.NextTransactionID()
+	assert.Equal(t, int32(8), id)
+	tx, err := store.Transaction(1)
+	assert.NoError(t, err)
+	assert.Equal(t, transaction(1, "CP01"), tx)
+	assert.Equal(t, 3000.0, tx.Energy())
+	tx, err = store.Transaction(2)
+	assert.NoError(t, err)
+	assert.Nil(t, tx)
+	list, err := store.Transactions("CP02")
+	assert.NoError(t, err)
+	assert.Equal(t, []*transactions.Transaction{transaction(7, "CP02")}, list)
+	list, _ = store.Transactions("")
+	assert.Len(t, list, 2)
+
+	expiry := now.Add(24 * time.Hour)
+	assert.NoError(t, store.SaveAuthorizationList(&AuthorizationList{
+		Name:    "depot",
+		Version: 3,
+		IdTags: []*csreq.LocalAuthorizationListItems{
+			{IdTag: "TAG1", IdTagInfo: &csreq.IdTagInfo{Status: enums.AuthorizationStatusAccepted, ExpiryDate: &expiry, ParentIdTag: "FLEET"}},
+		},
+		Updated: now,
+	}))
+	authList, err := store.AuthorizationList("depot")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, authList.Version)
+	assert.Equal(t, "FLEET", authList.IdTagInfo("TAG1").ParentIdTag)
+	assert.Nil(t, authList.IdTagInfo("TAG2"))
+	authList, err = store.AuthorizationList("other")
+	assert.NoError(t, err)
+	assert.Nil(t, authList)
+
+	assert.NoError(t, store.SaveConfiguration(NewConfigurationSnapshot("CP01", &csresp.GetConfiguration{
+		ConfigurationKey: []*csresp.ConfigurationKeyItems{{Key: "HeartbeatInterval", Value: "60"}},
+	}, now)))
+	snapshot, err := store.Configuration("CP01")
+	assert.NoError(t, err)
+	value, ok := snapshot.Value("HeartbeatInterval")
+	assert.True(t, ok)
+	assert.Equal(t, "60", value)
+	_, ok = snapshot.Value("MeterValueSampleInterval")
+	assert.False(t, ok)
+}
+
+func Test_Memory(t *testing.T) {
+	testStore(t, NewMemory())
+}
+
+func Test_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFile(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	testStore(t, store)
+	// the ID can't escape the directory
+	_, err = os.Stat(filepath.Join(dir, "chargepoints", "%2E.%2FCP03.json"))
+	assert.NoError(t, err)
+	// a write that didn't complete
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "chargepoints", ".CP04.json.123"), []byte("{"), 0644))
+
+	// reopened, e.g. once the central system restarted
+	store, err = NewFile(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	info, err := store.ChargePoint("CP01")
+	assert.NoError(t, err)
+	assert.Equal(t, chargePoint("CP01"), info)
+	infos, _ := store.ChargePoints()
+	assert.Len(t, infos, 3)
+	tx, err := store.Transaction(7)
+	assert.NoError(t, err)
+	assert.Equal(t, transaction(7, "CP02"), tx)
+	id, _ := store.NextTransactionID()
+	assert.Equal(t, int32(9), id)
+	authList, _ := store.AuthorizationList("depot")
+	assert.Equal(t, 3, authList.Version)
+	snapshot, _ := store.Configuration("CP01")
+	assert.Equal(t, now, snapshot.Time)
+}
+
+func Test_FileCorrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "chargepoints"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "chargepoints", "CP01.json"), []byte("{"), 0644))
+	store, err := NewFile(dir)
+	assert.NoError(t, err)
+	_, err = store.ChargePoint("CP01")
+	assert.Error(t, err)
+	_, err = store.ChargePoints()
+	assert.Error(t, err)
+}
+
+func Test_StoreTracker(t *testing.T) {
+	store := NewMemory()
+	tracker := transactions.NewTracker(transactions.WithStore(store), transactions.WithIDAllocator(store))
+	tx, err := tracker.Start("CP01", &cpreq.StartTransaction{ConnectorId: 1, IdTag: "TAG1", MeterStart: 1000, Timestamp: now})
+	assert.NoError(t, err)
+	_, err = tracker.Stop("CP01", &cpreq.StopTransaction{TransactionId: int(tx.ID), MeterStop: 3000, Timestamp: now.Add(time.Hour)})
+	assert.NoError(t, err)
+	stored, err := store.Transaction(tx.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, 2000.0, stored.Energy())
+	}
+}
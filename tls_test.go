@@ -0,0 +1,154 @@
+package ocpp_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/cp"
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCA signs the certificates of the TLS tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert, key, pool}
+}
+
+func (ca *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func Test_TLS(t *testing.T) {
+	ca := newTestCA(t)
+	csys := cs.New()
+	metadatas := make(chan cs.ChargePointRequestMetadata, 1)
+	csys.SetChargePointMessageHandler(func(req cpreq.ChargePointRequest, metadata cs.ChargePointRequestMetadata) (cpresp.ChargePointResponse, error) {
+		metadatas <- metadata
+		return &cpresp.Heartbeat{}, nil
+	})
+	server := httptest.NewUnstartedServer(csys)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, "localhost", x509.ExtKeyUsageServerAuth)},
+		ClientCAs:    ca.pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	csysURL := "wss" + strings.TrimPrefix(server.URL, "https")
+	cshandler := func(req csreq.CentralSystemRequest) (csresp.CentralSystemResponse, error) {
+		return nil, errors.New("not supported")
+	}
+	clientCert := ca.issue(t, "tlsCP", x509.ExtKeyUsageClientAuth)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newChargePoint := func(identity string, options ...cp.Option) (cp.ChargePoint, error) {
+		return cp.New(ctx, identity, csysURL, ocpp.V16, ocpp.JSON, nil, nil, cshandler, options...)
+	}
+
+	t.Run("server certificate only", func(t *testing.T) {
+		cpoint, err := newChargePoint("plainCP", cp.WithTLSConfig(&tls.Config{RootCAs: ca.pool}))
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = cpoint.Send("plainCP", &cpreq.Heartbeat{})
+		assert.NoError(t, err)
+		metadata := <-metadatas
+		assert.Equal(t, "plainCP", metadata.ChargePointID)
+		assert.Equal(t, "", metadata.CertificateIdentity)
+	})
+
+	t.Run("identity from the client certificate", func(t *testing.T) {
+		cpoint, err := newChargePoint("", cp.WithTLSConfig(&tls.Config{RootCAs: ca.pool}), cp.WithClientCertificate(clientCert))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "tlsCP", cpoint.Identity())
+		_, err = cpoint.Send("tlsCP", &cpreq.Heartbeat{})
+		assert.NoError(t, err)
+		metadata := <-metadatas
+		assert.Equal(t, "tlsCP", metadata.ChargePointID)
+		assert.Equal(t, "tlsCP", metadata.CertificateIdentity)
+	})
+
+	t.Run("identity not matching the client certificate", func(t *testing.T) {
+		_, err := newChargePoint("otherCP", cp.WithClientCertificate(clientCert), cp.WithTLSConfig(&tls.Config{RootCAs: ca.pool}))
+		assert.Error(t, err)
+	})
+
+	t.Run("untrusted central system", func(t *testing.T) {
+		_, err := newChargePoint("plainCP")
+		assert.Error(t, err)
+	})
+
+	t.Run("untrusted client certificate", func(t *testing.T) {
+		otherCA := newTestCA(t)
+		_, err := newChargePoint("", cp.WithTLSConfig(&tls.Config{RootCAs: ca.pool}), cp.WithClientCertificate(otherCA.issue(t, "tlsCP", x509.ExtKeyUsageClientAuth)))
+		assert.Error(t, err)
+	})
+}
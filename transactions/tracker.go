@@ -0,0 +1,345 @@
+package transactions
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/internal/log"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+)
+
+// IDAllocator allocates the IDs of the transactions started
+type IDAllocator interface {
+	NextTransactionID() (int32, error)
+}
+
+// Sequence allocates increasing transaction IDs
+type Sequence struct {
+	mux  sync.Mutex
+	last int32
+}
+
+// NewSequence allocates the IDs following last, e.g.
+// the last one allocated before the CS restarted
+func NewSequence(last int32) *Sequence {
+	return &Sequence{last: last}
+}
+
+func (s *Sequence) NextTransactionID() (int32, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.last++
+	return s.last, nil
+}
+
+// Store keeps the completed transactions
+type Store interface {
+	SaveTransaction(tx *Transaction) error
+	// Transaction is the completed transaction of the ID, nil when it's unknown
+	Transaction(id int32) (*Transaction, error)
+}
+
+// Listener is called with each completed transaction
+type Listener func(tx Transaction)
+
+// IdTagInfoFunc gives the IdTagInfo of the id tag starting
+// or stopping a transaction on the charge point
+type IdTagInfoFunc func(ctx context.Context, cpID, idTag string) (*cpresp.IdTagInfo, error)
+
+// recentlyCompleted is how many completed transactions are remembered,
+// so a StopTransaction sent again, its response being lost, is ignored
+const recentlyCompleted = 256
+
+// Tracker follows the transactions of the charge points,
+// from their StartTransaction to their StopTransaction
+type Tracker struct {
+	mux       sync.Mutex
+	ids       IDAllocator
+	store     Store
+	listeners []Listener
+	idTagInfo IdTagInfoFunc
+	running   map[int32]*Transaction
+	// connectors are the transactions last started on each connector
+	connectors map[connector]int32
+	completed  map[int32]bool
+	// completedOrder is the order of the completed transactions, the oldest first
+	completedOrder []int32
+}
+
+type connector struct {
+	chargePointID string
+	connectorID   int
+}
+
+// Option configures the tracker
+type Option func(*Tracker)
+
+// WithIDAllocator allocates the transaction IDs with the allocator
+// instead of a sequence from 1, which restarts with the CS
+func WithIDAllocator(ids IDAllocator) Option {
+	return func(t *Tracker) {
+		t.ids = ids
+	}
+}
+
+// WithStore saves the completed transactions to the store
+func WithStore(store Store) Option {
+	return func(t *Tracker) {
+		t.store = store
+	}
+}
+
+// WithListener calls the listener with the completed transactions,
+// once they're saved to the store when there's one
+func WithListener(listener Listener) Option {
+	return func(t *Tracker) {
+		t.listeners = append(t.listeners, listener)
+	}
+}
+
+// WithIdTagInfo gives the IdTagInfo of the StartTransaction and
+// StopTransaction responses, every id tag being accepted otherwise
+func WithIdTagInfo(idTagInfo IdTagInfoFunc) Option {
+	return func(t *Tracker) {
+		t.idTagInfo = idTagInfo
+	}
+}
+
+// NewTracker tracks the transactions, see Register
+// to handle the requests of the charge points
+func NewTracker(options ...Option) *Tracker {
+	t := &Tracker{
+		ids: NewSequence(0),
+		idTagInfo: func(ctx context.Context, cpID, idTag string) (*cpresp.IdTagInfo, error) {
+			return &cpresp.IdTagInfo{Status: enums.AuthorizationStatusAccepted}, nil
+		},
+		running:    make(map[int32]*Transaction),
+		connectors: make(map[connector]int32),
+		completed:  make(map[int32]bool),
+	}
+	for _, option := range options {
+		option(t)
+	}
+	return t
+}
+
+// Start starts the transaction of the StartTransaction request,
+// allocating its ID. The transaction already started is returned
+// when the charge point sends the request again.
+func (t *Tracker) Start(cpID string, req *cpreq.StartTransaction) (Transaction, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	key := connector{chargePointID: cpID, connectorID: req.ConnectorId}
+	if tx, ok := t.running[t.connectors[key]]; ok &&
+		tx.IdTag == req.IdTag && tx.MeterStart == req.MeterStart && tx.Started.Equal(req.Timestamp) {
+		return tx.copy(), nil
+	}
+	id, err := t.ids.NextTransactionID()
+	if err != nil {
+		return Transaction{}, err
+	}
+	tx := &Transaction{
+		ID:            id,
+		ChargePointID: cpID,
+		ConnectorId:   req.ConnectorId,
+		IdTag:         req.IdTag,
+		ReservationId: req.ReservationId,
+		MeterStart:    req.MeterStart,
+		Started:       req.Timestamp,
+	}
+	t.running[id] = tx
+	// a transaction still running on the connector keeps running
+	// until its StopTransaction, which the charge point queued
+	t.connectors[key] = id
+	return tx.copy(), nil
+}
+
+// MeterValues attaches the meter values to their transaction,
+// telling whether it's one of the running transactions
+func (t *Tracker) MeterValues(cpID string, req *cpreq.MeterValues) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	tx, ok := t.running[req.TransactionId]
+	if !ok || req.TransactionId == 0 || tx.ChargePointID != cpID {
+		return false
+	}
+	tx.MeterValues = append(tx.MeterValues, req.MeterValue...)
+	return true
+}
+
+// Stop completes the transaction of the StopTransaction request, saving it
+// to the store and calling the listeners. When the store fails, it keeps
+// running so the charge point sends the request again. A transaction that
+// isn't running, e.g. started before the CS restarted, is completed from the
+// request alone, unless it's already completed or running on another charge
+// point: the request is then ignored and a zero Transaction returned.
+func (t *Tracker) Stop(cpID string, req *cpreq.StopTransaction) (Transaction, error) {
+	t.mux.Lock()
+	id := int32(req.TransactionId)
+	tx, ok := t.running[id]
+	if ok && tx.ChargePointID != cpID {
+		t.mux.Unlock()
+		return Transaction{}, nil
+	}
+	if !ok && t.completed[id] {
+		t.mux.Unlock()
+		return Transaction{}, nil
+	}
+	if !ok && t.store != nil {
+		// it may have been completed before the CS restarted
+		saved, err := t.store.Transaction(id)
+		if err != nil {
+			t.mux.Unlock()
+			return Transaction{}, err
+		}
+		if saved != nil {
+			t.remember(id)
+			t.mux.Unlock()
+			return Transaction{}, nil
+		}
+	}
+	if !ok {
+		tx = &Transaction{ID: id, ChargePointID: cpID, IdTag: req.IdTag}
+	}
+	stopped := *tx
+	stopped.MeterValues = append(append([]*cpreq.MeterValueItems(nil), tx.MeterValues...), transactionData(req)...)
+	stopped.MeterStop = req.MeterStop
+	timestamp := req.Timestamp
+	stopped.Stopped = &timestamp
+	stopped.StopIdTag = req.IdTag
+	stopped.Reason = req.Reason
+	if stopped.Reason == "" {
+		stopped.Reason = enums.ReasonLocal
+	}
+	if t.store != nil {
+		if err := t.store.SaveTransaction(&stopped); err != nil {
+			t.mux.Unlock()
+			return Transaction{}, err
+		}
+	}
+	delete(t.running, id)
+	key := connector{chargePointID: tx.ChargePointID, connectorID: tx.ConnectorId}
+	if t.connectors[key] == id {
+		delete(t.connectors, key)
+	}
+	t.remember(id)
+	listeners := t.listeners
+	t.mux.Unlock()
+	for _, listener := range listeners {
+		listener(stopped.copy())
+	}
+	return stopped, nil
+}
+
+func transactionData(req *cpreq.StopTransaction) []*cpreq.MeterValueItems {
+	var meterValues []*cpreq.MeterValueItems
+	for _, data := range req.TransactionData {
+		if data != nil {
+			meterValues = append(meterValues, &cpreq.MeterValueItems{SampledValues: data.SampledValues, Timestamp: data.Timestamp})
+		}
+	}
+	return meterValues
+}
+
+func (t *Tracker) remember(id int32) {
+	t.completed[id] = true
+	t.completedOrder = append(t.completedOrder, id)
+	if len(t.completedOrder) > recentlyCompleted {
+		delete(t.completed, t.completedOrder[0])
+		t.completedOrder = t.completedOrder[1:]
+	}
+}
+
+// Active is the transaction running on the connector, if there's one
+func (t *Tracker) Active(cpID string, connectorID int) (Transaction, bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	tx, ok := t.running[t.connectors[connector{chargePointID: cpID, connectorID: connectorID}]]
+	if !ok {
+		return Transaction{}, false
+	}
+	return tx.copy(), true
+}
+
+// Transaction is the running transaction of the ID, if there's one
+func (t *Tracker) Transaction(id int32) (Transaction, bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	tx, ok := t.running[id]
+	if !ok {
+		return Transaction{}, false
+	}
+	return tx.copy(), true
+}
+
+// Running are the running transactions, by ID
+func (t *Tracker) Running() []Transaction {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	list := make([]Transaction, 0, len(t.running))
+	for _, tx := range t.running {
+		list = append(list, tx.copy())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// HandleStartTransaction is the handler of the StartTransaction requests,
+// answering with the ID of the transaction, which is started even when
+// the id tag isn't accepted: the charge point then stops it
+func (t *Tracker) HandleStartTransaction(ctx context.Context, req *cpreq.StartTransaction, metadata cs.ChargePointRequestMetadata) (*cpresp.StartTransaction, error) {
+	idTagInfo, err := t.idTagInfo(ctx, metadata.ChargePointID, req.IdTag)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.Start(metadata.ChargePointID, req)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("Transaction %d started on connector %d of %s", tx.ID, tx.ConnectorId, tx.ChargePointID)
+	return &cpresp.StartTransaction{IdTagInfo: idTagInfo, TransactionId: tx.ID}, nil
+}
+
+// HandleMeterValues is the handler of the MeterValues requests
+func (t *Tracker) HandleMeterValues(ctx context.Context, req *cpreq.MeterValues, metadata cs.ChargePointRequestMetadata) (*cpresp.MeterValues, error) {
+	if req.TransactionId != 0 && !t.MeterValues(metadata.ChargePointID, req) {
+		log.Debug("Meter values of %s for unknown transaction %d", metadata.ChargePointID, req.TransactionId)
+	}
+	return &cpresp.MeterValues{}, nil
+}
+
+// HandleStopTransaction is the handler of the StopTransaction requests,
+// answering with the IdTagInfo of the id tag stopping it, if there's one
+func (t *Tracker) HandleStopTransaction(ctx context.Context, req *cpreq.StopTransaction, metadata cs.ChargePointRequestMetadata) (*cpresp.StopTransaction, error) {
+	resp := &cpresp.StopTransaction{}
+	if req.IdTag != "" {
+		idTagInfo, err := t.idTagInfo(ctx, metadata.ChargePointID, req.IdTag)
+		if err != nil {
+			return nil, err
+		}
+		resp.IdTagInfo = idTagInfo
+	}
+	tx, err := t.Stop(metadata.ChargePointID, req)
+	if err != nil {
+		log.Error("Saving transaction %d of %s: %v", req.TransactionId, metadata.ChargePointID, err)
+		return nil, err
+	}
+	if tx.ID != 0 {
+		log.Debug("Transaction %d stopped with %.0f Wh delivered", tx.ID, tx.Energy())
+	}
+	return resp, nil
+}
+
+// Register handles the StartTransaction, MeterValues
+// and StopTransaction requests of the router
+func (t *Tracker) Register(router *cs.Router) {
+	router.OnStartTransaction(t.HandleStartTransaction)
+	router.OnMeterValues(t.HandleMeterValues)
+	router.OnStopTransaction(t.HandleStopTransaction)
+}
@@ -0,0 +1,282 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/cs"
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	mux          sync.Mutex
+	err          error
+	transactions []*Transaction
+}
+
+func (s *fakeStore) SaveTransaction(tx *Transaction) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.transactions = append(s.transactions, tx)
+	return nil
+}
+
+func (s *fakeStore) Transaction(id int32) (*Transaction, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, tx := range s.transactions {
+		if tx.ID == id {
+			return tx, nil
+		}
+	}
+	return nil, nil
+}
+
+func metadata(cpID string) cs.ChargePointRequestMetadata {
+	return cs.ChargePointRequestMetadata{ChargePointID: cpID}
+}
+
+func startTransaction(connectorID int, idTag string, meterStart int) *cpreq.StartTransaction {
+	return &cpreq.StartTransaction{ConnectorId: connectorID, IdTag: idTag, MeterStart: meterStart, Timestamp: start}
+}
+
+func meterValues(txID int32, values ...*cpreq.MeterValueItems) *cpreq.MeterValues {
+	return &cpreq.MeterValues{ConnectorId: 1, TransactionId: txID, MeterValue: values}
+}
+
+func Test_TrackerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{}
+	var completed []Transaction
+	tracker := NewTracker(WithStore(store), WithListener(func(tx Transaction) {
+		completed = append(completed, tx)
+	}))
+
+	resp, err := tracker.HandleStartTransaction(ctx, startTransaction(1, "TAG1", 1000), metadata("CP01"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.TransactionId)
+	assert.Equal(t, enums.AuthorizationStatusAccepted, resp.IdTagInfo.Status)
+	resp, err = tracker.HandleStartTransaction(ctx, startTransaction(1, "TAG2", 500), metadata("CP02"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.TransactionId)
+
+	tx, ok := tracker.Active("CP01", 1)
+	assert.True(t, ok)
+	assert.Equal(t, "TAG1", tx.IdTag)
+	_, ok = tracker.Active("CP01", 2)
+	assert.False(t, ok)
+	assert.Len(t, tracker.Running(), 2)
+
+	_, err = tracker.HandleMeterValues(ctx, meterValues(1, sample(10, register("2000", enums.UnitOfMeasureWh))), metadata("CP01"))
+	assert.NoError(t, err)
+	tx, _ = tracker.Transaction(1)
+	assert.Equal(t, 1000.0, tx.Energy())
+	// the meter values of another charge point, or without transaction, aren't attached
+	assert.False(t, tracker.MeterValues("CP02", meterValues(1, sample(20, register("9000", enums.UnitOfMeasureWh)))))
+	assert.False(t, tracker.MeterValues("CP01", meterValues(0, sample(20, register("9000", enums.UnitOfMeasureWh)))))
+
+	stopResp, err := tracker.HandleStopTransaction(ctx, &cpreq.StopTransaction{
+		TransactionId: 1,
+		IdTag:         "TAG1",
+		MeterStop:     4000,
+		Timestamp:     start.Add(time.Hour),
+		TransactionData: []*cpreq.TransactionDataItems{
+			{Timestamp: start.Add(30 * time.Minute), SampledValues: []*cpreq.SampledValue{register("3000", enums.UnitOfMeasureWh)}},
+		},
+	}, metadata("CP01"))
+	assert.NoError(t, err)
+	assert.Equal(t, enums.AuthorizationStatusAccepted, stopResp.IdTagInfo.Status)
+	_, ok = tracker.Active("CP01", 1)
+	assert.False(t, ok)
+	assert.Len(t, tracker.Running(), 1)
+
+	assert.Len(t, store.transactions, 1)
+	assert.Len(t, completed, 1)
+	record := completed[0]
+	assert.Equal(t, "CP01", record.ChargePointID)
+	assert.Equal(t, 3000.0, record.Energy())
+	assert.Equal(t, time.Hour, record.Duration(time.Time{}))
+	assert.Equal(t, enums.ReasonLocal, record.Reason)
+	assert.Len(t, record.MeterValues, 2)
+
+	// sent again, its response being lost
+	_, err = tracker.HandleStopTransaction(ctx, &cpreq.StopTransaction{TransactionId: 1, MeterStop: 4000, Timestamp: start.Add(time.Hour)}, metadata("CP01"))
+	assert.NoError(t, err)
+	assert.Len(t, completed, 1)
+}
+
+func Test_TrackerStartSentAgain(t *testing.T) {
+	tracker := NewTracker()
+	first, err := tracker.Start("CP01", startTransaction(1, "TAG1", 1000))
+	assert.NoError(t, err)
+	again, err := tracker.Start("CP01", startTransaction(1, "TAG1", 1000))
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+
+	// a new transaction on the connector, its StopTransaction being queued
+	next, err := tracker.Start("CP01", startTransaction(1, "TAG2", 2000))
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.ID, next.ID)
+	tx, _ := tracker.Active("CP01", 1)
+	assert.Equal(t, next.ID, tx.ID)
+	_, err = tracker.Stop("CP01", &cpreq.StopTransaction{TransactionId: int(first.ID), MeterStop: 1500})
+	assert.NoError(t, err)
+	tx, _ = tracker.Active("CP01", 1)
+	assert.Equal(t, next.ID, tx.ID)
+}
+
+func Test_TrackerUnknownTransaction(t *testing.T) {
+	var completed []Transaction
+	tracker := NewTracker(WithListener(func(tx Transaction) {
+		completed = append(completed, tx)
+	}))
+	tx, err := tracker.Stop("CP01", &cpreq.StopTransaction{
+		TransactionId: 42,
+		IdTag:         "TAG1",
+		MeterStop:     4000,
+		Reason:        enums.ReasonEVDisconnected,
+		TransactionData: []*cpreq.TransactionDataItems{
+			{Timestamp: start, SampledValues: []*cpreq.SampledValue{register("1000", enums.UnitOfMeasureWh)}},
+			{Timestamp: start.Add(time.Hour), SampledValues: []*cpreq.SampledValue{register("4000", enums.UnitOfMeasureWh)}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(42), tx.ID)
+	assert.Equal(t, "TAG1", tx.IdTag)
+	assert.Equal(t, enums.ReasonEVDisconnected, tx.Reason)
+	assert.Equal(t, 3000.0, tx.Energy())
+	assert.Len(t, completed, 1)
+}
+
+func Test_TrackerOtherChargePoint(t *testing.T) {
+	tracker := NewTracker()
+	tx, err := tracker.Start("CP01", startTransaction(1, "TAG1", 1000))
+	assert.NoError(t, err)
+	// the ID is one of another charge point's transactions
+	stopped, err := tracker.Stop("CP02", &cpreq.StopTransaction{TransactionId: int(tx.ID), MeterStop: 2000})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), stopped.ID)
+	_, ok := tracker.Active("CP01", 1)
+	assert.True(t, ok)
+
+	stopped, err = tracker.Stop("CP01", &cpreq.StopTransaction{TransactionId: int(tx.ID), MeterStop: 2000})
+	assert.NoError(t, err)
+	assert.Equal(t, tx.ID, stopped.ID)
+	assert.Equal(t, 1000.0, stopped.Energy())
+}
+
+func Test_TrackerCompletedBeforeRestart(t *testing.T) {
+	store := &fakeStore{}
+	tracker := NewTracker(WithStore(store))
+	tx, err := tracker.Start("CP01", startTransaction(1, "TAG1", 1000))
+	assert.NoError(t, err)
+	stop := &cpreq.StopTransaction{TransactionId: int(tx.ID), MeterStop: 2000}
+	_, err = tracker.Stop("CP01", stop)
+	assert.NoError(t, err)
+
+	// the CS restarted, then the charge point sent the request again
+	tracker = NewTracker(WithStore(store))
+	stopped, err := tracker.Stop("CP01", stop)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), stopped.ID)
+	if assert.Len(t, store.transactions, 1) {
+		assert.Equal(t, 1000, store.transactions[0].MeterStart)
+	}
+
+}
+
+func Test_TrackerStoreFailure(t *testing.T) {
+	store := &fakeStore{err: errors.New("disk full")}
+	tracker := NewTracker(WithStore(store))
+	tx, err := tracker.Start("CP01", startTransaction(1, "TAG1", 1000))
+	assert.NoError(t, err)
+	_, err = tracker.HandleStopTransaction(context.Background(), &cpreq.StopTransaction{TransactionId: int(tx.ID), MeterStop: 2000}, metadata("CP01"))
+	assert.Error(t, err)
+	// it keeps running, the charge point sending it again
+	_, ok := tracker.Active("CP01", 1)
+	assert.True(t, ok)
+
+	store.err = nil
+	_, err = tracker.Stop("CP01", &cpreq.StopTransaction{TransactionId: int(tx.ID), MeterStop: 2000})
+	assert.NoError(t, err)
+	assert.Len(t, store.transactions, 1)
+	assert.Equal(t, 1000.0, store.transactions[0].Energy())
+}
+
+type allocator int32
+
+func (a *allocator) NextTransactionID() (int32, error) {
+	if *a < 0 {
+		return 0, errors.New("no ID left")
+	}
+	*a += 10
+	return int32(*a), nil
+}
+
+func Test_TrackerOptions(t *testing.T) {
+	ids := allocator(100)
+	tracker := NewTracker(WithIDAllocator(&ids), WithIdTagInfo(func(ctx context.Context, cpID, idTag string) (*cpresp.IdTagInfo, error) {
+		if idTag == "ERROR" {
+			return nil, errors.New("unreachable")
+		}
+		return &cpresp.IdTagInfo{Status: enums.AuthorizationStatusBlocked}, nil
+	}))
+	ctx := context.Background()
+	resp, err := tracker.HandleStartTransaction(ctx, startTransaction(1, "TAG1", 0), metadata("CP01"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(110), resp.TransactionId)
+	// started even though it's blocked, the charge point stopping it
+	assert.Equal(t, enums.AuthorizationStatusBlocked, resp.IdTagInfo.Status)
+	assert.Len(t, tracker.Running(), 1)
+
+	_, err = tracker.HandleStartTransaction(ctx, startTransaction(2, "ERROR", 0), metadata("CP01"))
+	assert.Error(t, err)
+	ids = -1
+	_, err = tracker.HandleStartTransaction(ctx, startTransaction(2, "TAG2", 0), metadata("CP01"))
+	assert.Error(t, err)
+	assert.Len(t, tracker.Running(), 1)
+
+	// no IdTagInfo without an id tag
+	stopResp, err := tracker.HandleStopTransaction(ctx, &cpreq.StopTransaction{TransactionId: 110}, metadata("CP01"))
+	assert.NoError(t, err)
+	assert.Nil(t, stopResp.IdTagInfo)
+}
+
+func Test_TrackerRegister(t *testing.T) {
+	router := cs.NewRouter()
+	NewTracker().Register(router)
+	resp, err := router.Handle(startTransaction(1, "TAG1", 0), metadata("CP01"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.(*cpresp.StartTransaction).TransactionId)
+}
+
+func Test_SequenceConcurrency(t *testing.T) {
+	sequence := NewSequence(41)
+	ids := make(chan int32, 100)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, _ := sequence.NextTransactionID()
+			ids <- id
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	seen := make(map[int32]bool)
+	for id := range ids {
+		assert.False(t, seen[id])
+		assert.True(t, id > 41 && id <= 141)
+		seen[id] = true
+	}
+}
@@ -0,0 +1,146 @@
+package transactions
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+)
+
+// Transaction is a charging session on a connector of a charge point
+type Transaction struct {
+	ID            int32  `json:"id"`
+	ChargePointID string `json:"chargePointId"`
+	ConnectorId   int    `json:"connectorId"`
+	IdTag         string `json:"idTag"`
+	ReservationId int    `json:"reservationId,omitempty"`
+	// MeterStart is the energy register when the transaction
+	// started, in Wh, Started being zero when the transaction
+	// is only known from its StopTransaction
+	MeterStart int       `json:"meterStart"`
+	Started    time.Time `json:"started"`
+	// MeterValues sent during the transaction, along with
+	// the transaction data of its StopTransaction
+	MeterValues []*cpreq.MeterValueItems `json:"meterValues,omitempty"`
+	// the rest is set once it's stopped
+	MeterStop int          `json:"meterStop,omitempty"`
+	Stopped   *time.Time   `json:"stopped,omitempty"`
+	StopIdTag string       `json:"stopIdTag,omitempty"`
+	Reason    enums.Reason `json:"reason,omitempty"`
+}
+
+// Running tells whether the transaction isn't stopped yet
+func (tx *Transaction) Running() bool {
+	return tx.Stopped == nil
+}
+
+// Duration of the transaction, until now while it's running
+func (tx *Transaction) Duration(now time.Time) time.Duration {
+	if tx.Started.IsZero() {
+		return 0
+	}
+	if tx.Stopped != nil {
+		return tx.Stopped.Sub(tx.Started)
+	}
+	return now.Sub(tx.Started)
+}
+
+func (tx *Transaction) copy() Transaction {
+	c := *tx
+	c.MeterValues = append([]*cpreq.MeterValueItems(nil), tx.MeterValues...)
+	return c
+}
+
+// Energy delivered by the transaction in Wh: the difference between
+// MeterStop, or the latest energy register of its meter values while
+// it's running, and MeterStart. When they're unknown or inconsistent,
+// e.g. the meter was replaced, it's the span of the energy registers
+// of its meter values, or the sum of their energy intervals.
+func (tx *Transaction) Energy() float64 {
+	first, last, ok := tx.registers()
+	if !tx.Started.IsZero() {
+		end, known := float64(tx.MeterStop), !tx.Running()
+		if tx.Running() && ok {
+			end, known = last, true
+		}
+		if known && end >= float64(tx.MeterStart) {
+			return end - float64(tx.MeterStart)
+		}
+	}
+	if ok && last >= first {
+		return last - first
+	}
+	return tx.intervals()
+}
+
+// registers are the first and latest energy registers
+// of the meter values, in Wh, if there's any
+func (tx *Transaction) registers() (first, last float64, ok bool) {
+	var firstAt, lastAt time.Time
+	for _, meterValue := range tx.MeterValues {
+		if meterValue == nil {
+			continue
+		}
+		for _, sample := range meterValue.SampledValues {
+			// the default measurand is the energy register
+			if sample == nil || (sample.Measurand != "" && sample.Measurand != enums.MeasurandEnergyActiveImportRegister) {
+				continue
+			}
+			value, valid := energy(sample)
+			if !valid {
+				continue
+			}
+			if !ok || meterValue.Timestamp.Before(firstAt) {
+				first, firstAt = value, meterValue.Timestamp
+			}
+			if !ok || !meterValue.Timestamp.Before(lastAt) {
+				last, lastAt = value, meterValue.Timestamp
+			}
+			ok = true
+		}
+	}
+	return first, last, ok
+}
+
+// intervals is the sum of the energy intervals of the meter values, in Wh
+func (tx *Transaction) intervals() float64 {
+	total := 0.0
+	for _, meterValue := range tx.MeterValues {
+		if meterValue == nil {
+			continue
+		}
+		for _, sample := range meterValue.SampledValues {
+			if sample == nil || sample.Measurand != enums.MeasurandEnergyActiveImportInterval {
+				continue
+			}
+			if value, valid := energy(sample); valid {
+				total += value
+			}
+		}
+	}
+	return total
+}
+
+// energy is the value of the sample in Wh, skipping the ones
+// of a single phase, or measured elsewhere than the outlet
+func energy(sample *cpreq.SampledValue) (float64, bool) {
+	if sample.Phase != "" || sample.Format == enums.ValueFormatSignedData {
+		return 0, false
+	}
+	if sample.Location != "" && sample.Location != enums.LocationOutlet {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(sample.Value), 64)
+	if err != nil {
+		return 0, false
+	}
+	switch sample.Unit {
+	case "", enums.UnitOfMeasureWh:
+		return value, true
+	case enums.UnitOfMeasureKWh:
+		return value * 1000, true
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package transactions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/enums"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+
+// sample is a meter value of a minute after the start
+func sample(minutes int, values ...*cpreq.SampledValue) *cpreq.MeterValueItems {
+	return &cpreq.MeterValueItems{Timestamp: start.Add(time.Duration(minutes) * time.Minute), SampledValues: values}
+}
+
+func register(value string, unit enums.UnitOfMeasure) *cpreq.SampledValue {
+	return &cpreq.SampledValue{Measurand: enums.MeasurandEnergyActiveImportRegister, Unit: unit, Value: value}
+}
+
+func Test_Energy(t *testing.T) {
+	stopped := start.Add(time.Hour)
+	cases := []struct {
+		Name        string
+		Transaction Transaction
+		Energy      float64
+	}{
+		{"meter stop", Transaction{MeterStart: 1000, Started: start, MeterStop: 8500, Stopped: &stopped}, 7500},
+		{"running", Transaction{MeterStart: 1000, Started: start, MeterValues: []*cpreq.MeterValueItems{
+			sample(10, register("2000", enums.UnitOfMeasureWh)),
+			sample(20, register("3.5", enums.UnitOfMeasureKWh)),
+		}}, 2500},
+		{"running without meter values", Transaction{MeterStart: 1000, Started: start}, 0},
+		{"default measurand and unit", Transaction{MeterStart: 1000, Started: start, MeterValues: []*cpreq.MeterValueItems{
+			sample(10, &cpreq.SampledValue{Value: "1600"}),
+		}}, 600},
+		{"meter values out of order", Transaction{MeterStart: 1000, Started: start, MeterValues: []*cpreq.MeterValueItems{
+			sample(20, register("3000", enums.UnitOfMeasureWh)),
+			sample(10, register("2000", enums.UnitOfMeasureWh)),
+		}}, 2000},
+		{"phases and other measurands ignored", Transaction{MeterStart: 1000, Started: start, MeterValues: []*cpreq.MeterValueItems{
+			sample(10,
+				register("2000", enums.UnitOfMeasureWh),
+				&cpreq.SampledValue{Measurand: enums.MeasurandEnergyActiveImportRegister, Phase: enums.PhaseL1, Value: "900"},
+				&cpreq.SampledValue{Measurand: enums.MeasurandPowerActiveImport, Unit: enums.UnitOfMeasureW, Value: "7400"},
+			),
+		}}, 1000},
+		{"unknown start", Transaction{MeterStop: 8500, Stopped: &stopped, MeterValues: []*cpreq.MeterValueItems{
+			sample(0, register("1000", enums.UnitOfMeasureWh)),
+			sample(60, register("8500", enums.UnitOfMeasureWh)),
+		}}, 7500},
+		{"meter replaced", Transaction{MeterStart: 90000, Started: start, MeterStop: 500, Stopped: &stopped, MeterValues: []*cpreq.MeterValueItems{
+			sample(30, register("100", enums.UnitOfMeasureWh)),
+			sample(60, register("500", enums.UnitOfMeasureWh)),
+		}}, 400},
+		{"intervals", Transaction{MeterStop: 8500, Stopped: &stopped, MeterValues: []*cpreq.MeterValueItems{
+			sample(30, &cpreq.SampledValue{Measurand: enums.MeasurandEnergyActiveImportInterval, Unit: enums.UnitOfMeasureKWh, Value: "1.5"}),
+			sample(60, &cpreq.SampledValue{Measurand: enums.MeasurandEnergyActiveImportInterval, Value: "500"}),
+		}}, 2000},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Energy, c.Transaction.Energy())
+		})
+	}
+}
+
+func Test_Duration(t *testing.T) {
+	stopped := start.Add(time.Hour)
+	tx := Transaction{Started: start}
+	assert.True(t, tx.Running())
+	assert.Equal(t, 10*time.Minute, tx.Duration(start.Add(10*time.Minute)))
+	tx.Stopped = &stopped
+	assert.False(t, tx.Running())
+	assert.Equal(t, time.Hour, tx.Duration(start.Add(2*time.Hour)))
+	assert.Equal(t, time.Duration(0), (&Transaction{Stopped: &stopped}).Duration(stopped))
+}
@@ -3,6 +3,7 @@ package ws
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/enums"
 	"github.com/gorilla/websocket"
 )
 
@@ -31,20 +33,36 @@ type Conn struct {
 	cancelCtx context.CancelFunc
 	*websocket.Conn
 	sendMux      sync.Mutex
-	sentMessages map[MessageID]*CallMessage
+	pending      *pendingCalls
+	queue        *callQueue
 	requests     chan struct {
 		messages.Request
 		MessageID
 		ChargerID string
 	}
-	responsesOf map[MessageID]chan CallResponse
+	// version negotiated through the websocket subprotocol
+	version ocpp.Version
+	// side of the connection, the peer being the other one
+	side ocpp.Side
+	// requestTimeout bounds the calls sent without a deadline
+	requestTimeout time.Duration
+	// schemaValidation of the payloads sent and received
+	schemaValidation bool
 }
 
-func newConn(socket *websocket.Conn) *Conn {
+var (
+	ErrorNoSupportedSubprotocol = errors.New("none of the requested OCPP subprotocols is supported")
+)
+
+func newConn(socket *websocket.Conn, version ocpp.Version, side ocpp.Side) *Conn {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Conn{
-		Conn:         socket,
-		sentMessages: make(map[MessageID]*CallMessage, 0),
+		Conn:           socket,
+		version:        version,
+		side:           side,
+		requestTimeout: internal.DefaultRequestTimeout,
+		pending:      newPendingCalls(),
+		queue:        newCallQueue(),
 		requests: make(chan struct {
 			messages.Request
 			MessageID
@@ -52,19 +70,40 @@ func newConn(socket *websocket.Conn) *Conn {
 		}, 0),
 		ctx:         ctx,
 		cancelCtx:   cancel,
-		responsesOf: make(map[MessageID]chan CallResponse),
 	}
 }
 
+// Dial connects to the central system, advertising the
+// OCPP subprotocol of the given version. The central system
+// must echo it back, otherwise the connection is refused.
 func Dial(csURL string, version ocpp.Version, h http.Header) (*Conn, error) {
+	return DialTLS(csURL, version, h, nil)
+}
+
+// DialTLS is like Dial, with the TLS configuration used for wss:// URLs,
+// e.g. the CA of the central system or the charge point certificate
+func DialTLS(csURL string, version ocpp.Version, h http.Header, config *tls.Config) (*Conn, error) {
+	protocol := ocppVersionToProtocol(version)
+	if protocol == "" {
+		return nil, fmt.Errorf("no websocket subprotocol for OCPP version %s", version)
+	}
 	dialer := websocket.Dialer{
-		//Subprotocols: []string{ocppVersionToProtocol(version)},
+		Subprotocols:    []string{protocol},
+		TLSClientConfig: config,
 	}
-	socket, _, err := dialer.Dial(csURL, h)
+	socket, resp, err := dialer.Dial(csURL, h)
 	if err != nil {
+		if resp != nil {
+			// e.g. the charge point wasn't authorized
+			return nil, fmt.Errorf("%w: %s", err, resp.Status)
+		}
 		return nil, err
 	}
-	return newConn(socket), err
+	if socket.Subprotocol() != protocol {
+		socket.Close()
+		return nil, fmt.Errorf("central system answered with subprotocol %q: %w", socket.Subprotocol(), ErrorNoSupportedSubprotocol)
+	}
+	return newConn(socket, version, ocpp.ChargePoint), nil
 }
 
 var upgrader = websocket.Upgrader{
@@ -85,14 +124,95 @@ func ocppVersionToProtocol(version ocpp.Version) string {
 	return ""
 }
 
+func protocolToOCPPVersion(protocol string) ocpp.Version {
+	switch protocol {
+	case "ocpp1.5":
+		return ocpp.V15
+	case "ocpp1.6":
+		return ocpp.V16
+	}
+	return ""
+}
+
+// negotiateVersion picks the first of the supported versions
+// (in order of preference) that the charge point requested
+// as a subprotocol, protocols we don't know (e.g. ocpp2.0.1)
+// are ignored
+func negotiateVersion(requestedProtocols []string, supportedVersions []ocpp.Version) (ocpp.Version, bool) {
+	requested := make(map[ocpp.Version]bool, len(requestedProtocols))
+	for _, protocol := range requestedProtocols {
+		if v := protocolToOCPPVersion(protocol); v != "" {
+			requested[v] = true
+		}
+	}
+	for _, v := range supportedVersions {
+		if requested[v] {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Handshake upgrades the request to a websocket connection, echoing
+// back the best OCPP subprotocol among the supported versions.
+// If the charge point didn't request any of them, the upgrade
+// is refused with a 400 Bad Request.
 func Handshake(w http.ResponseWriter, r *http.Request, supportedVersions []ocpp.Version) (*Conn, error) {
+	requestedProtocols := websocket.Subprotocols(r)
+	version, ok := negotiateVersion(requestedProtocols, supportedVersions)
+	if !ok {
+		http.Error(w, ErrorNoSupportedSubprotocol.Error(), http.StatusBadRequest)
+		return nil, fmt.Errorf("requested subprotocols %v: %w", requestedProtocols, ErrorNoSupportedSubprotocol)
+	}
 	upgraderHeader := http.Header{}
-	//for _, v := range supportedVersions {
-	//	upgraderHeader.Add("Sec-WebSocket-Protocol", ocppVersionToProtocol(v))
-	//}
+	upgraderHeader.Set("Sec-WebSocket-Protocol", ocppVersionToProtocol(version))
 	socket, err := upgrader.Upgrade(w, r, upgraderHeader)
-	conn := newConn(socket)
-	return conn, err
+	if err != nil {
+		return nil, err
+	}
+	return newConn(socket, version, ocpp.CentralSystem), nil
+}
+
+// Version returns the OCPP version negotiated for this connection
+func (c *Conn) Version() ocpp.Version {
+	return c.version
+}
+
+// Side of the connection: the charge point
+// dials it, the central system accepts it
+func (c *Conn) Side() ocpp.Side {
+	return c.side
+}
+
+// peer is the side at the other end of the connection
+func (c *Conn) peer() ocpp.Side {
+	if c.side == ocpp.ChargePoint {
+		return ocpp.CentralSystem
+	}
+	return ocpp.ChargePoint
+}
+
+// SetRequestTimeout sets how long a call sent without a deadline waits
+// for its response, including the time spent in the outbound queue
+func (c *Conn) SetRequestTimeout(timeout time.Duration) {
+	c.requestTimeout = timeout
+}
+
+// SetSchemaValidation validates the payloads sent and received against
+// the OCPP 1.6 JSON schemas. An invalid call is answered with the
+// ErrorCode of the violation, and an invalid request or response isn't sent.
+func (c *Conn) SetSchemaValidation(enabled bool) {
+	c.schemaValidation = enabled
+}
+
+// SetQueueOptions configures the outbound call queue
+func (c *Conn) SetQueueOptions(options QueueOptions) {
+	c.queue.setOptions(options)
+}
+
+// QueueStats returns a snapshot of the outbound call queue
+func (c *Conn) QueueStats() QueueStats {
+	return c.queue.snapshot()
 }
 
 func (c *Conn) WriteJSON(data interface{}) error {
@@ -101,12 +221,30 @@ func (c *Conn) WriteJSON(data interface{}) error {
 	return c.Conn.WriteJSON(data)
 }
 
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.sendMux.Lock()
+	defer c.sendMux.Unlock()
+	return c.Conn.WriteMessage(messageType, data)
+}
+
+// Close the connection, failing every call
+// still waiting for a response
 func (c *Conn) Close() error {
 	err := c.Conn.Close()
+	c.pending.closeAll()
 	c.cancelCtx()
 	return err
 }
 
+// CloseWithReason tells the peer why the connection is
+// being closed through a close frame, then closes it, e.g.
+// with websocket.CloseGoingAway when shutting down
+func (c *Conn) CloseWithReason(code int, reason string) error {
+	deadline := time.Now().Add(time.Second)
+	c.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+	return c.Close()
+}
+
 func (c *Conn) WaitClose() <-chan struct{} {
 	return c.ctx.Done()
 }
@@ -116,22 +254,42 @@ var (
 	space   = []byte{' '}
 )
 
+// messageFields is how many fields the frame of each message type has
+var messageFields = map[MessageType]int{
+	Call:       4,
+	CallResult: 3,
+	CallError:  5,
+}
+
 func UnmarshalMessage(msg []byte) (Message, error) {
 	var frame struct {
 		ChargerID string `json:"charger"`
 		OCPP []interface{} `json:"ocpp"`
 	}
-	err := json.Unmarshal(msg, &frame)
+	var err error
+	if bytes.HasPrefix(msg, []byte("[")) {
+		// plain OCPP-J message, not wrapped with the charger
+		err = json.Unmarshal(msg, &frame.OCPP)
+	} else {
+		err = json.Unmarshal(msg, &frame)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("on unmarshalling websocket message: %w", err)
 	}
-	if len(frame.OCPP) == 0 {
+	if len(frame.OCPP) < 2 {
 		return nil, fmt.Errorf("not an OCPP message: %s", string(msg))
 	}
 	msgType, ok := frame.OCPP[0].(float64)
 	if !ok {
 		return nil, fmt.Errorf("first field is not a message type: %w", err)
 	}
+	fields, ok := messageFields[MessageType(msgType)]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type %v: %s", msgType, string(msg))
+	}
+	if len(frame.OCPP) < fields {
+		return nil, fmt.Errorf("not an OCPP message, %d fields instead of %d: %s", len(frame.OCPP), fields, string(msg))
+	}
 	idStr, ok := frame.OCPP[1].(string)
 	if !ok {
 		return nil, fmt.Errorf("second field is not a message ID: %w", err)
@@ -170,11 +328,13 @@ func UnmarshalMessage(msg []byte) (Message, error) {
 		}
 		return &CallErrorMessage{frame.ChargerID,id, ErrorCode(codeStr), description, details}, nil
 	}
-	return nil, nil
+	return nil, fmt.Errorf("unknown message type %v: %s", msgType, string(msg))
 }
 
 func (c *Conn) ReadMessageAsync() <-chan error {
-	readMessageResultChannel := make(chan error)
+	// buffered, so the reading goroutine doesn't leak
+	// when the caller stopped listening
+	readMessageResultChannel := make(chan error, 1)
 	go func() {
 		readMessageResultChannel <- c.ReadMessage()
 	}()
@@ -197,91 +357,145 @@ func (c *Conn) ReadMessage() error {
 	log.Debug("Received a message, parsed: %v", msg)
 
 	if msg.Type() == CallResult || msg.Type() == CallError {
-		_, ok := c.sentMessages[msg.ID()]
+		_, ok := c.pending.get(msg.ID())
 		if !ok {
 			return errors.New("received call error/result without sending any call message")
 		}
 	}
 
-	var wserr ErrorCode
 	switch m := msg.(type) {
 	case *CallMessage:
-		var req messages.Request
-		req, wserr = c.callToRequest(m)
-		if wserr != Nil {
-			msg := NewCallErrorMessage(msg.ID(), wserr, "on handling message")
-			return c.sendMessage(msg)
+		req, err := c.callToRequest(m)
+		if err != nil {
+			return c.sendMessage(unmarshalResponse(msg.ID(), nil, err))
 		}
-		c.requests <- struct {
+		select {
+		case c.requests <- struct {
 			messages.Request
 			MessageID
 			ChargerID string
-		}{req, msg.ID(), msg.ChargerID()}
+		}{req, msg.ID(), msg.ChargerID()}:
+		case <-c.ctx.Done():
+			return ErrConnectionClosed
+		}
 	case *CallResultMessage:
-		var resp messages.Response
-		resp, wserr = c.callResultToResponse(m)
-		c.responsesOf[m.ID()] <- CallResponse{
+		resp, err := c.callResultToResponse(m)
+		c.deliverResponse(m.ID(), CallResponse{
 			response: resp,
-			err:      wserr,
-		}
+			err:      err,
+		})
 	case *CallErrorMessage:
-		c.responsesOf[m.ID()] <- CallResponse{
+		c.deliverResponse(m.ID(), CallResponse{
 			response: nil,
 			err:      m,
-		}
+		})
 	}
 	return nil
 }
 
-func (c *Conn) callToRequest(call *CallMessage) (messages.Request, ErrorCode) {
-	req := req.FromActionName(string(call.Action))
+func (c *Conn) callToRequest(call *CallMessage) (messages.Request, error) {
+	req := req.FromSender(c.peer(), string(call.Action))
 	if req == nil {
-		return nil, NotSupported
+		return nil, fmt.Errorf("%w: %s", NotImplemented, call.Action)
+	}
+	if c.schemaValidation {
+		if err := validate(string(call.Action), call.Payload); err != nil {
+			return nil, err
+		}
 	}
 	originalPayload, err := json.Marshal(call.Payload)
 	if err != nil {
-		return nil, GenericError
+		return nil, fmt.Errorf("%w: %v", GenericError, err)
 	}
 	err = json.Unmarshal(originalPayload, req)
 	if err != nil {
-		return nil, FormationViolation
+		return nil, fmt.Errorf("%w: %v", unmarshalErrorCode(err), err)
 	}
-	return req, Nil
+	if c.schemaValidation {
+		if err := validateRequest(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
 }
 
-func (c *Conn) callResultToResponse(result *CallResultMessage) (messages.Response, ErrorCode) {
+func (c *Conn) callResultToResponse(result *CallResultMessage) (messages.Response, error) {
 	id := result.ID()
-	call, ok := c.sentMessages[id]
+	call, ok := c.pending.get(id)
 	if !ok {
 		return nil, GenericError
 	}
 	if call == nil {
 		return nil, NotSupported
 	}
-	resp := req.FromActionName(string(call.Action)).GetResponse()
+	request := req.FromSender(c.side, string(call.Action))
+	if request == nil {
+		return nil, NotSupported
+	}
+	if c.schemaValidation {
+		if err := validate(string(call.Action)+"Response", result.Payload); err != nil {
+			return nil, fmt.Errorf("invalid %s response: %w", call.Action, err)
+		}
+	}
+	resp := request.GetResponse()
 	originalPayload, err := json.Marshal(result.Payload)
 	if err != nil {
-		return nil, GenericError
+		return nil, fmt.Errorf("%w: %v", GenericError, err)
 	}
 	err = json.Unmarshal(originalPayload, resp)
 	if err != nil {
-		return nil, FormationViolation
+		return nil, fmt.Errorf("%w: %v", unmarshalErrorCode(err), err)
 	}
-	return resp, Nil
+	return resp, nil
 }
+
+// unmarshalErrorCode is the ErrorCode of a payload which couldn't be unmarshalled
+func unmarshalErrorCode(err error) ErrorCode {
+	if errors.Is(err, enums.ErrUnknownValue) {
+		return PropertyConstraintViolation
+	}
+	return FormationViolation
+}
+
 func (c *Conn) SendResponse(id MessageID, response messages.Response, err error) error {
-	return c.sendMessage(unmarshalResponse(id, response, err))
+	if err == nil && c.schemaValidation {
+		if err := validateResponse(response); err != nil {
+			// it's our handler's fault, not the call's, so
+			// the peer isn't told what's wrong with it
+			errMsg := NewCallErrorMessage(id, InternalError, "on validating the response")
+			if sendErr := c.sendMessage(errMsg); sendErr != nil {
+				return sendErr
+			}
+			return fmt.Errorf("invalid response to %s: %w", id, err)
+		}
+	}
+	bts, err := json.Marshal(unmarshalResponse(id, response, err))
+	if err != nil {
+		// e.g. an invalid enumeration, the peer gets
+		// an error instead of waiting for the response
+		errMsg := NewCallErrorMessage(id, InternalError, "on marshalling the response")
+		if sendErr := c.sendMessage(errMsg); sendErr != nil {
+			return sendErr
+		}
+		return fmt.Errorf("on marshalling response: %w", err)
+	}
+	return c.send(bts)
 }
-func (c *Conn) sendMessage(msg Message) error {
-	c.sendMux.Lock()
-	defer c.sendMux.Unlock()
 
+func (c *Conn) sendMessage(msg Message) error {
 	bts, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("on marshalling message: %w", err)
 	}
+	return c.send(bts)
+}
+
+func (c *Conn) send(bts []byte) error {
+	c.sendMux.Lock()
+	defer c.sendMux.Unlock()
+
 	log.Debug("Sending message [raw]: %v", string(bts))
-	err = c.Conn.WriteMessage(websocket.TextMessage, bts)
+	err := c.Conn.WriteMessage(websocket.TextMessage, bts)
 	if err != nil {
 		return fmt.Errorf("on sending message: %w", err)
 	}
@@ -297,27 +511,75 @@ func (c *Conn) Requests() <-chan struct {
 	return c.requests
 }
 
+// SendRequest sends the request and waits for its response
+// at most the connection's request timeout
 func (c *Conn) SendRequest(chargerID string, request messages.Request) (messages.Response, error) {
+	return c.SendRequestContext(context.Background(), chargerID, request)
+}
+
+// SendRequestContext sends the request and waits for its response
+// until the context is done, in which case the call is forgotten
+// and a late response will be discarded. Without a deadline, it
+// waits at most the connection's request timeout.
+//
+// Only one call is outstanding at a time, so the request
+// first waits for its turn in the outbound queue.
+func (c *Conn) SendRequestContext(ctx context.Context, chargerID string, request messages.Request) (messages.Response, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		// a call never answered must time out, or it holds the
+		// outbound queue and no other call is ever sent
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
 	id := MessageID(uuid.New().String())
 	msg, err := UnmarshalRequest(id, chargerID, request)
 	if err != nil {
 		return nil, err
 	}
-	c.sentMessages[id] = msg
-	c.responsesOf[id] = make(chan CallResponse)
+	if c.schemaValidation {
+		if err := validate(request.Action(), msg.Payload); err != nil {
+			return nil, fmt.Errorf("invalid %s request: %w", request.Action(), err)
+		}
+		if err := validateRequest(request); err != nil {
+			return nil, fmt.Errorf("invalid %s request: %w", request.Action(), err)
+		}
+	}
+	err = c.queue.acquire(ctx, c.ctx.Done(), request)
+	if err != nil {
+		if errors.Is(err, ctx.Err()) {
+			return nil, fmt.Errorf("waiting to send %s: %w", request.Action(), err)
+		}
+		return nil, err
+	}
+	defer c.queue.release()
+
+	call, err := c.pending.add(msg)
+	if err != nil {
+		return nil, err
+	}
+	defer c.pending.remove(id)
+
 	err = c.sendMessage(msg)
 	if err != nil {
+		if c.ctx.Err() != nil {
+			return nil, ErrConnectionClosed
+		}
 		return nil, err
 	}
 	select {
-	case callResponse := <-c.responsesOf[id]:
-		delete(c.sentMessages, id)
-		delete(c.responsesOf, id)
-		if callResponse.err != Nil {
+	case callResponse := <-call.response:
+		if callResponse.err != nil && callResponse.err != Nil {
 			return nil, callResponse.err
 		}
 		return callResponse.response, nil
-	case <-time.After(internal.DefaultRequestTimeout):
-		return nil, fmt.Errorf("request timeout exceeded")
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for %s response: %w", request.Action(), ctx.Err())
+	}
+}
+
+func (c *Conn) deliverResponse(id MessageID, resp CallResponse) {
+	if !c.pending.resolve(id, resp) {
+		log.Debug("Discarding response of %s, nobody is waiting for it", id)
 	}
 }
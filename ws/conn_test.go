@@ -0,0 +1,402 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NegotiateVersion(t *testing.T) {
+	cases := []struct {
+		name      string
+		requested []string
+		supported []ocpp.Version
+		version   ocpp.Version
+		ok        bool
+	}{
+		{"exact match", []string{"ocpp1.6"}, []ocpp.Version{ocpp.V16}, ocpp.V16, true},
+		{"unknown protocols are ignored", []string{"ocpp2.0.1", "ocpp1.6"}, []ocpp.Version{ocpp.V16}, ocpp.V16, true},
+		{"server preference wins", []string{"ocpp1.5", "ocpp1.6"}, []ocpp.Version{ocpp.V16, ocpp.V15}, ocpp.V16, true},
+		{"no match", []string{"ocpp2.0.1"}, []ocpp.Version{ocpp.V16}, "", false},
+		{"nothing requested", nil, []ocpp.Version{ocpp.V16}, "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			version, ok := negotiateVersion(c.requested, c.supported)
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.version, version)
+		})
+	}
+}
+
+func Test_Handshake(t *testing.T) {
+	versions := make(chan ocpp.Version, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Handshake(w, r, []ocpp.Version{ocpp.V16})
+		if err != nil {
+			return
+		}
+		versions <- conn.Version()
+		conn.Close()
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	t.Run("accepts ocpp1.6", func(t *testing.T) {
+		conn, err := Dial(url, ocpp.V16, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		assert.Equal(t, ocpp.V16, conn.Version())
+		assert.Equal(t, "ocpp1.6", conn.Subprotocol())
+		assert.Equal(t, ocpp.V16, <-versions)
+	})
+
+	t.Run("echoes the matching protocol only", func(t *testing.T) {
+		dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1", "ocpp1.6"}}
+		socket, _, err := dialer.Dial(url, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer socket.Close()
+		assert.Equal(t, "ocpp1.6", socket.Subprotocol())
+		assert.Equal(t, ocpp.V16, <-versions)
+	})
+
+	t.Run("refuses unsupported versions", func(t *testing.T) {
+		_, err := Dial(url, ocpp.V15, nil)
+		assert.Error(t, err)
+
+		_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		assert.Error(t, err)
+		if assert.NotNil(t, resp) {
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		}
+	})
+}
+
+func Test_SendRequestContext(t *testing.T) {
+	// central system which never answers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Handshake(w, r, []ocpp.Version{ocpp.V16})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.Conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, err := Dial(url, ocpp.V16, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := conn.SendRequestContext(ctx, "123", &cpreq.Heartbeat{})
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, 0, conn.pending.len())
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-time.After(50 * time.Millisecond)
+			cancel()
+		}()
+		_, err := conn.SendRequestContext(ctx, "123", &cpreq.Heartbeat{})
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 0, conn.pending.len())
+	})
+
+	t.Run("default timeout", func(t *testing.T) {
+		conn.SetRequestTimeout(50 * time.Millisecond)
+		_, err := conn.SendRequest("123", &cpreq.Heartbeat{})
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("no deadline", func(t *testing.T) {
+		conn.SetRequestTimeout(50 * time.Millisecond)
+		// the unanswered call frees the queue for the next one
+		for i := 0; i < 2; i++ {
+			_, err := conn.SendRequestContext(context.Background(), "123", &cpreq.Heartbeat{})
+			assert.True(t, errors.Is(err, context.DeadlineExceeded))
+			assert.False(t, conn.QueueStats().Outstanding)
+		}
+	})
+}
+
+// echoServer answers every heartbeat it receives
+func echoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Handshake(w, r, []ocpp.Version{ocpp.V16})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		go func() {
+			for req := range conn.Requests() {
+				conn.SendResponse(req.MessageID, &cpresp.Heartbeat{}, nil)
+			}
+		}()
+		for conn.ReadMessage() == nil {
+		}
+	}))
+}
+
+func Test_ConcurrentSendRequest(t *testing.T) {
+	server := echoServer()
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, err := Dial(url, ocpp.V16, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	go func() {
+		for conn.ReadMessage() == nil {
+		}
+	}()
+
+	senders, calls := 100, 20
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				resp, err := conn.SendRequest("123", &cpreq.Heartbeat{})
+				assert.NoError(t, err)
+				assert.IsType(t, &cpresp.Heartbeat{}, resp)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 0, conn.pending.len())
+}
+
+func Test_ConnectionClosed(t *testing.T) {
+	// central system which never answers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Handshake(w, r, []ocpp.Version{ocpp.V16})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.Conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, err := Dial(url, ocpp.V16, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	inFlight := 50
+	errs := make(chan error, inFlight)
+	for i := 0; i < inFlight; i++ {
+		go func() {
+			_, err := conn.SendRequestContext(context.Background(), "123", &cpreq.Heartbeat{})
+			errs <- err
+		}()
+	}
+	// wait until a call is outstanding and the others are queued
+	for conn.pending.len() < 1 || conn.QueueStats().Length < inFlight-1 {
+		time.Sleep(time.Millisecond)
+	}
+	conn.Close()
+	for i := 0; i < inFlight; i++ {
+		assert.True(t, errors.Is(<-errs, ErrConnectionClosed))
+	}
+
+	_, err = conn.SendRequest("123", &cpreq.Heartbeat{})
+	assert.True(t, errors.Is(err, ErrConnectionClosed))
+}
+
+func Test_PendingCalls(t *testing.T) {
+	pending := newPendingCalls()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := MessageID(strconv.Itoa(i))
+			call, err := pending.add(NewCallMessage(id, "123", "Heartbeat", nil))
+			if !assert.NoError(t, err) {
+				return
+			}
+			go pending.resolve(id, CallResponse{response: &cpresp.Heartbeat{}, err: Nil})
+			resp := <-call.response
+			assert.IsType(t, &cpresp.Heartbeat{}, resp.response)
+			// already resolved
+			assert.False(t, pending.resolve(id, CallResponse{}))
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 0, pending.len())
+
+	call, err := pending.add(NewCallMessage("late", "123", "Heartbeat", nil))
+	assert.NoError(t, err)
+	pending.closeAll()
+	assert.Equal(t, ErrConnectionClosed, (<-call.response).err)
+	_, err = pending.add(NewCallMessage("after close", "123", "Heartbeat", nil))
+	assert.Equal(t, ErrConnectionClosed, err)
+}
+
+func Test_DataTransfer(t *testing.T) {
+	received := make(chan messages.Request, 1)
+	serverConns := make(chan *Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Handshake(w, r, []ocpp.Version{ocpp.V16})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		serverConns <- conn
+		go func() {
+			for req := range conn.Requests() {
+				received <- req.Request
+				conn.SendResponse(req.MessageID, &cpresp.DataTransfer{Status: "Accepted", Data: "from cs"}, nil)
+			}
+		}()
+		for conn.ReadMessage() == nil {
+		}
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, err := Dial(url, ocpp.V16, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	go func() {
+		for req := range conn.Requests() {
+			received <- req.Request
+			conn.SendResponse(req.MessageID, &csresp.DataTransfer{Status: "Accepted", Data: "from cp"}, nil)
+		}
+	}()
+	go func() {
+		for conn.ReadMessage() == nil {
+		}
+	}()
+	serverConn := <-serverConns
+	assert.Equal(t, ocpp.ChargePoint, conn.Side())
+	assert.Equal(t, ocpp.CentralSystem, serverConn.Side())
+
+	t.Run("from the charge point", func(t *testing.T) {
+		resp, err := conn.SendRequest("123", &cpreq.DataTransfer{VendorId: "com.acme", MessageId: "Tariff", Data: "from cp"})
+		assert.NoError(t, err)
+		assert.Equal(t, &cpresp.DataTransfer{Status: "Accepted", Data: "from cs"}, resp)
+		assert.Equal(t, &cpreq.DataTransfer{VendorId: "com.acme", MessageId: "Tariff", Data: "from cp"}, <-received)
+	})
+
+	t.Run("from the central system", func(t *testing.T) {
+		resp, err := serverConn.SendRequest("123", &csreq.DataTransfer{VendorId: "com.acme", MessageId: "Tariff", Data: "from cs"})
+		assert.NoError(t, err)
+		assert.Equal(t, &csresp.DataTransfer{Status: "Accepted", Data: "from cp"}, resp)
+		assert.Equal(t, &csreq.DataTransfer{VendorId: "com.acme", MessageId: "Tariff", Data: "from cs"}, <-received)
+	})
+
+	t.Run("request of the wrong side", func(t *testing.T) {
+		_, err := conn.SendRequest("123", &csreq.Reset{Type: "Soft"})
+		assert.True(t, errors.Is(err, NotImplemented), "unexpected error: %v", err)
+	})
+}
+
+func Test_UnmarshalMessage(t *testing.T) {
+	cases := []struct {
+		name    string
+		frame   string
+		msgType MessageType
+		ok      bool
+	}{
+		{"call", `[2,"id","Heartbeat",{}]`, Call, true},
+		{"call result", `[3,"id",{}]`, CallResult, true},
+		{"call error", `[4,"id","GenericError","x",{}]`, CallError, true},
+		{"wrapped with the charger", `{"charger":"cp1","ocpp":[3,"id",{}]}`, CallResult, true},
+		{"call without payload", `[2,"id","Heartbeat"]`, 0, false},
+		{"call result without payload", `[3,"id"]`, 0, false},
+		{"call error without details", `[4,"id","GenericError","x"]`, 0, false},
+		{"unknown message type", `[5,"id",{}]`, 0, false},
+		{"no message ID", `[2]`, 0, false},
+		{"not a frame", `{}`, 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			msg, err := UnmarshalMessage([]byte(c.frame))
+			if !c.ok {
+				assert.Error(t, err)
+				assert.Nil(t, msg)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.msgType, msg.Type())
+				assert.Equal(t, MessageID("id"), msg.ID())
+			}
+		})
+	}
+}
+
+func Test_SendResponseMarshalFailure(t *testing.T) {
+	// central system answering with an invalid registration status
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Handshake(w, r, []ocpp.Version{ocpp.V16})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		go func() {
+			for req := range conn.Requests() {
+				err := conn.SendResponse(req.MessageID, &cpresp.BootNotification{Status: "Maybe", CurrentTime: time.Now(), Interval: 60}, nil)
+				assert.Error(t, err)
+			}
+		}()
+		for conn.ReadMessage() == nil {
+		}
+	}))
+	defer server.Close()
+
+	conn, err := Dial("ws"+strings.TrimPrefix(server.URL, "http"), ocpp.V16, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	go func() {
+		for conn.ReadMessage() == nil {
+		}
+	}()
+	conn.SetRequestTimeout(5 * time.Second)
+	_, err = conn.SendRequest("123", &cpreq.BootNotification{ChargePointVendor: "ACME", ChargePointModel: "Model 1"})
+	assert.True(t, errors.Is(err, InternalError), "unexpected error: %v", err)
+}
@@ -2,6 +2,7 @@ package ws
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/michaelbironneau/go-ocpp/internal/log"
 
@@ -102,6 +103,8 @@ func (result *CallResultMessage) MarshalJSON() ([]byte, error) {
 type ErrorCode string
 
 const (
+	// NotImplemented when Requested Action is not known by receiver
+	NotImplemented ErrorCode = "NotImplemented"
 	// NotSupported when Requested Action is recognized but not supported by the receiver
 	NotSupported ErrorCode = "NotSupported"
 	// InternalError when An internal error occurred and the receiver was not able to process the requested Action successfully
@@ -140,6 +143,17 @@ func (err *CallErrorMessage) Error() string {
 	return fmt.Sprintf("[%s] %s: %s", err.errorCode, err.errorDescription, err.errorDetails)
 }
 
+// Code is the OCPP-J error code of the error
+func (err *CallErrorMessage) Code() ErrorCode {
+	return err.errorCode
+}
+
+// Unwrap lets errors.Is match the error code, e.g.
+// errors.Is(err, ws.NotSupported)
+func (err *CallErrorMessage) Unwrap() error {
+	return err.errorCode
+}
+
 func NewCallErrorMessage(id MessageID, errorCode ErrorCode, errorDescription string) *CallErrorMessage {
 	return &CallErrorMessage{
 		id:               id,
@@ -164,8 +178,15 @@ func (err *CallErrorMessage) MarshalJSON() ([]byte, error) {
 	return json.Marshal([]interface{}{err.Type(), err.id, err.errorCode, err.errorDescription, err.errorDetails})
 }
 
+// unmarshalResponse answers with the error code the error wraps, e.g.
+// fmt.Errorf("%w: no such connector", ws.PropertyConstraintViolation),
+// or with an InternalError
 func unmarshalResponse(id MessageID, resp messages.Response, err error) Message {
 	if err != nil {
+		var code ErrorCode
+		if errors.As(err, &code) {
+			return NewCallErrorMessage(id, code, err.Error())
+		}
 		return NewCallErrorMessage(id, InternalError, err.Error())
 	}
 	return NewCallResult(id, resp)
@@ -0,0 +1,99 @@
+package ws
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrConnectionClosed is returned to every call still waiting
+	// for its response when the websocket connection goes down
+	ErrConnectionClosed = errors.New("websocket connection closed")
+)
+
+// pendingCall is a sent call waiting for its response
+type pendingCall struct {
+	message *CallMessage
+	// buffered, so delivering a response never blocks the reader
+	response chan CallResponse
+}
+
+// pendingCalls keeps track of the calls sent through a connection,
+// it's safe to use from the reader and any number of senders
+type pendingCalls struct {
+	mux    sync.Mutex
+	calls  map[MessageID]*pendingCall
+	closed bool
+}
+
+func newPendingCalls() *pendingCalls {
+	return &pendingCalls{
+		calls: make(map[MessageID]*pendingCall),
+	}
+}
+
+// add registers the call before it's sent, it
+// fails if the connection was already closed
+func (p *pendingCalls) add(msg *CallMessage) (*pendingCall, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.closed {
+		return nil, ErrConnectionClosed
+	}
+	call := &pendingCall{
+		message:  msg,
+		response: make(chan CallResponse, 1),
+	}
+	p.calls[msg.ID()] = call
+	return call, nil
+}
+
+// get returns the call message sent with this id
+func (p *pendingCalls) get(id MessageID) (*CallMessage, bool) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	call, ok := p.calls[id]
+	if !ok {
+		return nil, false
+	}
+	return call.message, true
+}
+
+// resolve delivers the response to the caller waiting for it
+// and forgets the call, it returns false if nobody is waiting
+func (p *pendingCalls) resolve(id MessageID, resp CallResponse) bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	call, ok := p.calls[id]
+	if !ok {
+		return false
+	}
+	delete(p.calls, id)
+	call.response <- resp
+	return true
+}
+
+// remove forgets the call, e.g. when the caller gave up
+func (p *pendingCalls) remove(id MessageID) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	delete(p.calls, id)
+}
+
+// closeAll fails every pending call with ErrConnectionClosed
+// and refuses any call added afterwards
+func (p *pendingCalls) closeAll() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.closed = true
+	for id, call := range p.calls {
+		call.response <- CallResponse{err: ErrConnectionClosed}
+		delete(p.calls, id)
+	}
+}
+
+func (p *pendingCalls) len() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return len(p.calls)
+}
@@ -0,0 +1,217 @@
+package ws
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/messages"
+)
+
+var (
+	// ErrQueueFull is returned when a call can't even wait
+	// for its turn because the outbound queue is full
+	ErrQueueFull = errors.New("outbound call queue is full")
+)
+
+// QueueOptions configures the outbound call queue of a connection.
+//
+// OCPP-J only allows one outstanding call per connection, so
+// every call waits in the queue until the previous one was
+// answered, timed out or was cancelled.
+type QueueOptions struct {
+	// Depth is how many calls may wait for their turn,
+	// unbounded if not positive
+	Depth int
+	// Priority of the request, calls with a higher priority are
+	// sent first, calls with the same priority in FIFO order
+	Priority func(request messages.Request) int
+	// OnDequeue is called when a call is about to be sent,
+	// with how long it waited and how many calls are still waiting
+	OnDequeue func(action string, wait time.Duration, queueLength int)
+}
+
+// PriorityByAction gives each action its priority, actions
+// not in the map have priority 0, e.g.:
+//
+// ws.PriorityByAction(map[string]int{"RemoteStopTransaction": 10})
+func PriorityByAction(priorities map[string]int) func(messages.Request) int {
+	return func(request messages.Request) int {
+		return priorities[request.Action()]
+	}
+}
+
+// QueueStats is a snapshot of the outbound call queue
+type QueueStats struct {
+	// Length is how many calls are waiting for their turn
+	Length int
+	// Outstanding is whether a call is waiting for its response
+	Outstanding bool
+	// Dequeued is how many calls got their turn
+	Dequeued uint64
+	// TotalWait and MaxWait are the time spent
+	// in the queue by the dequeued calls
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+type queuedCall struct {
+	action   string
+	priority int
+	seq      uint64
+	enqueued time.Time
+	index    int
+	// closed when it's the call's turn
+	turn chan struct{}
+}
+
+// callHeap orders the calls by priority, then by arrival
+type callHeap []*queuedCall
+
+func (h callHeap) Len() int { return len(h) }
+func (h callHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h callHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *callHeap) Push(x interface{}) {
+	call := x.(*queuedCall)
+	call.index = len(*h)
+	*h = append(*h, call)
+}
+func (h *callHeap) Pop() interface{} {
+	old := *h
+	call := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	call.index = -1
+	return call
+}
+
+// callQueue lets a single call be outstanding at a time
+type callQueue struct {
+	mux     sync.Mutex
+	options QueueOptions
+	waiting callHeap
+	busy    bool
+	seq     uint64
+	stats   QueueStats
+}
+
+func newCallQueue() *callQueue {
+	return &callQueue{}
+}
+
+func (q *callQueue) setOptions(options QueueOptions) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	q.options = options
+}
+
+// acquire waits for the request's turn to be sent, the caller
+// must release the queue once the call is over
+func (q *callQueue) acquire(ctx context.Context, closed <-chan struct{}, request messages.Request) error {
+	q.mux.Lock()
+	priority := q.options.Priority
+	q.mux.Unlock()
+	call := &queuedCall{
+		action:   request.Action(),
+		enqueued: time.Now(),
+		turn:     make(chan struct{}),
+	}
+	if priority != nil {
+		call.priority = priority(request)
+	}
+
+	q.mux.Lock()
+	call.seq = q.seq
+	q.seq++
+	if !q.busy && len(q.waiting) == 0 {
+		q.busy = true
+		notify := q.dequeued(call)
+		q.mux.Unlock()
+		notify()
+		return nil
+	}
+	if q.options.Depth > 0 && len(q.waiting) >= q.options.Depth {
+		q.mux.Unlock()
+		return ErrQueueFull
+	}
+	heap.Push(&q.waiting, call)
+	q.mux.Unlock()
+
+	var err error
+	select {
+	case <-call.turn:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-closed:
+		err = ErrConnectionClosed
+	}
+
+	q.mux.Lock()
+	if call.index >= 0 {
+		heap.Remove(&q.waiting, call.index)
+		q.mux.Unlock()
+		return err
+	}
+	// got its turn meanwhile, pass it on
+	notify := q.next()
+	q.mux.Unlock()
+	notify()
+	return err
+}
+
+// release gives the turn to the next call
+func (q *callQueue) release() {
+	q.mux.Lock()
+	notify := q.next()
+	q.mux.Unlock()
+	notify()
+}
+
+// next must be called holding the lock, the returned
+// function notifies the dequeue once the lock is released
+func (q *callQueue) next() func() {
+	if len(q.waiting) == 0 {
+		q.busy = false
+		return func() {}
+	}
+	call := heap.Pop(&q.waiting).(*queuedCall)
+	notify := q.dequeued(call)
+	close(call.turn)
+	return notify
+}
+
+func (q *callQueue) dequeued(call *queuedCall) func() {
+	wait := time.Since(call.enqueued)
+	q.stats.Dequeued++
+	q.stats.TotalWait += wait
+	if wait > q.stats.MaxWait {
+		q.stats.MaxWait = wait
+	}
+	onDequeue, queueLength := q.options.OnDequeue, len(q.waiting)
+	return func() {
+		if onDequeue != nil {
+			onDequeue(call.action, wait, queueLength)
+		}
+	}
+}
+
+func (q *callQueue) snapshot() QueueStats {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	stats := q.stats
+	stats.Length = len(q.waiting)
+	stats.Outstanding = q.busy
+	return stats
+}
@@ -0,0 +1,168 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/messages"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CallQueue(t *testing.T) {
+	closed := make(chan struct{})
+
+	// waitQueued waits until the queue has the given number of waiting calls
+	waitQueued := func(q *callQueue, length int) {
+		for q.snapshot().Length != length {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("by priority, then FIFO", func(t *testing.T) {
+		q := newCallQueue()
+		q.setOptions(QueueOptions{
+			Priority: PriorityByAction(map[string]int{"RemoteStopTransaction": 10}),
+		})
+		assert.NoError(t, q.acquire(context.Background(), closed, &csreq.GetConfiguration{}))
+
+		var order []string
+		var orderMux sync.Mutex
+		var wg sync.WaitGroup
+		requests := []messages.Request{
+			&csreq.GetConfiguration{},
+			&csreq.Reset{},
+			&csreq.RemoteStopTransaction{},
+		}
+		for i, request := range requests {
+			wg.Add(1)
+			go func(request messages.Request) {
+				defer wg.Done()
+				assert.NoError(t, q.acquire(context.Background(), closed, request))
+				orderMux.Lock()
+				order = append(order, request.Action())
+				orderMux.Unlock()
+				q.release()
+			}(request)
+			waitQueued(q, i+1)
+		}
+		q.release()
+		wg.Wait()
+		assert.Equal(t, []string{"RemoteStopTransaction", "GetConfiguration", "Reset"}, order)
+
+		stats := q.snapshot()
+		assert.Equal(t, 0, stats.Length)
+		assert.False(t, stats.Outstanding)
+		assert.Equal(t, uint64(4), stats.Dequeued)
+		assert.True(t, stats.MaxWait > 0)
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		q := newCallQueue()
+		q.setOptions(QueueOptions{Depth: 1})
+		assert.NoError(t, q.acquire(context.Background(), closed, &csreq.GetConfiguration{}))
+		go q.acquire(context.Background(), closed, &csreq.GetConfiguration{})
+		waitQueued(q, 1)
+		assert.Equal(t, ErrQueueFull, q.acquire(context.Background(), closed, &csreq.GetConfiguration{}))
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		q := newCallQueue()
+		assert.NoError(t, q.acquire(context.Background(), closed, &csreq.GetConfiguration{}))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := q.acquire(ctx, closed, &csreq.GetConfiguration{})
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, 0, q.snapshot().Length)
+		// the cancelled call doesn't hold the turn
+		q.release()
+		assert.NoError(t, q.acquire(context.Background(), closed, &csreq.GetConfiguration{}))
+	})
+
+	t.Run("connection closed while waiting", func(t *testing.T) {
+		q := newCallQueue()
+		closing := make(chan struct{})
+		assert.NoError(t, q.acquire(context.Background(), closing, &csreq.GetConfiguration{}))
+		errs := make(chan error)
+		go func() {
+			errs <- q.acquire(context.Background(), closing, &csreq.GetConfiguration{})
+		}()
+		waitQueued(q, 1)
+		close(closing)
+		assert.Equal(t, ErrConnectionClosed, <-errs)
+	})
+}
+
+func Test_OneOutstandingCall(t *testing.T) {
+	var outstanding, maxOutstanding int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Handshake(w, r, []ocpp.Version{ocpp.V16})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, raw, err := conn.Conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg, err := UnmarshalMessage(raw)
+			if err != nil {
+				continue
+			}
+			n := atomic.AddInt32(&outstanding, 1)
+			if n > atomic.LoadInt32(&maxOutstanding) {
+				atomic.StoreInt32(&maxOutstanding, n)
+			}
+			// answer later, so any call sent meanwhile would pile up
+			go func(id MessageID) {
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&outstanding, -1)
+				conn.SendResponse(id, &cpresp.Heartbeat{}, nil)
+			}(msg.ID())
+		}
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, err := Dial(url, ocpp.V16, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	var dequeued int32
+	conn.SetQueueOptions(QueueOptions{
+		OnDequeue: func(action string, wait time.Duration, queueLength int) {
+			atomic.AddInt32(&dequeued, 1)
+		},
+	})
+	go func() {
+		for conn.ReadMessage() == nil {
+		}
+	}()
+
+	senders := 20
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := conn.SendRequest("123", &cpreq.Heartbeat{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&maxOutstanding))
+	assert.Equal(t, int32(senders), atomic.LoadInt32(&dequeued))
+	assert.Equal(t, uint64(senders), conn.QueueStats().Dequeued)
+}
@@ -0,0 +1,194 @@
+package ws
+
+//go:generate go run ../internal/gen/schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp/messages"
+)
+
+// schema is the subset of JSON Schema draft-04 used by the OCPP 1.6 schemas
+type schema struct {
+	Type                 string             `json:"type"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Enum                 []string           `json:"enum"`
+	MaxLength            *int               `json:"maxLength"`
+	Format               string             `json:"format"`
+	Items                *schema            `json:"items"`
+	MultipleOf           *float64           `json:"multipleOf"`
+}
+
+var (
+	schemas     map[string]*schema
+	schemasOnce sync.Once
+)
+
+func getSchema(name string) *schema {
+	schemasOnce.Do(func() {
+		schemas = make(map[string]*schema, len(schemaSources))
+		for name, source := range schemaSources {
+			s := &schema{}
+			if err := json.Unmarshal([]byte(source), s); err != nil {
+				panic(fmt.Sprintf("invalid schema %s: %v", name, err))
+			}
+			schemas[name] = s
+		}
+	})
+	return schemas[name]
+}
+
+// validate the payload against the schema of the given name, the action
+// for the requests and the action + "Response" for the responses. The
+// error wraps the ErrorCode of the violation, there's none without schema.
+func validate(name string, payload interface{}) error {
+	s := getSchema(name)
+	if s == nil {
+		return nil
+	}
+	return s.validate(name, payload)
+}
+
+// validateResponse validates the response against the schema of
+// its action, which is the name of the response type
+func validateResponse(response interface{}) error {
+	t := reflect.TypeOf(response)
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	payload, err := toPayload(response)
+	if err != nil {
+		return fmt.Errorf("%w: %v", unmarshalErrorCode(err), err)
+	}
+	return validate(t.Name()+"Response", payload)
+}
+
+// validator is a message checking the rules the
+// schemas can't express, e.g. csreq.SetChargingProfile
+type validator interface {
+	Validate() error
+}
+
+// validateRequest checks the rules of the request, if it has any
+func validateRequest(request messages.Request) error {
+	v, ok := request.(validator)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", PropertyConstraintViolation, err)
+	}
+	return nil
+}
+
+// toPayload is the value as decoded from its JSON
+func toPayload(value interface{}) (interface{}, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var payload interface{}
+	err = json.Unmarshal(raw, &payload)
+	return payload, err
+}
+
+func (s *schema) validate(path string, value interface{}) error {
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: %s is not an object", TypeConstraintViolation, path)
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%w: %s.%s is required", OccurenceConstraintViolation, path, name)
+			}
+		}
+		// sorted, so the same payload always gives the same error
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%w: unknown property %s.%s", FormationViolation, path, name)
+				}
+				continue
+			}
+			if err := property.validate(path+"."+name, object[name]); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%w: %s is not an array", TypeConstraintViolation, path)
+		}
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range array {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w: %s is not a string", TypeConstraintViolation, path)
+		}
+		if s.MaxLength != nil && len([]rune(str)) > *s.MaxLength {
+			return fmt.Errorf("%w: %s is longer than %d characters", PropertyConstraintViolation, path, *s.MaxLength)
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return fmt.Errorf("%w: %s %q is not one of %v", PropertyConstraintViolation, path, str, s.Enum)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return fmt.Errorf("%w: %s %q is not a date-time", TypeConstraintViolation, path, str)
+			}
+		}
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%w: %s is not a %s", TypeConstraintViolation, path, s.Type)
+		}
+		if s.Type == "integer" && number != math.Trunc(number) {
+			return fmt.Errorf("%w: %s is not an integer", TypeConstraintViolation, path)
+		}
+		if s.MultipleOf != nil {
+			quotient := number / *s.MultipleOf
+			if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+				return fmt.Errorf("%w: %s is not a multiple of %v", PropertyConstraintViolation, path, *s.MultipleOf)
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%w: %s is not a boolean", TypeConstraintViolation, path)
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
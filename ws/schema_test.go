@@ -0,0 +1,168 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/michaelbironneau/go-ocpp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/cpresp"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csreq"
+	"github.com/michaelbironneau/go-ocpp/messages/v1x/csresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		payload string
+		code    ErrorCode
+	}{
+		{"valid", "StatusNotification", `{"connectorId":1,"errorCode":"NoError","status":"Available","timestamp":"2019-04-05T21:45:00.123Z"}`, Nil},
+		{"missing required", "StatusNotification", `{"connectorId":1,"status":"Available"}`, OccurenceConstraintViolation},
+		{"not in enum", "StatusNotification", `{"connectorId":1,"errorCode":"NoError","status":"Sleeping"}`, PropertyConstraintViolation},
+		{"wrong type", "StatusNotification", `{"connectorId":"1","errorCode":"NoError","status":"Available"}`, TypeConstraintViolation},
+		{"not an integer", "StatusNotification", `{"connectorId":1.5,"errorCode":"NoError","status":"Available"}`, TypeConstraintViolation},
+		{"not a date-time", "StatusNotification", `{"connectorId":1,"errorCode":"NoError","status":"Available","timestamp":"yesterday"}`, TypeConstraintViolation},
+		{"unknown property", "StatusNotification", `{"connectorId":1,"errorCode":"NoError","status":"Available","color":"red"}`, FormationViolation},
+		{"too long", "Authorize", `{"idTag":"123456789012345678901"}`, PropertyConstraintViolation},
+		{"longest", "Authorize", `{"idTag":"12345678901234567890"}`, Nil},
+		{"nested", "AuthorizeResponse", `{"idTagInfo":{"status":"Accepted","expiryDate":"2019-04-05T21:45:00Z"}}`, Nil},
+		{"nested not in enum", "AuthorizeResponse", `{"idTagInfo":{"status":"Maybe"}}`, PropertyConstraintViolation},
+		{"array item", "MeterValues", `{"connectorId":1,"meterValue":[{"timestamp":"2019-04-05T21:45:00Z","sampledValue":[{"value":"1","unit":"Parsecs"}]}]}`, PropertyConstraintViolation},
+		{"multiple of", "SetChargingProfile", `{"connectorId":1,"csChargingProfiles":{"chargingProfileId":1,"stackLevel":0,"chargingProfilePurpose":"TxDefaultProfile","chargingProfileKind":"Absolute","chargingSchedule":{"chargingRateUnit":"A","chargingSchedulePeriod":[{"startPeriod":0,"limit":16.05}]}}}`, PropertyConstraintViolation},
+		{"multiple of 0.1", "SetChargingProfile", `{"connectorId":1,"csChargingProfiles":{"chargingProfileId":1,"stackLevel":0,"chargingProfilePurpose":"TxDefaultProfile","chargingProfileKind":"Absolute","chargingSchedule":{"chargingRateUnit":"A","chargingSchedulePeriod":[{"startPeriod":0,"limit":16.3}]}}}`, Nil},
+		{"boolean", "GetConfigurationResponse", `{"configurationKey":[{"key":"a","readonly":"yes"}]}`, TypeConstraintViolation},
+		{"no schema", "Unknown", `{"anything":1}`, Nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload interface{}
+			if !assert.NoError(t, json.Unmarshal([]byte(tt.payload), &payload)) {
+				return
+			}
+			err := validate(tt.schema, payload)
+			if tt.code == Nil {
+				assert.NoError(t, err)
+				return
+			}
+			var code ErrorCode
+			if assert.True(t, errors.As(err, &code), "unexpected error: %v", err) {
+				assert.Equal(t, tt.code, code, err.Error())
+			}
+		})
+	}
+}
+
+func Test_AllSchemas(t *testing.T) {
+	assert.Len(t, schemaSources, 56)
+	for name := range schemaSources {
+		assert.NotNil(t, getSchema(name), name)
+	}
+}
+
+func Test_ValidateResponse(t *testing.T) {
+	assert.NoError(t, validateResponse(&cpresp.Heartbeat{CurrentTime: time.Now()}))
+	assert.NoError(t, validateResponse(&csresp.Reset{Status: "Accepted"}))
+	err := validateResponse(&csresp.GetDiagnostics{FileName: strings.Repeat("a", 256)})
+	assert.True(t, errors.Is(err, PropertyConstraintViolation), "unexpected error: %v", err)
+	// not even marshalled
+	err = validateResponse(&csresp.Reset{Status: "Maybe"})
+	assert.True(t, errors.Is(err, PropertyConstraintViolation), "unexpected error: %v", err)
+}
+
+func Test_SchemaValidation(t *testing.T) {
+	// errors of the invalid responses of the handler
+	responseErrs := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Handshake(w, r, []ocpp.Version{ocpp.V16})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetSchemaValidation(true)
+		go func() {
+			for req := range conn.Requests() {
+				switch req.Request.(type) {
+				case *cpreq.Authorize:
+					responseErrs <- conn.SendResponse(req.MessageID, &cpresp.Authorize{IdTagInfo: &cpresp.IdTagInfo{Status: "Accepted", ParentIdTag: "123456789012345678901"}}, nil)
+				default:
+					conn.SendResponse(req.MessageID, &cpresp.StatusNotification{}, nil)
+				}
+			}
+		}()
+		for conn.ReadMessage() == nil {
+		}
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, err := Dial(url, ocpp.V16, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	go func() {
+		for conn.ReadMessage() == nil {
+		}
+	}()
+
+	t.Run("valid request", func(t *testing.T) {
+		_, err := conn.SendRequest("123", &cpreq.StatusNotification{ConnectorId: 1, ErrorCode: "NoError", Status: "Available"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid request received", func(t *testing.T) {
+		_, err := conn.SendRequest("123", &cpreq.StatusNotification{ConnectorId: 1, ErrorCode: "NoError", Status: "Available", Info: strings.Repeat("a", 51)})
+		var callErr *CallErrorMessage
+		if assert.True(t, errors.As(err, &callErr), "unexpected error: %v", err) {
+			assert.Equal(t, PropertyConstraintViolation, callErr.Code())
+		}
+	})
+
+	t.Run("invalid response of the handler", func(t *testing.T) {
+		_, err := conn.SendRequest("123", &cpreq.Authorize{IdTag: "TAG"})
+		var callErr *CallErrorMessage
+		if assert.True(t, errors.As(err, &callErr), "unexpected error: %v", err) {
+			// the call was fine, the peer isn't told about the response
+			assert.Equal(t, InternalError, callErr.Code())
+			assert.NotContains(t, callErr.Error(), "parentIdTag")
+		}
+		err = <-responseErrs
+		assert.True(t, errors.Is(err, PropertyConstraintViolation), "unexpected error: %v", err)
+	})
+
+	conn.SetSchemaValidation(true)
+
+	t.Run("invalid request not sent", func(t *testing.T) {
+		_, err := conn.SendRequest("123", &cpreq.Authorize{IdTag: "123456789012345678901"})
+		assert.True(t, errors.Is(err, PropertyConstraintViolation), "unexpected error: %v", err)
+	})
+}
+
+func Test_UnknownEnumerationValue(t *testing.T) {
+	err := json.Unmarshal([]byte(`{"connectorId":1,"errorCode":"NoError","status":"Sleeping"}`), &cpreq.StatusNotification{})
+	assert.Equal(t, PropertyConstraintViolation, unmarshalErrorCode(err))
+	err = json.Unmarshal([]byte(`{"connectorId":"1","errorCode":"NoError","status":"Available"}`), &cpreq.StatusNotification{})
+	assert.Equal(t, FormationViolation, unmarshalErrorCode(err))
+}
+
+func Test_ValidateRequest(t *testing.T) {
+	assert.NoError(t, validateRequest(&cpreq.Heartbeat{}))
+	assert.NoError(t, validateRequest(&csreq.RemoteStartTransaction{IdTag: "TAG"}))
+	err := validateRequest(&csreq.SetChargingProfile{ConnectorId: 1, CsChargingProfiles: &csreq.ChargingProfile{
+		ChargingProfileKind:    "Relative",
+		ChargingProfilePurpose: "TxProfile",
+		ChargingSchedule: &csreq.ChargingSchedule{
+			ChargingRateUnit:       "A",
+			ChargingSchedulePeriod: []*csreq.ChargingSchedulePeriodItems{{Limit: 16}},
+		},
+	}})
+	assert.True(t, errors.Is(err, PropertyConstraintViolation), "unexpected error: %v", err)
+}